@@ -2,15 +2,77 @@ package cmd
 
 import (
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
 )
 
+// requireAccount resolves the account for API commands:
+//
+//  1. --account (or a profile's account), then GOG_ACCOUNT; aliases from
+//     `gog auth alias set` resolve to their email
+//  2. the stored default account (`gog auth manage`)
+//  3. the only stored token, if there is exactly one
+//
+// With several stored tokens and no default it fails and lists them.
 func requireAccount(flags *RootFlags) (string, error) {
+	if strings.TrimSpace(flags.Accounts) != "" || flags.AllAccounts {
+		return "", usagef("--accounts/--all-accounts only work with: %s", fanoutCommands)
+	}
 	if v := strings.TrimSpace(flags.Account); v != "" {
-		return v, nil
+		return resolveAccount(v)
 	}
 	if v := strings.TrimSpace(os.Getenv("GOG_ACCOUNT")); v != "" {
+		return resolveAccount(v)
+	}
+	return defaultAccount()
+}
+
+func resolveAccount(v string) (string, error) {
+	if strings.Contains(v, "@") {
 		return v, nil
 	}
-	return "", usage("missing --account (or set GOG_ACCOUNT)")
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return "", err
+	}
+	if email, ok := cfg.AccountAlias(v); ok {
+		return email, nil
+	}
+	if names := cfg.AliasNames(); len(names) > 0 {
+		return "", usagef("unknown account alias %q (aliases: %s)", v, strings.Join(names, ", "))
+	}
+	return "", usagef("unknown account alias %q (set one with: gog auth alias set %s <email>)", v, v)
+}
+
+func defaultAccount() (string, error) {
+	missing := usage("missing --account (or set GOG_ACCOUNT)")
+
+	store, err := openSecretsStore()
+	if err != nil {
+		return "", missing
+	}
+	if v, err := store.GetDefaultAccount(); err == nil && strings.TrimSpace(v) != "" {
+		return strings.TrimSpace(v), nil
+	}
+
+	tokens, err := store.ListTokens()
+	if err != nil {
+		return "", missing
+	}
+	switch len(tokens) {
+	case 0:
+		return "", missing
+	case 1:
+		return tokens[0].Email, nil
+	}
+
+	emails := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		emails = append(emails, t.Email)
+	}
+	sort.Strings(emails)
+	return "", usagef("multiple accounts, choose one with --account: %s", strings.Join(emails, ", "))
 }
@@ -1,6 +1,29 @@
 package cmd
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/secrets"
+)
+
+func withSecretsStore(t *testing.T, store secrets.Store) {
+	t.Helper()
+	orig := openSecretsStore
+	t.Cleanup(func() { openSecretsStore = orig })
+	openSecretsStore = func() (secrets.Store, error) { return store, nil }
+}
+
+func storeWithTokens(t *testing.T, emails ...string) *memSecretsStore {
+	t.Helper()
+	store := newMemSecretsStore()
+	for _, email := range emails {
+		if err := store.SetToken(email, secrets.Token{RefreshToken: "rt"}); err != nil {
+			t.Fatalf("SetToken: %v", err)
+		}
+	}
+	return store
+}
 
 func TestRequireAccount_PrefersFlag(t *testing.T) {
 	t.Setenv("GOG_ACCOUNT", "env@example.com")
@@ -28,9 +51,75 @@ func TestRequireAccount_UsesEnv(t *testing.T) {
 
 func TestRequireAccount_Missing(t *testing.T) {
 	t.Setenv("GOG_ACCOUNT", "")
+	withSecretsStore(t, newMemSecretsStore())
 	flags := &RootFlags{}
 	_, err := requireAccount(flags)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestRequireAccount_Alias(t *testing.T) {
+	withTempConfigHome(t)
+	withSecretsStore(t, storeWithTokens(t, "me@corp.com"))
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"auth", "alias", "set", "Work", "me@corp.com"}); err != nil {
+			t.Fatalf("alias set: %v", err)
+		}
+	})
+
+	got, err := requireAccount(&RootFlags{Account: "work"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got != "me@corp.com" {
+		t.Fatalf("got %q", got)
+	}
+
+	_, err = requireAccount(&RootFlags{Account: "home"})
+	if err == nil || !strings.Contains(err.Error(), "aliases: work") {
+		t.Fatalf("expected unknown alias error, got %v", err)
+	}
+}
+
+func TestRequireAccount_DefaultAccount(t *testing.T) {
+	t.Setenv("GOG_ACCOUNT", "")
+	store := storeWithTokens(t, "a@example.com", "b@example.com")
+	store.defaultAccount = "b@example.com"
+	withSecretsStore(t, store)
+
+	got, err := requireAccount(&RootFlags{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got != "b@example.com" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRequireAccount_SingleToken(t *testing.T) {
+	t.Setenv("GOG_ACCOUNT", "")
+	withSecretsStore(t, storeWithTokens(t, "only@example.com"))
+
+	got, err := requireAccount(&RootFlags{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got != "only@example.com" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRequireAccount_MultipleAccounts(t *testing.T) {
+	t.Setenv("GOG_ACCOUNT", "")
+	withSecretsStore(t, storeWithTokens(t, "b@example.com", "a@example.com"))
+
+	_, err := requireAccount(&RootFlags{})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(), "multiple accounts, choose one with --account: a@example.com, b@example.com") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type AuthAliasCmd struct {
+	List  AuthAliasListCmd  `cmd:"" name:"list" help:"List account aliases"`
+	Set   AuthAliasSetCmd   `cmd:"" name:"set" help:"Point an alias at a stored account (use with --account <alias>)"`
+	Unset AuthAliasUnsetCmd `cmd:"" name:"unset" help:"Remove an account alias"`
+}
+
+type AuthAliasListCmd struct{}
+
+func (c *AuthAliasListCmd) Run(ctx context.Context) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		aliases := cfg.AccountAliases
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"aliases": aliases})
+	}
+
+	names := cfg.AliasNames()
+	if len(names) == 0 {
+		ui.FromContext(ctx).Err().Println("No account aliases")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ALIAS\tEMAIL")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, cfg.AccountAliases[name])
+	}
+	return nil
+}
+
+type AuthAliasSetCmd struct {
+	Alias string `arg:"" name:"alias" help:"Alias name (e.g. work)"`
+	Email string `arg:"" name:"email" help:"Stored account email"`
+}
+
+func (c *AuthAliasSetCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+	alias := config.NormalizeAlias(c.Alias)
+	if err := config.ValidateAlias(alias); err != nil {
+		return usage(err.Error())
+	}
+	email := strings.ToLower(strings.TrimSpace(c.Email))
+	if !strings.Contains(email, "@") {
+		return usagef("invalid email %q", c.Email)
+	}
+
+	store, err := openSecretsStore()
+	if err != nil {
+		return err
+	}
+	tokens, err := store.ListTokens()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, t := range tokens {
+		if strings.EqualFold(t.Email, email) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return usagef("no stored token for %s (run: gog auth add %s)", email, email)
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.AccountAliases == nil {
+		cfg.AccountAliases = map[string]string{}
+	}
+	cfg.AccountAliases[alias] = email
+	if err := config.WriteConfig(cfg); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"alias": alias,
+			"email": email,
+		})
+	}
+	u.Out().Printf("alias\t%s", alias)
+	u.Out().Printf("email\t%s", email)
+	return nil
+}
+
+type AuthAliasUnsetCmd struct {
+	Alias string `arg:"" name:"alias" help:"Alias name"`
+}
+
+func (c *AuthAliasUnsetCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+	alias := config.NormalizeAlias(c.Alias)
+	if alias == "" {
+		return usage("empty alias")
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.AccountAliases[alias]; !ok {
+		return usagef("unknown account alias %q", alias)
+	}
+	delete(cfg.AccountAliases, alias)
+	if err := config.WriteConfig(cfg); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"deleted": true,
+			"alias":   alias,
+		})
+	}
+	u.Out().Printf("deleted\ttrue")
+	u.Out().Printf("alias\t%s", alias)
+	return nil
+}
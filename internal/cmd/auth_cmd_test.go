@@ -18,7 +18,8 @@ import (
 )
 
 type memSecretsStore struct {
-	tokens map[string]secrets.Token
+	tokens         map[string]secrets.Token
+	defaultAccount string
 }
 
 func newMemSecretsStore() *memSecretsStore {
@@ -83,10 +84,11 @@ func (s *memSecretsStore) ListTokens() ([]secrets.Token, error) {
 }
 
 func (s *memSecretsStore) GetDefaultAccount() (string, error) {
-	return "", nil
+	return s.defaultAccount, nil
 }
 
 func (s *memSecretsStore) SetDefaultAccount(email string) error {
+	s.defaultAccount = normalizeEmail(email)
 	return nil
 }
 
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type CacheCmd struct {
+	Stats CacheStatsCmd `cmd:"" name:"stats" help:"Show cache location, entry count and size"`
+	Clear CacheClearCmd `cmd:"" name:"clear" help:"Delete all cached responses"`
+}
+
+type CacheStatsCmd struct{}
+
+func (c *CacheStatsCmd) Run(ctx context.Context) error {
+	dir, err := config.HTTPCacheDir()
+	if err != nil {
+		return err
+	}
+	stats, err := googleapi.ReadCacheStats(dir, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, stats)
+	}
+
+	u := ui.FromContext(ctx)
+	u.Out().Printf("dir\t%s", stats.Dir)
+	u.Out().Printf("entries\t%d", stats.Entries)
+	u.Out().Printf("expired\t%d", stats.Expired)
+	u.Out().Printf("bytes\t%d", stats.Bytes)
+	if len(stats.Accounts) == 0 {
+		return nil
+	}
+
+	accounts := make([]string, 0, len(stats.Accounts))
+	for account := range stats.Accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ACCOUNT\tENTRIES")
+	for _, account := range accounts {
+		fmt.Fprintf(w, "%s\t%d\n", account, stats.Accounts[account])
+	}
+	return nil
+}
+
+type CacheClearCmd struct{}
+
+func (c *CacheClearCmd) Run(ctx context.Context) error {
+	dir, err := config.HTTPCacheDir()
+	if err != nil {
+		return err
+	}
+	removed, err := googleapi.ClearCache(dir)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"cleared": true,
+			"removed": removed,
+		})
+	}
+	u := ui.FromContext(ctx)
+	u.Out().Printf("cleared\ttrue")
+	u.Out().Printf("removed\t%d", removed)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
@@ -34,12 +35,12 @@ type CalendarCmd struct {
 }
 
 type CalendarCalendarsCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"100"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *CalendarCalendarsCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -50,39 +51,32 @@ func (c *CalendarCalendarsCmd) Run(ctx context.Context, flags *RootFlags) error
 		return err
 	}
 
-	resp, err := svc.CalendarList.List().MaxResults(c.Max).PageToken(c.Page).Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"calendars":     resp.Items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-	if len(resp.Items) == 0 {
-		u.Err().Println("No calendars")
-		return nil
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]*calendar.CalendarListEntry, string, error) {
+		resp, err := svc.CalendarList.List().MaxResults(pageSize).PageToken(pageToken).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
 	}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tNAME\tROLE")
-	for _, cal := range resp.Items {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", cal.Id, cal.Summary, cal.AccessRole)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[*calendar.CalendarListEntry]{
+		Key:    "calendars",
+		Empty:  "No calendars",
+		Header: "ID\tNAME\tROLE",
+		Row: func(cal *calendar.CalendarListEntry) string {
+			return fmt.Sprintf("%s\t%s\t%s", cal.Id, cal.Summary, cal.AccessRole)
+		},
+	}, fetch)
 }
 
 type CalendarAclCmd struct {
 	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID"`
-	Max        int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Max        int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"100"`
 	Page       string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *CalendarAclCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -97,57 +91,48 @@ func (c *CalendarAclCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	resp, err := svc.Acl.List(calendarID).MaxResults(c.Max).PageToken(c.Page).Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"rules":         resp.Items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-	if len(resp.Items) == 0 {
-		u.Err().Println("No ACL rules")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "SCOPE_TYPE\tSCOPE_VALUE\tROLE")
-	for _, rule := range resp.Items {
-		scopeType := ""
-		scopeValue := ""
-		if rule.Scope != nil {
-			scopeType = rule.Scope.Type
-			scopeValue = rule.Scope.Value
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]*calendar.AclRule, string, error) {
+		resp, err := svc.Acl.List(calendarID).MaxResults(pageSize).PageToken(pageToken).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\n", scopeType, scopeValue, rule.Role)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+		return resp.Items, resp.NextPageToken, nil
+	}
+
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[*calendar.AclRule]{
+		Key:    "rules",
+		Empty:  "No ACL rules",
+		Header: "SCOPE_TYPE\tSCOPE_VALUE\tROLE",
+		Row: func(rule *calendar.AclRule) string {
+			scopeType := ""
+			scopeValue := ""
+			if rule.Scope != nil {
+				scopeType = rule.Scope.Type
+				scopeValue = rule.Scope.Value
+			}
+			return fmt.Sprintf("%s\t%s\t%s", scopeType, scopeValue, rule.Role)
+		},
+	}, fetch)
 }
 
 type CalendarEventsCmd struct {
-	CalendarID string `arg:"" name:"calendarId" optional:"" help:"Calendar ID"`
+	CalendarID string `arg:"" name:"calendarId" optional:"" help:"Calendar ID (default: profile calendar_id)" default:"${default_calendar}"`
 	From       string `name:"from" help:"Start time (RFC3339; default: now)"`
 	To         string `name:"to" help:"End time (RFC3339; default: +7d)"`
-	Max        int64  `name:"max" aliases:"limit" help:"Max results" default:"10"`
+	Max        int64  `name:"max" aliases:"limit" help:"Max results (per page with --all-pages)" default:"10"`
 	Page       string `name:"page" help:"Page token"`
+	AllPages   bool   `name:"all-pages" help:"Fetch all pages"`
+	MaxTotal   int64  `name:"max-total" help:"Stop after this many events in total (implies --all-pages)"`
 	Query      string `name:"query" help:"Free text search"`
 	All        bool   `name:"all" help:"Fetch events from all calendars"`
 }
 
 func (c *CalendarEventsCmd) Run(ctx context.Context, flags *RootFlags) error {
-	account, err := requireAccount(flags)
-	if err != nil {
-		return err
-	}
-
 	if !c.All && strings.TrimSpace(c.CalendarID) == "" {
 		return usage("calendarId required unless --all is specified")
 	}
-	if c.All && strings.TrimSpace(c.CalendarID) != "" {
+	// The profile's calendar_id is only a default; --all overrides it.
+	if c.All && strings.TrimSpace(c.CalendarID) != "" && c.CalendarID != flags.profile.CalendarID {
 		return usage("calendarId not allowed with --all flag")
 	}
 
@@ -162,16 +147,27 @@ func (c *CalendarEventsCmd) Run(ctx context.Context, flags *RootFlags) error {
 		to = oneWeekLater.Format(time.RFC3339)
 	}
 
-	svc, err := newCalendarService(ctx, account)
-	if err != nil {
-		return err
-	}
-
+	opts := pageOptions{Page: c.Page, Max: c.Max, All: c.AllPages, MaxTotal: c.MaxTotal}
 	if c.All {
-		return listAllCalendarsEvents(ctx, svc, from, to, c.Max, c.Page, c.Query)
+		account, err := requireAccount(flags)
+		if err != nil {
+			return err
+		}
+		svc, err := newCalendarService(ctx, account)
+		if err != nil {
+			return err
+		}
+		return listAllCalendarsEvents(ctx, svc, from, to, opts, c.Query)
 	}
+
 	calendarID := strings.TrimSpace(c.CalendarID)
-	return listCalendarEvents(ctx, svc, calendarID, from, to, c.Max, c.Page, c.Query)
+	return runList(ctx, flags, opts, calendarEventsOutput(), func(ctx context.Context, account string) (pageFetcher[*calendar.Event], error) {
+		svc, err := newCalendarService(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		return calendarEventsFetcher(svc, calendarID, from, to, c.Query), nil
+	})
 }
 
 type CalendarEventCmd struct {
@@ -204,7 +200,7 @@ func (c *CalendarEventCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"event": event})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"event": event})
 	}
 	printCalendarEvent(u, event)
 	return nil
@@ -255,7 +251,7 @@ func (c *CalendarCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"event": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"event": created})
 	}
 	printCalendarEvent(u, created)
 	return nil
@@ -335,7 +331,7 @@ func (c *CalendarUpdateCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"event": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"event": updated})
 	}
 	printCalendarEvent(u, updated)
 	return nil
@@ -374,7 +370,7 @@ func (c *CalendarDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"deleted":    true,
 			"calendarId": calendarID,
 			"eventId":    eventID,
@@ -427,7 +423,7 @@ func (c *CalendarFreeBusyCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"calendars": resp.Calendars})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"calendars": resp.Calendars})
 	}
 
 	if len(resp.Calendars) == 0 {
@@ -446,44 +442,39 @@ func (c *CalendarFreeBusyCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
-func listCalendarEvents(ctx context.Context, svc *calendar.Service, calendarID, from, to string, maxResults int64, page, query string) error {
-	u := ui.FromContext(ctx)
-
-	call := svc.Events.List(calendarID).
-		TimeMin(from).
-		TimeMax(to).
-		MaxResults(maxResults).
-		PageToken(page).
-		SingleEvents(true).
-		OrderBy("startTime")
-	if strings.TrimSpace(query) != "" {
-		call = call.Q(query)
-	}
-	resp, err := call.Context(ctx).Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"events":        resp.Items,
-			"nextPageToken": resp.NextPageToken,
-		})
+func calendarEventsFetcher(svc *calendar.Service, calendarID, from, to, query string) pageFetcher[*calendar.Event] {
+	return func(ctx context.Context, pageToken string, maxResults int64) ([]*calendar.Event, string, error) {
+		call := svc.Events.List(calendarID).
+			TimeMin(from).
+			TimeMax(to).
+			MaxResults(maxResults).
+			PageToken(pageToken).
+			SingleEvents(true).
+			OrderBy("startTime")
+		if strings.TrimSpace(query) != "" {
+			call = call.Q(query)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
 	}
+}
 
-	if len(resp.Items) == 0 {
-		u.Err().Println("No events")
-		return nil
+func calendarEventsOutput() listOutput[*calendar.Event] {
+	return listOutput[*calendar.Event]{
+		Key:    "events",
+		Empty:  "No events",
+		Header: "ID\tSTART\tEND\tSUMMARY",
+		Row: func(e *calendar.Event) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s", e.Id, eventStart(e), eventEnd(e), e.Summary)
+		},
 	}
+}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-
-	fmt.Fprintln(w, "ID\tSTART\tEND\tSUMMARY")
-	for _, e := range resp.Items {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Id, eventStart(e), eventEnd(e), e.Summary)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+func listCalendarEvents(ctx context.Context, svc *calendar.Service, calendarID, from, to string, opts pageOptions, query string) error {
+	return writeList(ctx, opts, calendarEventsOutput(), calendarEventsFetcher(svc, calendarID, from, to, query))
 }
 
 type eventWithCalendar struct {
@@ -491,7 +482,7 @@ type eventWithCalendar struct {
 	CalendarID string
 }
 
-func listAllCalendarsEvents(ctx context.Context, svc *calendar.Service, from, to string, maxResults int64, page, query string) error {
+func listAllCalendarsEvents(ctx context.Context, svc *calendar.Service, from, to string, opts pageOptions, query string) error {
 	u := ui.FromContext(ctx)
 
 	calResp, err := svc.CalendarList.List().Context(ctx).Do()
@@ -504,42 +495,66 @@ func listAllCalendarsEvents(ctx context.Context, svc *calendar.Service, from, to
 		return nil
 	}
 
+	fetchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
 	all := []*eventWithCalendar{}
+	var total int64
 	for _, c := range calResp.Items {
-		events, err := svc.Events.List(c.Id).
-			TimeMin(from).
-			TimeMax(to).
-			MaxResults(maxResults).
-			PageToken(page).
-			SingleEvents(true).
-			OrderBy("startTime").
-			Q(query).
-			Context(ctx).
-			Do()
+		calOpts := opts
+		if opts.MaxTotal > 0 {
+			if total >= opts.MaxTotal {
+				break
+			}
+			calOpts.MaxTotal = opts.MaxTotal - total
+		}
+		fetch := calendarEventsFetcher(svc, c.Id, from, to, query)
+		_, err := fetchPages(fetchCtx, calOpts, fetch, func(events []*calendar.Event) error {
+			total += int64(len(events))
+			for _, e := range events {
+				ev := &eventWithCalendar{Event: e, CalendarID: c.Id}
+				if outfmt.IsNDJSON(ctx) {
+					if err := outfmt.WriteJSON(ctx, os.Stdout, ev); err != nil {
+						return err
+					}
+					continue
+				}
+				all = append(all, ev)
+			}
+			return nil
+		})
 		if err != nil {
+			if fetchCtx.Err() != nil {
+				u.Err().Printf("Interrupted; writing %d partial results", len(all))
+				break
+			}
 			u.Err().Printf("calendar %s: %v", c.Id, err)
 			continue
 		}
-		for _, e := range events.Items {
-			all = append(all, &eventWithCalendar{Event: e, CalendarID: c.Id})
-		}
 	}
+	interrupted := interruptedOr(fetchCtx, fetchCtx.Err())
 
+	if outfmt.IsNDJSON(ctx) {
+		return interrupted
+	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"events": all})
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"events": all}); err != nil {
+			return err
+		}
+		return interrupted
 	}
 	if len(all) == 0 {
 		u.Err().Println("No events")
-		return nil
+		return interrupted
 	}
 
 	w, flush := tableWriter(ctx)
-	defer flush()
 	fmt.Fprintln(w, "CALENDAR\tID\tSTART\tEND\tSUMMARY")
 	for _, e := range all {
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.CalendarID, e.Id, eventStart(e.Event), eventEnd(e.Event), e.Summary)
 	}
-	return nil
+	flush()
+	return interrupted
 }
 
 func printCalendarEvent(u *ui.UI, event *calendar.Event) {
@@ -6,7 +6,6 @@ import (
 	"os"
 	"sort"
 	"strconv"
-	"text/tabwriter"
 
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
@@ -32,7 +31,7 @@ func (c *CalendarColorsCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"event":    colors.Event,
 			"calendar": colors.Calendar,
 		})
@@ -44,9 +43,9 @@ func (c *CalendarColorsCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if len(colors.Event) > 0 {
-		fmt.Println("EVENT COLORS:")
-		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		fmt.Fprintln(tw, "ID\tBACKGROUND\tFOREGROUND")
+		tableNotes(ctx).Println("EVENT COLORS:")
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "ID\tBACKGROUND\tFOREGROUND")
 
 		ids := make([]int, 0, len(colors.Event))
 		for id := range colors.Event {
@@ -59,16 +58,16 @@ func (c *CalendarColorsCmd) Run(ctx context.Context, flags *RootFlags) error {
 		for _, num := range ids {
 			id := strconv.Itoa(num)
 			c := colors.Event[id]
-			fmt.Fprintf(tw, "%s\t%s\t%s\n", id, c.Background, c.Foreground)
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, c.Background, c.Foreground)
 		}
-		_ = tw.Flush()
-		fmt.Println()
+		flush()
+		tableNotes(ctx).Println("")
 	}
 
 	if len(colors.Calendar) > 0 {
-		fmt.Println("CALENDAR COLORS:")
-		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		fmt.Fprintln(tw, "ID\tBACKGROUND\tFOREGROUND")
+		tableNotes(ctx).Println("CALENDAR COLORS:")
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "ID\tBACKGROUND\tFOREGROUND")
 
 		ids := make([]int, 0, len(colors.Calendar))
 		for id := range colors.Calendar {
@@ -81,9 +80,9 @@ func (c *CalendarColorsCmd) Run(ctx context.Context, flags *RootFlags) error {
 		for _, num := range ids {
 			id := strconv.Itoa(num)
 			c := colors.Calendar[id]
-			fmt.Fprintf(tw, "%s\t%s\t%s\n", id, c.Background, c.Foreground)
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, c.Background, c.Foreground)
 		}
-		_ = tw.Flush()
+		flush()
 	}
 
 	return nil
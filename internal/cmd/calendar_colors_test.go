@@ -178,6 +178,19 @@ func TestCalendarColorsCmd_TableOutput(t *testing.T) {
 	if !strings.Contains(out, "#1d1d1d") {
 		t.Errorf("output missing foreground color: %q", out)
 	}
+
+	// With --output csv the headings and the blank separator go to stderr.
+	out = captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--account", "a@b.com", "--output", "csv", "calendar", "colors"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+	want := "ID,BACKGROUND,FOREGROUND\n1,#a4bdfc,#1d1d1d\n2,#7ae7bf,#1d1d1d\nID,BACKGROUND,FOREGROUND\n1,#ac725e,#1d1d1d\n"
+	if out != want {
+		t.Errorf("unexpected csv output: %q", out)
+	}
 }
 
 func TestCalendarColorsCmd_EmptyColors(t *testing.T) {
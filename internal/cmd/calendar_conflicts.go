@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"google.golang.org/api/calendar/v3"
@@ -72,7 +71,7 @@ func (c *CalendarConflictsCmd) Run(ctx context.Context, flags *RootFlags) error
 	conflicts := detectConflicts(resp.Calendars)
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"conflicts": conflicts,
 			"count":     len(conflicts),
 		})
@@ -83,13 +82,15 @@ func (c *CalendarConflictsCmd) Run(ctx context.Context, flags *RootFlags) error
 		return nil
 	}
 
-	fmt.Printf("CONFLICTS FOUND: %d\n\n", len(conflicts))
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "START\tEND\tCALENDARS")
+	notes := tableNotes(ctx)
+	notes.Printf("CONFLICTS FOUND: %d", len(conflicts))
+	notes.Println("")
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "START\tEND\tCALENDARS")
 	for _, c := range conflicts {
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Start, c.End, strings.Join(c.Calendars, ", "))
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Start, c.End, strings.Join(c.Calendars, ", "))
 	}
-	_ = tw.Flush()
 	return nil
 }
 
@@ -245,6 +245,28 @@ func TestCalendarConflictsCmd_TableOutput(t *testing.T) {
 	if !strings.Contains(out, "START") || !strings.Contains(out, "END") || !strings.Contains(out, "CALENDARS") {
 		t.Errorf("output missing table headers: %q", out)
 	}
+
+	// --output csv keeps stdout to the table; the summary goes to stderr.
+	var stderr string
+	out = captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := Execute([]string{
+				"--account", "a@b.com", "--output", "csv",
+				"calendar", "conflicts",
+				"--from", "2024-12-13T09:00:00Z",
+				"--to", "2024-12-13T12:00:00Z",
+				"--calendars", "primary,work@example.com",
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+	if !strings.HasPrefix(out, "START,END,CALENDARS\n") || strings.Contains(out, "CONFLICTS FOUND") {
+		t.Errorf("unexpected csv output: %q", out)
+	}
+	if !strings.Contains(stderr, "CONFLICTS FOUND: 1") {
+		t.Errorf("expected summary on stderr: %q", stderr)
+	}
 }
 
 func TestCalendarConflictsCmd_MultiCalendar(t *testing.T) {
@@ -90,7 +90,7 @@ func (c *CalendarRespondCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"event": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"event": updated})
 	}
 
 	u.Out().Printf("id\t%s", updated.Id)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/steipete/gogcli/internal/outfmt"
@@ -16,7 +15,7 @@ type CalendarSearchCmd struct {
 	Query      string `arg:"" name:"query" help:"Search query"`
 	From       string `name:"from" help:"Start time (RFC3339; default: 30 days ago)"`
 	To         string `name:"to" help:"End time (RFC3339; default: 90 days from now)"`
-	CalendarID string `name:"calendar" help:"Calendar ID" default:"primary"`
+	CalendarID string `name:"calendar" help:"Calendar ID" default:"${calendar}"`
 	Max        int64  `name:"max" aliases:"limit" help:"Max results" default:"25"`
 }
 
@@ -63,7 +62,7 @@ func (c *CalendarSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"events": resp.Items,
 			"query":  query,
 		})
@@ -74,11 +73,11 @@ func (c *CalendarSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "ID\tSTART\tEND\tSUMMARY")
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "ID\tSTART\tEND\tSUMMARY")
 	for _, e := range resp.Items {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Id, eventStart(e), eventEnd(e), e.Summary)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Id, eventStart(e), eventEnd(e), e.Summary)
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
@@ -11,8 +11,8 @@ import (
 )
 
 type CalendarTimeCmd struct {
-	CalendarID string `name:"calendar" help:"Calendar ID to get timezone from" default:"primary"`
-	Timezone   string `name:"timezone" help:"Override timezone (e.g., America/New_York, UTC)"`
+	CalendarID string `name:"calendar" help:"Calendar ID to get timezone from" default:"${calendar}"`
+	Timezone   string `name:"timezone" help:"Override timezone (e.g., America/New_York, UTC)" default:"${timezone}"`
 }
 
 func (c *CalendarTimeCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -57,7 +57,7 @@ func (c *CalendarTimeCmd) Run(ctx context.Context, flags *RootFlags) error {
 	formatted := now.Format("Monday, January 02, 2006 03:04 PM")
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"timezone":     tz,
 			"current_time": now.Format(time.RFC3339),
 			"formatted":    formatted,
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type ConfigCmd struct {
+	Get  ConfigGetCmd  `cmd:"" name:"get" help:"Print a config value (profile key with --profile)"`
+	Set  ConfigSetCmd  `cmd:"" name:"set" help:"Set a config value (profile key with --profile; empty value clears)"`
+	List ConfigListCmd `cmd:"" name:"list" help:"List config values and profiles"`
+	Path ConfigPathCmd `cmd:"" name:"path" help:"Print the config file path"`
+}
+
+type ConfigGetCmd struct {
+	Key string `arg:"" name:"key" help:"Key (keyring_backend|retries|http_timeout|circuit_breaker_threshold|circuit_breaker_reset, or account|output|color|timezone|calendar_id|drive_parent|tasklist with --profile)"`
+}
+
+func (c *ConfigGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	key := strings.TrimSpace(c.Key)
+	profileName := strings.TrimSpace(flags.Profile)
+
+	var value string
+	if profileName == "" {
+		if value, err = cfg.Get(key); err != nil {
+			return usage(err.Error())
+		}
+	} else {
+		p, err := cfg.Profile(profileName)
+		if err != nil {
+			return usage(err.Error())
+		}
+		if value, err = p.Get(key); err != nil {
+			return usage(err.Error())
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"profile": profileName,
+			"key":     key,
+			"value":   value,
+		})
+	}
+	u.Out().Println(value)
+	return nil
+}
+
+type ConfigSetCmd struct {
+	Key   string `arg:"" name:"key" help:"Key (keyring_backend|retries|http_timeout|circuit_breaker_*, or a profile key with --profile)"`
+	Value string `arg:"" name:"value" optional:"" help:"Value (omit to clear)"`
+}
+
+func (c *ConfigSetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	key := strings.TrimSpace(c.Key)
+	value := strings.TrimSpace(c.Value)
+	profileName := strings.TrimSpace(flags.Profile)
+
+	if profileName == "" {
+		if err := cfg.Set(key, value); err != nil {
+			return usage(err.Error())
+		}
+		value, _ = cfg.Get(key)
+	} else {
+		// Setting a key on an unknown profile creates it.
+		p := cfg.Profiles[profileName]
+		if err := p.Set(key, value); err != nil {
+			return usage(err.Error())
+		}
+		if cfg.Profiles == nil {
+			cfg.Profiles = map[string]config.Profile{}
+		}
+		cfg.Profiles[profileName] = p
+	}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"profile": profileName,
+			"key":     key,
+			"value":   value,
+		})
+	}
+	u.Out().Printf("%s\t%s", key, value)
+	return nil
+}
+
+type ConfigListCmd struct{}
+
+func (c *ConfigListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	profileName := strings.TrimSpace(flags.Profile)
+	if profileName != "" {
+		p, err := cfg.Profile(profileName)
+		if err != nil {
+			return usage(err.Error())
+		}
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+				"profile": profileName,
+				"values":  p,
+			})
+		}
+		w, flush := tableWriter(ctx)
+		defer flush()
+		fmt.Fprintln(w, "KEY\tVALUE")
+		for _, key := range config.ProfileKeys {
+			v, _ := p.Get(key)
+			fmt.Fprintf(w, "%s\t%s\n", key, v)
+		}
+		return nil
+	}
+
+	if outfmt.IsJSON(ctx) {
+		profiles := cfg.Profiles
+		if profiles == nil {
+			profiles = map[string]config.Profile{}
+		}
+		out := map[string]any{
+			"path":     path,
+			"profiles": profiles,
+		}
+		for _, key := range config.GlobalKeys {
+			out[key], _ = cfg.Get(key)
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "KEY\tVALUE")
+	fmt.Fprintf(w, "%s\t%s\n", config.KeyringBackendKey, cfg.KeyringBackend)
+	for _, key := range config.GlobalKeys[1:] {
+		if v, _ := cfg.Get(key); v != "" {
+			fmt.Fprintf(w, "%s\t%s\n", key, v)
+		}
+	}
+	for _, name := range cfg.ProfileNames() {
+		p := cfg.Profiles[name]
+		for _, key := range config.ProfileKeys {
+			if v, _ := p.Get(key); v != "" {
+				fmt.Fprintf(w, "profiles.%s.%s\t%s\n", name, key, v)
+			}
+		}
+	}
+	return nil
+}
+
+type ConfigPathCmd struct{}
+
+func (c *ConfigPathCmd) Run(ctx context.Context) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": path})
+	}
+	ui.FromContext(ctx).Out().Println(path)
+	return nil
+}
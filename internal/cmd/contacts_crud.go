@@ -16,12 +16,19 @@ import (
 const contactsReadMask = "names,emailAddresses,phoneNumbers"
 
 type ContactsListCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"100"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
+}
+
+type contactItem struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
 }
 
 func (c *ContactsListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -32,60 +39,44 @@ func (c *ContactsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	resp, err := svc.People.Connections.List("people/me").
-		PersonFields(contactsReadMask).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource string `json:"resource"`
-			Name     string `json:"name,omitempty"`
-			Email    string `json:"email,omitempty"`
-			Phone    string `json:"phone,omitempty"`
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]contactItem, string, error) {
+		resp, err := svc.People.Connections.List("people/me").
+			PersonFields(contactsReadMask).
+			PageSize(pageSize).
+			PageToken(pageToken).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", err
 		}
-		items := make([]item, 0, len(resp.Connections))
+		items := make([]contactItem, 0, len(resp.Connections))
 		for _, p := range resp.Connections {
 			if p == nil {
 				continue
 			}
-			items = append(items, item{
+			items = append(items, contactItem{
 				Resource: p.ResourceName,
 				Name:     primaryName(p),
 				Email:    primaryEmail(p),
 				Phone:    primaryPhone(p),
 			})
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"contacts":      items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-	if len(resp.Connections) == 0 {
-		u.Err().Println("No contacts")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "RESOURCE\tNAME\tEMAIL\tPHONE")
-	for _, p := range resp.Connections {
-		if p == nil {
-			continue
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			p.ResourceName,
-			sanitizeTab(primaryName(p)),
-			sanitizeTab(primaryEmail(p)),
-			sanitizeTab(primaryPhone(p)),
-		)
-	}
-
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+		return items, resp.NextPageToken, nil
+	}
+
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[contactItem]{
+		Key:    "contacts",
+		Empty:  "No contacts",
+		Header: "RESOURCE\tNAME\tEMAIL\tPHONE",
+		Row: func(it contactItem) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s",
+				it.Resource,
+				sanitizeTab(it.Name),
+				sanitizeTab(it.Email),
+				sanitizeTab(it.Phone),
+			)
+		},
+	}, fetch)
 }
 
 type ContactsGetCmd struct {
@@ -137,7 +128,7 @@ func (c *ContactsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 		if p == nil {
 			if outfmt.IsJSON(ctx) {
-				return outfmt.WriteJSON(os.Stdout, map[string]any{"found": false})
+				return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"found": false})
 			}
 			u.Err().Println("Not found")
 			return nil
@@ -145,7 +136,7 @@ func (c *ContactsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"contact": p})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"contact": p})
 	}
 
 	u.Out().Printf("resource\t%s", p.ResourceName)
@@ -199,7 +190,7 @@ func (c *ContactsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"contact": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"contact": created})
 	}
 	u.Out().Printf("resource\t%s", created.ResourceName)
 	return nil
@@ -281,7 +272,7 @@ func (c *ContactsUpdateCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"contact": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"contact": updated})
 	}
 	u.Out().Printf("resource\t%s", updated.ResourceName)
 	return nil
@@ -314,7 +305,7 @@ func (c *ContactsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"deleted": true, "resource": resourceName})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"deleted": true, "resource": resourceName})
 	}
 	u.Out().Printf("deleted\ttrue")
 	u.Out().Printf("resource\t%s", resourceName)
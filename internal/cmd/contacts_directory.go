@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"google.golang.org/api/people/v1"
+
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
@@ -22,12 +24,12 @@ type ContactsDirectoryCmd struct {
 }
 
 type ContactsDirectoryListCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results" default:"50"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"50"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *ContactsDirectoryListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -38,72 +40,34 @@ func (c *ContactsDirectoryListCmd) Run(ctx context.Context, flags *RootFlags) er
 		return err
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, directoryRequestTimeout)
-	defer cancel()
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]contactItem, string, error) {
+		ctxTimeout, cancel := context.WithTimeout(ctx, directoryRequestTimeout)
+		defer cancel()
 
-	resp, err := svc.People.ListDirectoryPeople().
-		Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE").
-		ReadMask(directoryReadMask).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		Context(ctxTimeout).
-		Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource string `json:"resource"`
-			Name     string `json:"name,omitempty"`
-			Email    string `json:"email,omitempty"`
+		resp, err := svc.People.ListDirectoryPeople().
+			Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE").
+			ReadMask(directoryReadMask).
+			PageSize(pageSize).
+			PageToken(pageToken).
+			Context(ctxTimeout).
+			Do()
+		if err != nil {
+			return nil, "", err
 		}
-		items := make([]item, 0, len(resp.People))
-		for _, p := range resp.People {
-			if p == nil {
-				continue
-			}
-			items = append(items, item{
-				Resource: p.ResourceName,
-				Name:     primaryName(p),
-				Email:    primaryEmail(p),
-			})
-		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"people":        items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.People) == 0 {
-		u.Err().Println("No results")
-		return nil
+		return directoryItems(resp.People), resp.NextPageToken, nil
 	}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "RESOURCE\tNAME\tEMAIL")
-	for _, p := range resp.People {
-		if p == nil {
-			continue
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\n",
-			p.ResourceName,
-			sanitizeTab(primaryName(p)),
-			sanitizeTab(primaryEmail(p)),
-		)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return writeList(ctx, c.options(c.Page, c.Max), directoryOutput(), fetch)
 }
 
 type ContactsDirectorySearchCmd struct {
 	Query []string `arg:"" name:"query" help:"Search query"`
-	Max   int64    `name:"max" aliases:"limit" help:"Max results" default:"50"`
+	Max   int64    `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"50"`
 	Page  string   `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *ContactsDirectorySearchCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -115,63 +79,53 @@ func (c *ContactsDirectorySearchCmd) Run(ctx context.Context, flags *RootFlags)
 		return err
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(ctx, directoryRequestTimeout)
-	defer cancel()
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]contactItem, string, error) {
+		ctxTimeout, cancel := context.WithTimeout(ctx, directoryRequestTimeout)
+		defer cancel()
 
-	resp, err := svc.People.SearchDirectoryPeople().
-		Query(query).
-		Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE").
-		ReadMask(directoryReadMask).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		Context(ctxTimeout).
-		Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource string `json:"resource"`
-			Name     string `json:"name,omitempty"`
-			Email    string `json:"email,omitempty"`
-		}
-		items := make([]item, 0, len(resp.People))
-		for _, p := range resp.People {
-			if p == nil {
-				continue
-			}
-			items = append(items, item{
-				Resource: p.ResourceName,
-				Name:     primaryName(p),
-				Email:    primaryEmail(p),
-			})
+		resp, err := svc.People.SearchDirectoryPeople().
+			Query(query).
+			Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE").
+			ReadMask(directoryReadMask).
+			PageSize(pageSize).
+			PageToken(pageToken).
+			Context(ctxTimeout).
+			Do()
+		if err != nil {
+			return nil, "", err
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"people":        items,
-			"nextPageToken": resp.NextPageToken,
-		})
+		return directoryItems(resp.People), resp.NextPageToken, nil
 	}
 
-	if len(resp.People) == 0 {
-		u.Err().Println("No results")
-		return nil
-	}
+	return writeList(ctx, c.options(c.Page, c.Max), directoryOutput(), fetch)
+}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "RESOURCE\tNAME\tEMAIL")
-	for _, p := range resp.People {
+// directoryItems maps directory people to list items (directory profiles
+// carry no phone numbers in directoryReadMask).
+func directoryItems(persons []*people.Person) []contactItem {
+	items := make([]contactItem, 0, len(persons))
+	for _, p := range persons {
 		if p == nil {
 			continue
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\n",
-			p.ResourceName,
-			sanitizeTab(primaryName(p)),
-			sanitizeTab(primaryEmail(p)),
-		)
+		items = append(items, contactItem{
+			Resource: p.ResourceName,
+			Name:     primaryName(p),
+			Email:    primaryEmail(p),
+		})
+	}
+	return items
+}
+
+func directoryOutput() listOutput[contactItem] {
+	return listOutput[contactItem]{
+		Key:    "people",
+		Empty:  "No results",
+		Header: "RESOURCE\tNAME\tEMAIL",
+		Row: func(it contactItem) string {
+			return fmt.Sprintf("%s\t%s\t%s", it.Resource, sanitizeTab(it.Name), sanitizeTab(it.Email))
+		},
 	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
 }
 
 type ContactsOtherCmd struct {
@@ -180,12 +134,12 @@ type ContactsOtherCmd struct {
 }
 
 type ContactsOtherListCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"100"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *ContactsOtherListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -196,60 +150,44 @@ func (c *ContactsOtherListCmd) Run(ctx context.Context, flags *RootFlags) error
 		return err
 	}
 
-	resp, err := svc.OtherContacts.List().
-		ReadMask(contactsReadMask).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource string `json:"resource"`
-			Name     string `json:"name,omitempty"`
-			Email    string `json:"email,omitempty"`
-			Phone    string `json:"phone,omitempty"`
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]contactItem, string, error) {
+		resp, err := svc.OtherContacts.List().
+			ReadMask(contactsReadMask).
+			PageSize(pageSize).
+			PageToken(pageToken).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", err
 		}
-		items := make([]item, 0, len(resp.OtherContacts))
+		items := make([]contactItem, 0, len(resp.OtherContacts))
 		for _, p := range resp.OtherContacts {
 			if p == nil {
 				continue
 			}
-			items = append(items, item{
+			items = append(items, contactItem{
 				Resource: p.ResourceName,
 				Name:     primaryName(p),
 				Email:    primaryEmail(p),
 				Phone:    primaryPhone(p),
 			})
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"contacts":      items,
-			"nextPageToken": resp.NextPageToken,
-		})
+		return items, resp.NextPageToken, nil
 	}
 
-	if len(resp.OtherContacts) == 0 {
-		u.Err().Println("No results")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "RESOURCE\tNAME\tEMAIL\tPHONE")
-	for _, p := range resp.OtherContacts {
-		if p == nil {
-			continue
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			p.ResourceName,
-			sanitizeTab(primaryName(p)),
-			sanitizeTab(primaryEmail(p)),
-			sanitizeTab(primaryPhone(p)),
-		)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[contactItem]{
+		Key:    "contacts",
+		Empty:  "No results",
+		Header: "RESOURCE\tNAME\tEMAIL\tPHONE",
+		Row: func(it contactItem) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s",
+				it.Resource,
+				sanitizeTab(it.Name),
+				sanitizeTab(it.Email),
+				sanitizeTab(it.Phone),
+			)
+		},
+	}, fetch)
 }
 
 type ContactsOtherSearchCmd struct {
@@ -298,7 +236,7 @@ func (c *ContactsOtherSearchCmd) Run(ctx context.Context, flags *RootFlags) erro
 				Phone:    primaryPhone(p),
 			})
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"contacts": items})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"contacts": items})
 	}
 
 	if len(resp.Results) == 0 {
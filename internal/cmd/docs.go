@@ -93,7 +93,7 @@ func (c *DocsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"file": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"file": created})
 	}
 
 	u.Out().Printf("id\t%s", created.Id)
@@ -174,7 +174,7 @@ func (c *DocsCatCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"text": string(b)})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"text": string(b)})
 	}
 	_, err = os.Stdout.Write(b)
 	return err
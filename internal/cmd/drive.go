@@ -60,14 +60,14 @@ type DriveCmd struct {
 }
 
 type DriveLsCmd struct {
-	Max    int64  `name:"max" aliases:"limit" help:"Max results" default:"20"`
+	Max    int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"20"`
 	Page   string `name:"page" help:"Page token"`
 	Query  string `name:"query" help:"Drive query filter"`
-	Parent string `name:"parent" help:"Folder ID to list (default: root)"`
+	Parent string `name:"parent" help:"Folder ID to list (default: root)" default:"${drive_parent}"`
+	PagingFlags
 }
 
 func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -85,114 +85,68 @@ func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	q := buildDriveListQuery(folderID, c.Query)
 
-	resp, err := svc.Files.List().
-		Q(q).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		OrderBy("modifiedTime desc").
-		SupportsAllDrives(true).
-		IncludeItemsFromAllDrives(true).
-		Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"files":         resp.Files,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Files) == 0 {
-		u.Err().Println("No files")
-		return nil
+	return writeList(ctx, c.options(c.Page, c.Max), driveFilesOutput("No files"), driveFilesFetcher(svc, q))
+}
+
+func driveFilesFetcher(svc *drive.Service, q string) pageFetcher[*drive.File] {
+	return func(ctx context.Context, pageToken string, pageSize int64) ([]*drive.File, string, error) {
+		resp, err := svc.Files.List().
+			Q(q).
+			PageSize(pageSize).
+			PageToken(pageToken).
+			OrderBy("modifiedTime desc").
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Files, resp.NextPageToken, nil
 	}
+}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSIZE\tMODIFIED")
-	for _, f := range resp.Files {
-		fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\n",
-			f.Id,
-			f.Name,
-			driveType(f.MimeType),
-			formatDriveSize(f.Size),
-			formatDateTime(f.ModifiedTime),
-		)
+func driveFilesOutput(empty string) listOutput[*drive.File] {
+	return listOutput[*drive.File]{
+		Key:    "files",
+		Empty:  empty,
+		Header: "ID\tNAME\tTYPE\tSIZE\tMODIFIED",
+		Row: func(f *drive.File) string {
+			return fmt.Sprintf(
+				"%s\t%s\t%s\t%s\t%s",
+				f.Id,
+				f.Name,
+				driveType(f.MimeType),
+				formatDriveSize(f.Size),
+				formatDateTime(f.ModifiedTime),
+			)
+		},
 	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
 }
 
 type DriveSearchCmd struct {
 	Query []string `arg:"" name:"query" help:"Search query"`
-	Max   int64    `name:"max" aliases:"limit" help:"Max results" default:"20"`
+	Max   int64    `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"20"`
 	Page  string   `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
-	if err != nil {
-		return err
-	}
 	query := strings.TrimSpace(strings.Join(c.Query, " "))
 	if query == "" {
 		return usage("missing query")
 	}
 
-	svc, err := newDriveService(ctx, account)
-	if err != nil {
-		return err
-	}
+	q := buildDriveSearchQuery(query)
 
-	resp, err := svc.Files.List().
-		Q(buildDriveSearchQuery(query)).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		OrderBy("modifiedTime desc").
-		SupportsAllDrives(true).
-		IncludeItemsFromAllDrives(true).
-		Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"files":         resp.Files,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Files) == 0 {
-		u.Err().Println("No results")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSIZE\tMODIFIED")
-	for _, f := range resp.Files {
-		fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\n",
-			f.Id,
-			f.Name,
-			driveType(f.MimeType),
-			formatDriveSize(f.Size),
-			formatDateTime(f.ModifiedTime),
-		)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return runList(ctx, flags, c.options(c.Page, c.Max), driveFilesOutput("No results"), func(ctx context.Context, account string) (pageFetcher[*drive.File], error) {
+		svc, err := newDriveService(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		return driveFilesFetcher(svc, q), nil
+	})
 }
 
 type DriveGetCmd struct {
@@ -225,7 +179,7 @@ func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"file": f})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"file": f})
 	}
 
 	u.Out().Printf("id\t%s", f.Id)
@@ -290,7 +244,7 @@ func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"path": downloadedPath,
 			"size": size,
 		})
@@ -316,7 +270,7 @@ func (c *DriveCopyCmd) Run(ctx context.Context, flags *RootFlags) error {
 type DriveUploadCmd struct {
 	LocalPath string `arg:"" name:"localPath" help:"Path to local file"`
 	Name      string `name:"name" help:"Override filename"`
-	Parent    string `name:"parent" help:"Destination folder ID"`
+	Parent    string `name:"parent" help:"Destination folder ID" default:"${drive_parent}"`
 }
 
 func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -365,7 +319,7 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"file": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"file": created})
 	}
 
 	u.Out().Printf("id\t%s", created.Id)
@@ -378,7 +332,7 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 type DriveMkdirCmd struct {
 	Name   string `arg:"" name:"name" help:"Folder name"`
-	Parent string `name:"parent" help:"Parent folder ID"`
+	Parent string `name:"parent" help:"Parent folder ID" default:"${drive_parent}"`
 }
 
 func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -416,7 +370,7 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"folder": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"folder": created})
 	}
 
 	u.Out().Printf("id\t%s", created.Id)
@@ -455,7 +409,7 @@ func (c *DriveDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"deleted": true,
 			"id":      fileID,
 		})
@@ -513,7 +467,7 @@ func (c *DriveMoveCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"file": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"file": updated})
 	}
 
 	u.Out().Printf("id\t%s", updated.Id)
@@ -556,7 +510,7 @@ func (c *DriveRenameCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"file": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"file": updated})
 	}
 
 	u.Out().Printf("id\t%s", updated.Id)
@@ -624,7 +578,7 @@ func (c *DriveShareCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"link":         link,
 			"permissionId": created.Id,
 			"permission":   created,
@@ -670,7 +624,7 @@ func (c *DriveUnshareCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"removed":      true,
 			"fileId":       fileID,
 			"permissionId": permissionID,
@@ -685,12 +639,12 @@ func (c *DriveUnshareCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 type DrivePermissionsCmd struct {
 	FileID string `arg:"" name:"fileId" help:"File ID"`
-	Max    int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Max    int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"100"`
 	Page   string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *DrivePermissionsCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -705,46 +659,38 @@ func (c *DrivePermissionsCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	call := svc.Permissions.List(fileID).
-		SupportsAllDrives(true).
-		Fields("nextPageToken, permissions(id, type, role, emailAddress)").
-		Context(ctx)
-	if c.Max > 0 {
-		call = call.PageSize(c.Max)
-	}
-	if strings.TrimSpace(c.Page) != "" {
-		call = call.PageToken(c.Page)
-	}
-
-	resp, err := call.Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"fileId":          fileID,
-			"permissions":     resp.Permissions,
-			"permissionCount": len(resp.Permissions),
-			"nextPageToken":   resp.NextPageToken,
-		})
-	}
-	if len(resp.Permissions) == 0 {
-		u.Err().Println("No permissions")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tTYPE\tROLE\tEMAIL")
-	for _, p := range resp.Permissions {
-		email := p.EmailAddress
-		if email == "" {
-			email = "-"
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]*drive.Permission, string, error) {
+		call := svc.Permissions.List(fileID).
+			SupportsAllDrives(true).
+			Fields("nextPageToken, permissions(id, type, role, emailAddress)").
+			Context(ctx)
+		if pageSize > 0 {
+			call = call.PageSize(pageSize)
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Id, p.Type, p.Role, email)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Permissions, resp.NextPageToken, nil
+	}
+
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[*drive.Permission]{
+		Key:      "permissions",
+		Meta:     map[string]any{"fileId": fileID},
+		CountKey: "permissionCount",
+		Empty:    "No permissions",
+		Header:   "ID\tTYPE\tROLE\tEMAIL",
+		Row: func(p *drive.Permission) string {
+			email := p.EmailAddress
+			if email == "" {
+				email = "-"
+			}
+			return fmt.Sprintf("%s\t%s\t%s\t%s", p.Id, p.Type, p.Role, email)
+		},
+	}, fetch)
 }
 
 type DriveURLCmd struct {
@@ -783,7 +729,7 @@ func (c *DriveURLCmd) Run(ctx context.Context, flags *RootFlags) error {
 			}
 			urls = append(urls, map[string]string{"id": id, "url": link})
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"urls": urls})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"urls": urls})
 	}
 	return nil
 }
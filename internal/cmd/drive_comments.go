@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
 
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
@@ -24,13 +25,13 @@ type DriveCommentsCmd struct {
 
 type DriveCommentsListCmd struct {
 	FileID        string `arg:"" name:"fileId" help:"File ID"`
-	Max           int64  `name:"max" help:"Max results" default:"100"`
+	Max           int64  `name:"max" help:"Max results (per page with --all)" default:"100"`
 	Page          string `name:"page" help:"Page token"`
 	IncludeQuoted bool   `name:"include-quoted" help:"Include the quoted content the comment is anchored to"`
+	PagingFlags
 }
 
 func (c *DriveCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -45,72 +46,57 @@ func (c *DriveCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error
 		return err
 	}
 
-	var call *drive.CommentsListCall
+	commentFields := "comments(id,author,content,createdTime,modifiedTime,resolved,replies)"
+	header := "ID\tAUTHOR\tCONTENT\tCREATED\tRESOLVED\tREPLIES"
 	if c.IncludeQuoted {
-		call = svc.Comments.List(fileID).
-			IncludeDeleted(false).
-			PageSize(c.Max).
-			Fields("nextPageToken", "comments(id,author,content,createdTime,modifiedTime,resolved,quotedFileContent,replies)").
-			Context(ctx)
-	} else {
-		call = svc.Comments.List(fileID).
-			IncludeDeleted(false).
-			PageSize(c.Max).
-			Fields("nextPageToken", "comments(id,author,content,createdTime,modifiedTime,resolved,replies)").
-			Context(ctx)
-	}
-	if strings.TrimSpace(c.Page) != "" {
-		call = call.PageToken(c.Page)
-	}
-
-	resp, err := call.Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"fileId":        fileID,
-			"comments":      resp.Comments,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Comments) == 0 {
-		u.Err().Println("No comments")
-		return nil
+		commentFields = "comments(id,author,content,createdTime,modifiedTime,resolved,quotedFileContent,replies)"
+		header = "ID\tAUTHOR\tQUOTED\tCONTENT\tCREATED\tRESOLVED\tREPLIES"
 	}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	if c.IncludeQuoted {
-		fmt.Fprintln(w, "ID\tAUTHOR\tQUOTED\tCONTENT\tCREATED\tRESOLVED\tREPLIES")
-	} else {
-		fmt.Fprintln(w, "ID\tAUTHOR\tCONTENT\tCREATED\tRESOLVED\tREPLIES")
-	}
-	for _, comment := range resp.Comments {
-		author := ""
-		if comment.Author != nil {
-			author = comment.Author.DisplayName
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]*drive.Comment, string, error) {
+		call := svc.Comments.List(fileID).
+			IncludeDeleted(false).
+			PageSize(pageSize).
+			Fields("nextPageToken", gapi.Field(commentFields)).
+			Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
 		}
-		content := truncateString(comment.Content, 50)
-		replyCount := len(comment.Replies)
-		if c.IncludeQuoted {
-			quoted := ""
-			if comment.QuotedFileContent != nil {
-				quoted = truncateString(comment.QuotedFileContent.Value, 30)
+		return resp.Comments, resp.NextPageToken, nil
+	}
+
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[*drive.Comment]{
+		Key:    "comments",
+		Meta:   map[string]any{"fileId": fileID},
+		Empty:  "No comments",
+		Header: header,
+		Row: func(comment *drive.Comment) string {
+			author := ""
+			if comment.Author != nil {
+				author = comment.Author.DisplayName
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%d\n",
-				comment.Id,
-				author,
-				quoted,
-				content,
-				formatDateTime(comment.CreatedTime),
-				comment.Resolved,
-				replyCount,
-			)
-		} else {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%d\n",
+			content := truncateString(comment.Content, 50)
+			replyCount := len(comment.Replies)
+			if c.IncludeQuoted {
+				quoted := ""
+				if comment.QuotedFileContent != nil {
+					quoted = truncateString(comment.QuotedFileContent.Value, 30)
+				}
+				return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%t\t%d",
+					comment.Id,
+					author,
+					quoted,
+					content,
+					formatDateTime(comment.CreatedTime),
+					comment.Resolved,
+					replyCount,
+				)
+			}
+			return fmt.Sprintf("%s\t%s\t%s\t%s\t%t\t%d",
 				comment.Id,
 				author,
 				content,
@@ -118,10 +104,8 @@ func (c *DriveCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error
 				comment.Resolved,
 				replyCount,
 			)
-		}
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+		},
+	}, fetch)
 }
 
 type DriveCommentsGetCmd struct {
@@ -158,7 +142,7 @@ func (c *DriveCommentsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"comment": comment})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"comment": comment})
 	}
 
 	u.Out().Printf("id\t%s", comment.Id)
@@ -224,7 +208,7 @@ func (c *DriveCommentsCreateCmd) Run(ctx context.Context, flags *RootFlags) erro
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"comment": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"comment": created})
 	}
 
 	u.Out().Printf("id\t%s", created.Id)
@@ -276,7 +260,7 @@ func (c *DriveCommentsUpdateCmd) Run(ctx context.Context, flags *RootFlags) erro
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"comment": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"comment": updated})
 	}
 
 	u.Out().Printf("id\t%s", updated.Id)
@@ -319,7 +303,7 @@ func (c *DriveCommentsDeleteCmd) Run(ctx context.Context, flags *RootFlags) erro
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"deleted":   true,
 			"fileId":    fileID,
 			"commentId": commentID,
@@ -375,7 +359,7 @@ func (c *DriveCommentReplyCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"reply": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"reply": created})
 	}
 
 	u.Out().Printf("id\t%s", created.Id)
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+func withTempConfigHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	t.Setenv("GOG_PROFILE", "")
+	t.Setenv("GOG_ACCOUNT", "")
+}
+
+func TestExecute_ConfigSetGetList(t *testing.T) {
+	withTempConfigHome(t)
+
+	_ = captureStdout(t, func() {
+		for _, args := range [][]string{
+			{"--profile", "work", "config", "set", "account", "work@example.com"},
+			{"--profile", "work", "config", "set", "output", "json"},
+			{"config", "set", "keyring_backend", "file"},
+		} {
+			if err := Execute(args); err != nil {
+				t.Fatalf("Execute %v: %v", args, err)
+			}
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--plain", "--profile=work", "config", "get", "account"}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	})
+	if strings.TrimSpace(out) != "work@example.com" {
+		t.Fatalf("unexpected get output: %q", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "config", "list"}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	})
+	var parsed struct {
+		KeyringBackend string                    `json:"keyring_backend"`
+		Profiles       map[string]config.Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.KeyringBackend != "file" || parsed.Profiles["work"].Account != "work@example.com" || parsed.Profiles["work"].Output != "json" {
+		t.Fatalf("unexpected list: %#v", parsed)
+	}
+
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"config", "path"}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	})
+	if !strings.HasSuffix(strings.TrimSpace(out), filepath.Join("gogcli", "config.json")) {
+		t.Fatalf("unexpected path: %q", out)
+	}
+}
+
+func TestExecute_ConfigSet_Invalid(t *testing.T) {
+	withTempConfigHome(t)
+
+	_ = captureStderr(t, func() {
+		for _, args := range [][]string{
+			{"config", "set", "account", "x@example.com"},
+			{"--profile", "work", "config", "set", "color", "sometimes"},
+			{"config", "set", "keyring_backend", "vault"},
+			{"--profile", "nope", "config", "get", "account"},
+		} {
+			if err := Execute(args); err == nil || ExitCode(err) != 2 {
+				t.Fatalf("%v: expected usage error, got %v", args, err)
+			}
+		}
+	})
+}
+
+func TestExecute_ProfileDefaults(t *testing.T) {
+	withTempConfigHome(t)
+
+	if err := config.WriteConfig(config.File{Profiles: map[string]config.Profile{
+		"work": {Account: "work@example.com", Output: "json", DriveParent: "folder1"},
+	}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	var gotQuery, gotAccount string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": []map[string]any{{"id": "f1", "name": "One"}}})
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := drive.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newDriveService = func(_ context.Context, account string) (*drive.Service, error) {
+		gotAccount = account
+		return svc, nil
+	}
+
+	t.Setenv("GOG_PROFILE", "work")
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"drive", "ls"}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	})
+	if gotAccount != "work@example.com" || !strings.Contains(gotQuery, "'folder1' in parents") {
+		t.Fatalf("profile defaults not applied: account=%q q=%q", gotAccount, gotQuery)
+	}
+	if !strings.Contains(out, `"files"`) {
+		t.Fatalf("expected profile json output, got %q", out)
+	}
+
+	// Flags win over the profile.
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"--plain", "--account", "other@example.com", "drive", "ls", "--parent", "folder2"}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	})
+	if gotAccount != "other@example.com" || !strings.Contains(gotQuery, "'folder2' in parents") || !strings.HasPrefix(out, "ID\t") {
+		t.Fatalf("flags should override profile: account=%q q=%q out=%q", gotAccount, gotQuery, out)
+	}
+
+	// Unknown profiles are a usage error for non-config commands.
+	_ = captureStderr(t, func() {
+		if err := Execute([]string{"--profile", "nope", "drive", "ls"}); err == nil || ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+	})
+}
+
+func TestExecute_AuthAlias(t *testing.T) {
+	withTempConfigHome(t)
+	withSecretsStore(t, storeWithTokens(t, "me@corp.com"))
+
+	_ = captureStderr(t, func() {
+		if err := Execute([]string{"auth", "alias", "set", "work", "other@corp.com"}); err == nil {
+			t.Fatalf("expected error for unknown account")
+		}
+		if err := Execute([]string{"auth", "alias", "set", "a@b", "me@corp.com"}); err == nil {
+			t.Fatalf("expected error for alias with @")
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"auth", "alias", "set", "work", "me@corp.com"}); err != nil {
+			t.Fatalf("alias set: %v", err)
+		}
+		if err := Execute([]string{"--plain", "auth", "alias", "list"}); err != nil {
+			t.Fatalf("alias list: %v", err)
+		}
+	})
+	if !strings.Contains(out, "work\tme@corp.com") {
+		t.Fatalf("unexpected list output: %q", out)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"auth", "alias", "unset", "work"}); err != nil {
+			t.Fatalf("alias unset: %v", err)
+		}
+	})
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if len(cfg.AccountAliases) != 0 {
+		t.Fatalf("expected aliases removed, got %v", cfg.AccountAliases)
+	}
+}
+
+func TestExecute_CacheStatsClear(t *testing.T) {
+	withTempConfigHome(t)
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "cache", "stats"}); err != nil {
+			t.Fatalf("cache stats: %v", err)
+		}
+	})
+	var stats struct {
+		Dir     string `json:"dir"`
+		Entries int    `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if stats.Entries != 0 || !strings.HasSuffix(stats.Dir, filepath.Join("cache", "http")) {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"--plain", "cache", "clear"}); err != nil {
+			t.Fatalf("cache clear: %v", err)
+		}
+	})
+	if !strings.Contains(out, "removed\t0") {
+		t.Fatalf("unexpected clear output: %q", out)
+	}
+}
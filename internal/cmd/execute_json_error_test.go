@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func TestExecute_JSONError_NotFound(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"File not found: nope.","errors":[{"reason":"notFound","message":"File not found: nope."}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := drive.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newDriveService = func(context.Context, string) (*drive.Service, error) { return svc, nil }
+
+	var execErr error
+	errOut := captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			execErr = Execute([]string{"--json", "--account", "a@b.com", "drive", "get", "nope"})
+		})
+	})
+	if ExitCode(execErr) != 5 {
+		t.Fatalf("expected exit 5 (not_found), got %d (%v)", ExitCode(execErr), execErr)
+	}
+
+	var parsed struct {
+		Error struct {
+			Code     string `json:"code"`
+			Status   int    `json:"status"`
+			Reason   string `json:"reason"`
+			Message  string `json:"message"`
+			ExitCode int    `json:"exit_code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(errOut), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nstderr=%q", err, errOut)
+	}
+	if parsed.Error.Code != "not_found" || parsed.Error.Status != 404 || parsed.Error.Reason != "notFound" || parsed.Error.ExitCode != 5 {
+		t.Fatalf("unexpected error object: %#v", parsed.Error)
+	}
+}
+
+func TestExecute_JSONError_Usage(t *testing.T) {
+	var execErr error
+	errOut := captureStderr(t, func() {
+		execErr = Execute([]string{"--json", "drive", "ls", "--no-such-flag"})
+	})
+	if ExitCode(execErr) != 2 {
+		t.Fatalf("expected exit 2, got %d", ExitCode(execErr))
+	}
+	if !strings.Contains(errOut, `"code":"usage"`) {
+		t.Fatalf("expected usage JSON error, got %q", errOut)
+	}
+}
+
+func TestExecute_JSONError_InvalidJQ(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--jq", ".[", "version"}, "invalid --jq"},
+		{[]string{"--json", "--jq", ".[", "version"}, "invalid --jq"},
+		{[]string{"--jq", ".", "--plain", "version"}, "cannot combine --jq and --plain"},
+		{[]string{"--jq", ".", "--output", "csv", "version"}, "cannot combine --jq and --output csv"},
+	}
+	for _, tc := range cases {
+		var execErr error
+		var out string
+		errOut := captureStderr(t, func() {
+			out = captureStdout(t, func() {
+				execErr = Execute(tc.args)
+			})
+		})
+		if ExitCode(execErr) != 2 {
+			t.Fatalf("%v: expected exit 2, got %d (%v)", tc.args, ExitCode(execErr), execErr)
+		}
+		if out != "" {
+			t.Fatalf("%v: unexpected stdout %q", tc.args, out)
+		}
+
+		var parsed struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(errOut), &parsed); err != nil {
+			t.Fatalf("%v: json parse: %v\nstderr=%q", tc.args, err, errOut)
+		}
+		if parsed.Error.Code != "usage" || !strings.Contains(parsed.Error.Message, tc.want) {
+			t.Fatalf("%v: unexpected error object: %#v", tc.args, parsed.Error)
+		}
+	}
+}
+
+func TestExecute_InvalidOutputMode(t *testing.T) {
+	var execErr error
+	errOut := captureStderr(t, func() {
+		execErr = Execute([]string{"--output", "foo", "version"})
+	})
+	if ExitCode(execErr) != 2 {
+		t.Fatalf("expected exit 2, got %d (%v)", ExitCode(execErr), execErr)
+	}
+	if !strings.Contains(errOut, "table|tsv|csv|markdown|json") {
+		t.Fatalf("expected allowed values on stderr, got %q", errOut)
+	}
+
+	errOut = captureStderr(t, func() {
+		execErr = Execute([]string{"--ndjson", "--plain", "version"})
+	})
+	if ExitCode(execErr) != 2 {
+		t.Fatalf("expected exit 2, got %d (%v)", ExitCode(execErr), execErr)
+	}
+	if !strings.Contains(errOut, `"code":"usage"`) || !strings.Contains(errOut, "cannot combine --ndjson and --plain") {
+		t.Fatalf("expected usage JSON error, got %q", errOut)
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+	"google.golang.org/api/tasks/v1"
+)
+
+func newPagedDriveService(t *testing.T) {
+	t.Helper()
+
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/files") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"files":         []map[string]any{{"id": "f1", "name": "One"}, {"id": "f2", "name": "Two"}},
+				"nextPageToken": "p2",
+			})
+		case "p2":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"files": []map[string]any{{"id": "f3", "name": "Three"}},
+			})
+		default:
+			http.Error(w, "bad page", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := drive.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newDriveService = func(context.Context, string) (*drive.Service, error) { return svc, nil }
+}
+
+func TestExecute_DriveLs_NDJSON_All(t *testing.T) {
+	newPagedDriveService(t)
+
+	var errOut string
+	out := captureStdout(t, func() {
+		errOut = captureStderr(t, func() {
+			if err := Execute([]string{"--ndjson", "--account", "a@b.com", "drive", "ls", "--all"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", out)
+	}
+	for i, want := range []string{"f1", "f2", "f3"} {
+		var f struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &f); err != nil {
+			t.Fatalf("line %d: %v (%q)", i, err, lines[i])
+		}
+		if f.ID != want {
+			t.Fatalf("line %d: got %q want %q", i, f.ID, want)
+		}
+	}
+	if strings.Contains(errOut, "Next page") {
+		t.Fatalf("unexpected next page hint: %q", errOut)
+	}
+}
+
+func TestExecute_DriveLs_NDJSON_SinglePageHint(t *testing.T) {
+	newPagedDriveService(t)
+
+	var errOut string
+	out := captureStdout(t, func() {
+		errOut = captureStderr(t, func() {
+			if err := Execute([]string{"--ndjson", "--fields", "id", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if out != "{\"id\":\"f1\"}\n{\"id\":\"f2\"}\n" {
+		t.Fatalf("unexpected out=%q", out)
+	}
+	if !strings.Contains(errOut, "# Next page: --page p2") {
+		t.Fatalf("expected next page hint, got %q", errOut)
+	}
+}
+
+func TestExecute_DriveLs_JSON_All(t *testing.T) {
+	newPagedDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "ls", "--all"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	var parsed struct {
+		Files         []map[string]any `json:"files"`
+		NextPageToken string           `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Files) != 3 || parsed.NextPageToken != "" {
+		t.Fatalf("unexpected payload: %#v", parsed)
+	}
+}
+
+func TestExecute_TasksList_NDJSON_All(t *testing.T) {
+	origNew := newTasksService
+	t.Cleanup(func() { newTasksService = origNew })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !(r.URL.Path == "/tasks/v1/lists/l1/tasks" && r.Method == http.MethodGet) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"items":         []map[string]any{{"id": "t1", "title": "One"}},
+				"nextPageToken": "p2",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items": []map[string]any{{"id": "t2", "title": "Two"}},
+		})
+	}))
+	defer srv.Close()
+
+	svc, err := tasks.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newTasksService = func(context.Context, string) (*tasks.Service, error) { return svc, nil }
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--ndjson", "--jq", ".id", "--account", "a@b.com", "tasks", "list", "l1", "--all"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if out != "t1\nt2\n" {
+		t.Fatalf("unexpected out=%q", out)
+	}
+}
+
+func TestExecute_DriveLs_MaxTotal(t *testing.T) {
+	newPagedDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "ls", "--max", "2", "--max-total", "1"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	var parsed struct {
+		Files         []map[string]any `json:"files"`
+		NextPageToken string           `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Files) != 1 || parsed.Files[0]["id"] != "f1" || parsed.NextPageToken != "p2" {
+		t.Fatalf("unexpected payload: %#v", parsed)
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func newFieldsTestDriveService(t *testing.T) {
+	t.Helper()
+
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/files") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"files": []map[string]any{
+				{"id": "f1", "name": "One", "mimeType": "application/pdf", "size": "10", "modifiedTime": "2025-12-12T14:37:47Z"},
+				{"id": "f2", "name": "Two", "mimeType": "text/plain", "size": "20", "modifiedTime": "2025-12-13T14:37:47Z"},
+			},
+			"nextPageToken": "npt",
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := drive.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newDriveService = func(context.Context, string) (*drive.Service, error) { return svc, nil }
+}
+
+func TestExecute_Fields_JSON(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--fields", "id,mimeType", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	var parsed struct {
+		Files         []map[string]any `json:"files"`
+		NextPageToken string           `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.NextPageToken != "npt" || len(parsed.Files) != 2 {
+		t.Fatalf("unexpected output: %#v", parsed)
+	}
+	for _, f := range parsed.Files {
+		if len(f) != 2 || f["id"] == nil || f["mimeType"] == nil {
+			t.Fatalf("unexpected projected file: %#v", f)
+		}
+	}
+}
+
+func TestExecute_Fields_Plain(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--plain", "--fields", "name,id", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if out != "NAME\tID\nOne\tf1\nTwo\tf2\n" {
+		t.Fatalf("unexpected out=%q", out)
+	}
+}
+
+func TestExecute_JQ(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--jq", `.files[] | select(.mimeType == "text/plain") | .name`, "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if out != "Two\n" {
+		t.Fatalf("unexpected out=%q", out)
+	}
+}
+
+func TestExecute_JQ_InvalidAndPlain(t *testing.T) {
+	_ = captureStderr(t, func() {
+		err := Execute([]string{"--jq", ".[", "--account", "a@b.com", "drive", "ls"})
+		if err == nil || ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+
+		err = Execute([]string{"--plain", "--jq", ".files", "--account", "a@b.com", "drive", "ls"})
+		if err == nil || ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+	})
+}
+
+func TestExecute_Output_CSV(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--output", "csv", "--fields", "id,name", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if out != "ID,NAME\nf1,One\nf2,Two\n" {
+		t.Fatalf("unexpected out=%q", out)
+	}
+}
+
+func TestExecute_Output_Markdown(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--output", "markdown", "--fields", "name,type", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	want := "| NAME | TYPE |\n| --- | --- |\n| One | file |\n| Two | file |\n"
+	if out != want {
+		t.Fatalf("unexpected out=%q", out)
+	}
+}
+
+func TestExecute_Output_JSONAndInvalid(t *testing.T) {
+	newFieldsTestDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--output", "json", "--account", "a@b.com", "drive", "ls"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(out, `"files"`) {
+		t.Fatalf("expected json output, got %q", out)
+	}
+
+	_ = captureStderr(t, func() {
+		for _, args := range [][]string{
+			{"--output", "xml", "--account", "a@b.com", "drive", "ls"},
+			{"--json", "--output", "csv", "--account", "a@b.com", "drive", "ls"},
+			{"--output", "csv", "--jq", ".files", "--account", "a@b.com", "drive", "ls"},
+		} {
+			if err := Execute(args); err == nil || ExitCode(err) != 2 {
+				t.Fatalf("%v: expected usage error, got %v", args, err)
+			}
+		}
+	})
+}
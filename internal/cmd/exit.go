@@ -1,6 +1,15 @@
 package cmd
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/errfmt"
+	"github.com/steipete/gogcli/internal/outfmt"
+)
 
 type ExitError struct {
 	Code int
@@ -21,16 +30,50 @@ func (e *ExitError) Unwrap() error {
 	return e.Err
 }
 
+// ExitCode maps err to the process exit code: explicit ExitError codes
+// (usage 2, interrupted 130) first, then the errfmt error class.
 func ExitCode(err error) int {
 	if err == nil {
 		return 0
 	}
+	return errorInfo(err).ExitCode
+}
+
+// errorInfo classifies err for exit codes and --json error output.
+func errorInfo(err error) errfmt.Info {
+	info := errfmt.Classify(err)
 	var ee *ExitError
-	if errors.As(err, &ee) && ee != nil {
-		if ee.Code < 0 {
-			return 1
+	if errors.As(err, &ee) && ee != nil && ee.Code > 1 {
+		info.Code = errfmt.CodeForExit(ee.Code)
+		info.ExitCode = ee.Code
+	}
+	return info
+}
+
+// writeJSONError writes {"error": {...}} (see errfmt.Info) to w.
+func writeJSONError(w io.Writer, err error) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if encErr := enc.Encode(map[string]any{"error": errorInfo(err)}); encErr != nil {
+		_, _ = fmt.Fprintln(w, errfmt.Format(err))
+	}
+}
+
+// jsonRequested reports whether args/env ask for JSON output; used for errors
+// raised before flags are parsed.
+func jsonRequested(args []string, env outfmt.Mode) bool {
+	if env.JSON || env.NDJSON {
+		return true
+	}
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return false
+		case arg == "--json", arg == "--ndjson", arg == "--jq", strings.HasPrefix(arg, "--jq="):
+			return true
+		case arg == "--output=json", arg == "--output" && i+1 < len(args) && args[i+1] == "json":
+			return true
 		}
-		return ee.Code
 	}
-	return 1
+	return false
 }
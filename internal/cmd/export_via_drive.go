@@ -80,7 +80,7 @@ func exportViaDrive(ctx context.Context, flags *RootFlags, opts exportViaDriveOp
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"path": downloadedPath, "size": size})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": downloadedPath, "size": size})
 	}
 	u.Out().Printf("path\t%s", downloadedPath)
 	u.Out().Printf("size\t%s", formatDriveSize(size))
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// fanoutCommands lists the commands that accept --accounts/--all-accounts.
+const fanoutCommands = "gmail search, calendar events, tasks list, drive search, gmail watch serve"
+
+// fanoutAccounts returns the accounts selected by --accounts/--all-accounts,
+// or nil when neither is set (single-account mode).
+func fanoutAccounts(flags *RootFlags) ([]string, error) {
+	list := strings.TrimSpace(flags.Accounts)
+	if list == "" && !flags.AllAccounts {
+		return nil, nil
+	}
+	if list != "" && flags.AllAccounts {
+		return nil, usage("cannot combine --accounts and --all-accounts")
+	}
+
+	if flags.AllAccounts {
+		store, err := openSecretsStore()
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := store.ListTokens()
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			return nil, usage("no stored accounts (run: gog auth add <email>)")
+		}
+		accounts := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			accounts = append(accounts, t.Email)
+		}
+		sort.Strings(accounts)
+		return accounts, nil
+	}
+
+	seen := map[string]bool{}
+	var accounts []string
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		account, err := resolveAccount(part)
+		if err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(account); !seen[key] {
+			seen[key] = true
+			accounts = append(accounts, account)
+		}
+	}
+	if len(accounts) == 0 {
+		return nil, usage("empty --accounts")
+	}
+	return accounts, nil
+}
+
+// accountFetcher builds the page fetcher for one account (service setup etc.).
+type accountFetcher[T any] func(ctx context.Context, account string) (pageFetcher[T], error)
+
+// runList is the entry point for list commands that support fan-out: it runs
+// writeList for --account, or writeFanout for --accounts/--all-accounts.
+func runList[T any](ctx context.Context, flags *RootFlags, opts pageOptions, out listOutput[T], newFetch accountFetcher[T]) error {
+	accounts, err := fanoutAccounts(flags)
+	if err != nil {
+		return err
+	}
+	if accounts != nil {
+		if opts.Page != "" {
+			return usage("--page cannot be combined with --accounts/--all-accounts")
+		}
+		return writeFanout(ctx, accounts, opts, out, newFetch)
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fetch, err := newFetch(ctx, account)
+	if err != nil {
+		return err
+	}
+	return writeList(ctx, opts, out, fetch)
+}
+
+type accountResult[T any] struct {
+	items []T
+	next  string
+	err   error
+}
+
+// writeFanout runs the list for every account concurrently and writes the
+// merged results, each tagged with its account (JSON "account" field, table
+// ACCOUNT column). Results keep the account order. A failing account is
+// reported on stderr (and in JSON "errors") without stopping the others; the
+// command then exits 1.
+func writeFanout[T any](ctx context.Context, accounts []string, opts pageOptions, out listOutput[T], newFetch accountFetcher[T]) error {
+	u := ui.FromContext(ctx)
+
+	fetchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	const maxConcurrency = 4 // accounts fetched in parallel
+	sem := make(chan struct{}, maxConcurrency)
+
+	ndjson := outfmt.IsNDJSON(ctx)
+	var writeMu sync.Mutex
+	var writeErr error
+
+	results := make([]accountResult[T], len(accounts))
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(idx int, account string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-fetchCtx.Done():
+				results[idx].err = fetchCtx.Err()
+				return
+			}
+
+			fetch, err := newFetch(fetchCtx, account)
+			if err != nil {
+				results[idx].err = err
+				return
+			}
+			res := &results[idx]
+			res.next, res.err = fetchPages(fetchCtx, opts, fetch, func(items []T) error {
+				if !ndjson {
+					res.items = append(res.items, items...)
+					return nil
+				}
+				writeMu.Lock()
+				defer writeMu.Unlock()
+				for _, it := range items {
+					v, err := withAccount(account, it)
+					if err != nil {
+						return err
+					}
+					if err := outfmt.WriteJSON(ctx, os.Stdout, v); err != nil {
+						writeErr = err
+						return err
+					}
+				}
+				return nil
+			})
+		}(i, account)
+	}
+	wg.Wait()
+	if writeErr != nil {
+		return writeErr
+	}
+
+	type accountError struct {
+		Account string `json:"account"`
+		Error   string `json:"error"`
+	}
+	var failed []accountError
+	nextTokens := map[string]string{}
+	count := 0
+	for i, account := range accounts {
+		res := results[i]
+		count += len(res.items)
+		if res.next != "" {
+			nextTokens[account] = res.next
+		}
+		if res.err != nil && fetchCtx.Err() == nil {
+			failed = append(failed, accountError{Account: account, Error: res.err.Error()})
+			u.Err().Printf("%s: %v", account, res.err)
+		}
+	}
+	interrupted := fetchCtx.Err() != nil
+	if interrupted && !ndjson {
+		u.Err().Printf("Interrupted; writing %d partial results", count)
+	}
+
+	result := func() error {
+		if interrupted {
+			return errInterrupted
+		}
+		if len(failed) > 0 {
+			return &ExitError{Code: 1, Err: fmt.Errorf("%d of %d accounts failed", len(failed), len(accounts))}
+		}
+		return nil
+	}
+
+	if ndjson {
+		return result()
+	}
+
+	if outfmt.IsJSON(ctx) {
+		items := make([]any, 0, count)
+		for i, account := range accounts {
+			for _, it := range results[i].items {
+				v, err := withAccount(account, it)
+				if err != nil {
+					return err
+				}
+				items = append(items, v)
+			}
+		}
+		envelope := map[string]any{
+			out.Key:          items,
+			"accounts":       accounts,
+			"nextPageTokens": nextTokens,
+		}
+		for k, v := range out.Meta {
+			envelope[k] = v
+		}
+		if out.CountKey != "" {
+			envelope[out.CountKey] = count
+		}
+		if len(failed) > 0 {
+			envelope["errors"] = failed
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, envelope); err != nil {
+			return err
+		}
+		return result()
+	}
+
+	if count == 0 {
+		if len(failed) < len(accounts) {
+			u.Err().Println(out.Empty)
+		}
+		return result()
+	}
+
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "ACCOUNT\t"+out.Header)
+	for i, account := range accounts {
+		for _, it := range results[i].items {
+			fmt.Fprintln(w, account+"\t"+out.Row(it))
+		}
+	}
+	flush()
+	return result()
+}
+
+// withAccount converts an item to a JSON object with an added "account" field.
+func withAccount(account string, item any) (map[string]any, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("encode item: %w", err)
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("decode item: %w", err)
+	}
+	m["account"] = account
+	return m, nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// newFanoutDriveService serves one file per account; broken@example.com fails.
+func newFanoutDriveService(t *testing.T) {
+	t.Helper()
+
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	newDriveService = func(ctx context.Context, account string) (*drive.Service, error) {
+		if account == "broken@example.com" {
+			return nil, errors.New("no token")
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"files": []map[string]any{{"id": "f-" + account, "name": "Report"}},
+			})
+		}))
+		t.Cleanup(srv.Close)
+		return drive.NewService(ctx,
+			option.WithoutAuthentication(),
+			option.WithHTTPClient(srv.Client()),
+			option.WithEndpoint(srv.URL+"/"),
+		)
+	}
+}
+
+func TestExecute_DriveSearch_Accounts_JSON(t *testing.T) {
+	newFanoutDriveService(t)
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--accounts", "a@example.com,b@example.com", "drive", "search", "report"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	var parsed struct {
+		Files []map[string]any `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Files) != 2 {
+		t.Fatalf("unexpected files: %#v", parsed.Files)
+	}
+	for i, account := range []string{"a@example.com", "b@example.com"} {
+		if parsed.Files[i]["account"] != account || parsed.Files[i]["id"] != "f-"+account {
+			t.Fatalf("unexpected file %d: %#v", i, parsed.Files[i])
+		}
+	}
+}
+
+func TestExecute_DriveSearch_AllAccounts_PartialFailure(t *testing.T) {
+	newFanoutDriveService(t)
+	withSecretsStore(t, storeWithTokens(t, "broken@example.com", "a@example.com"))
+
+	var errOut string
+	var execErr error
+	out := captureStdout(t, func() {
+		errOut = captureStderr(t, func() {
+			execErr = Execute([]string{"--plain", "--all-accounts", "drive", "search", "report"})
+		})
+	})
+	if ExitCode(execErr) != 1 {
+		t.Fatalf("expected exit 1, got %v", execErr)
+	}
+	if !strings.Contains(out, "ACCOUNT\tID\tNAME") || !strings.Contains(out, "a@example.com\tf-a@example.com\tReport") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if !strings.Contains(errOut, "broken@example.com: no token") {
+		t.Fatalf("expected per-account error, got %q", errOut)
+	}
+}
+
+func TestExecute_Accounts_UnsupportedCommand(t *testing.T) {
+	_ = captureStderr(t, func() {
+		err := Execute([]string{"--accounts", "a@example.com", "drive", "get", "f1"})
+		if ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+	})
+}
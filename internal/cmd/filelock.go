@@ -0,0 +1,19 @@
+package cmd
+
+import "os"
+
+// withFileLock runs fn while holding an exclusive lock on path (created if
+// missing), so it is serialized with other gog processes using the same
+// lock, e.g. `watch serve` and `watch deliveries retry`.
+func withFileLock(path string, fn func() error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // lock file under config dir
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return err
+	}
+	defer func() { _ = unlockFile(f) }()
+	return fn()
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/mail"
-	"os"
 	"strings"
 	"sync"
 	"time"
@@ -12,8 +11,6 @@ import (
 	"google.golang.org/api/gmail/v1"
 
 	"github.com/steipete/gogcli/internal/googleapi"
-	"github.com/steipete/gogcli/internal/outfmt"
-	"github.com/steipete/gogcli/internal/ui"
 )
 
 var newGmailService = googleapi.NewGmail
@@ -26,11 +23,15 @@ type GmailCmd struct {
 	URL         GmailURLCmd         `cmd:"" name:"url" help:"Print Gmail web URLs for threads"`
 	Labels      GmailLabelsCmd      `cmd:"" name:"labels" help:"Label operations"`
 	Send        GmailSendCmd        `cmd:"" name:"send" help:"Send an email"`
+	Reply       GmailReplyCmd       `cmd:"" name:"reply" help:"Reply to a message, quoting it"`
+	Forward     GmailForwardCmd     `cmd:"" name:"forward" help:"Forward a message with its attachments"`
 	Drafts      GmailDraftsCmd      `cmd:"" name:"drafts" help:"Draft operations"`
 	Watch       GmailWatchCmd       `cmd:"" name:"watch" help:"Manage Gmail watch"`
 	History     GmailHistoryCmd     `cmd:"" name:"history" help:"Gmail history"`
 	AutoForward GmailAutoForwardCmd `cmd:"" name:"autoforward" help:"Auto-forwarding settings"`
 	Batch       GmailBatchCmd       `cmd:"" name:"batch" help:"Batch operations"`
+	Export      GmailExportCmd      `cmd:"" name:"export" help:"Export messages to mbox, Maildir or .eml files (resumable)"`
+	Import      GmailImportCmd      `cmd:"" name:"import" help:"Import mbox, Maildir or .eml files into the mailbox (resumable)"`
 	Delegates   GmailDelegatesCmd   `cmd:"" name:"delegates" help:"Delegate operations"`
 	Filters     GmailFiltersCmd     `cmd:"" name:"filters" help:"Filter operations"`
 	Forwarding  GmailForwardingCmd  `cmd:"" name:"forwarding" help:"Forwarding addresses"`
@@ -40,68 +41,56 @@ type GmailCmd struct {
 
 type GmailSearchCmd struct {
 	Query []string `arg:"" name:"query" help:"Search query"`
-	Max   int64    `name:"max" aliases:"limit" help:"Max results" default:"10"`
+	Max   int64    `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"10"`
 	Page  string   `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *GmailSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
-	if err != nil {
-		return err
-	}
 	query := strings.TrimSpace(strings.Join(c.Query, " "))
 	if query == "" {
 		return usage("missing query")
 	}
 
-	svc, err := newGmailService(ctx, account)
-	if err != nil {
-		return err
-	}
-
-	resp, err := svc.Users.Threads.List("me").
-		Q(query).
-		MaxResults(c.Max).
-		PageToken(c.Page).
-		Context(ctx).
-		Do()
-	if err != nil {
-		return err
-	}
-
-	idToName, err := fetchLabelIDToName(svc)
-	if err != nil {
-		return err
-	}
-
-	// Fetch thread details concurrently (fixes N+1 query pattern)
-	items, err := fetchThreadDetails(ctx, svc, resp.Threads, idToName)
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"threads":       items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
+	newFetch := func(ctx context.Context, account string) (pageFetcher[threadItem], error) {
+		svc, err := newGmailService(ctx, account)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(items) == 0 {
-		u.Err().Println("No results")
-		return nil
-	}
+		idToName, err := fetchLabelIDToName(svc)
+		if err != nil {
+			return nil, err
+		}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
+		return func(ctx context.Context, pageToken string, pageSize int64) ([]threadItem, string, error) {
+			resp, err := svc.Users.Threads.List("me").
+				Q(query).
+				MaxResults(pageSize).
+				PageToken(pageToken).
+				Context(ctx).
+				Do()
+			if err != nil {
+				return nil, "", err
+			}
 
-	fmt.Fprintln(w, "ID\tDATE\tFROM\tSUBJECT\tLABELS")
-	for _, it := range items {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", it.ID, it.Date, it.From, it.Subject, strings.Join(it.Labels, ","))
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+			// Fetch thread details concurrently (fixes N+1 query pattern)
+			items, err := fetchThreadDetails(ctx, svc, resp.Threads, idToName)
+			if err != nil {
+				return nil, "", err
+			}
+			return items, resp.NextPageToken, nil
+		}, nil
+	}
+
+	return runList(ctx, flags, c.options(c.Page, c.Max), listOutput[threadItem]{
+		Key:    "threads",
+		Empty:  "No results",
+		Header: "ID\tDATE\tFROM\tSUBJECT\tLABELS",
+		Row: func(it threadItem) string {
+			return fmt.Sprintf("%s\t%s\t%s\t%s\t%s", it.ID, it.Date, it.From, it.Subject, strings.Join(it.Labels, ","))
+		},
+	}, newFetch)
 }
 
 func firstMessage(t *gmail.Thread) *gmail.Message {
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"google.golang.org/api/gmail/v1"
@@ -11,13 +12,231 @@ import (
 	"github.com/steipete/gogcli/internal/ui"
 )
 
+const (
+	// batchChunkSize is the most IDs BatchModify/BatchDelete accept per call.
+	batchChunkSize = 1000
+	// batchListPageSize is the Users.Messages.List page size used by --query.
+	batchListPageSize = 500
+	// batchConfirmThreshold is how many messages a batch may touch before it
+	// asks for confirmation (or --force).
+	batchConfirmThreshold = 100
+	// batchSampleSize is how many matches --dry-run shows.
+	batchSampleSize = 10
+)
+
 type GmailBatchCmd struct {
-	Delete GmailBatchDeleteCmd `cmd:"" name:"delete" help:"Permanently delete multiple messages"`
-	Modify GmailBatchModifyCmd `cmd:"" name:"modify" help:"Modify labels on multiple messages"`
+	Delete     GmailBatchDeleteCmd     `cmd:"" name:"delete" help:"Permanently delete multiple messages"`
+	Modify     GmailBatchModifyCmd     `cmd:"" name:"modify" help:"Modify labels on multiple messages"`
+	MarkRead   GmailBatchMarkReadCmd   `cmd:"" name:"mark-read" help:"Mark multiple messages as read"`
+	MarkUnread GmailBatchMarkUnreadCmd `cmd:"" name:"mark-unread" help:"Mark multiple messages as unread"`
+	Archive    GmailBatchArchiveCmd    `cmd:"" name:"archive" help:"Archive multiple messages (remove INBOX)"`
+	Trash      GmailBatchTrashCmd      `cmd:"" name:"trash" help:"Move multiple messages to trash"`
+	Label      GmailBatchLabelCmd      `cmd:"" name:"label" help:"Add labels to multiple messages"`
+	Unlabel    GmailBatchUnlabelCmd    `cmd:"" name:"unlabel" help:"Remove labels from multiple messages"`
+}
+
+// GmailBatchSelector picks the messages a batch command acts on: explicit
+// message IDs, or every message matching --query.
+type GmailBatchSelector struct {
+	MessageIDs       []string `arg:"" optional:"" name:"messageId" help:"Message IDs (or use --query)"`
+	Query            string   `name:"query" help:"Gmail search query selecting the messages"`
+	Max              int64    `name:"max" help:"Stop after this many matches with --query (0 = no limit)"`
+	IncludeSpamTrash bool     `name:"include-spam-trash" help:"Include spam and trash in --query matches"`
+	DryRun           bool     `name:"dry-run" help:"Show the match count and a sample without changing anything"`
+}
+
+// resolve returns the selected message IDs, paging through
+// Users.Messages.List for --query and reporting progress on stderr.
+func (s GmailBatchSelector) resolve(ctx context.Context, svc *gmail.Service) ([]string, error) {
+	hasIDs := len(s.MessageIDs) > 0
+	hasQuery := s.Query != ""
+	switch {
+	case hasIDs && hasQuery:
+		return nil, usage("use message IDs or --query, not both")
+	case !hasIDs && !hasQuery:
+		return nil, usage("missing message IDs (or --query)")
+	case hasIDs:
+		return s.MessageIDs, nil
+	}
+
+	u := ui.FromContext(ctx)
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]string, string, error) {
+		call := svc.Users.Messages.List("me").
+			Q(s.Query).
+			MaxResults(pageSize).
+			IncludeSpamTrash(s.IncludeSpamTrash).
+			Fields("messages(id),nextPageToken").
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		ids := make([]string, 0, len(resp.Messages))
+		for _, m := range resp.Messages {
+			if m != nil && m.Id != "" {
+				ids = append(ids, m.Id)
+			}
+		}
+		return ids, resp.NextPageToken, nil
+	}
+
+	var ids []string
+	opts := pageOptions{Max: batchListPageSize, All: true, MaxTotal: s.Max}
+	_, err := fetchPages(ctx, opts, fetch, func(page []string) error {
+		ids = append(ids, page...)
+		if u != nil {
+			u.Err().Printf("Matched %d messages...", len(ids))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// gmailBatchOp describes one batch verb.
+type gmailBatchOp struct {
+	verb   string   // e.g. "archive"; used in prompts and output
+	add    []string // label IDs to add
+	remove []string // label IDs to remove
+	delete bool     // permanent BatchDelete instead of BatchModify
+}
+
+// runGmailBatch resolves the selection, then applies op in chunks of
+// batchChunkSize. Large (or permanent --query) batches need confirmation.
+func runGmailBatch(ctx context.Context, flags *RootFlags, sel GmailBatchSelector, svc *gmail.Service, op gmailBatchOp) ([]string, error) {
+	u := ui.FromContext(ctx)
+
+	ids, err := sel.resolve(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	if sel.DryRun {
+		return nil, writeGmailBatchDryRun(ctx, svc, sel, op, ids)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	if len(ids) > batchConfirmThreshold || (op.delete && sel.Query != "") {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("%s %d messages", op.verb, len(ids))); err != nil {
+			return nil, err
+		}
+	}
+
+	chunks := chunkStrings(ids, batchChunkSize)
+	done := 0
+	for _, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if op.delete {
+			err = svc.Users.Messages.BatchDelete("me", &gmail.BatchDeleteMessagesRequest{
+				Ids: chunk,
+			}).Context(ctx).Do()
+		} else {
+			err = svc.Users.Messages.BatchModify("me", &gmail.BatchModifyMessagesRequest{
+				Ids:            chunk,
+				AddLabelIds:    op.add,
+				RemoveLabelIds: op.remove,
+			}).Context(ctx).Do()
+		}
+		if err != nil {
+			if done > 0 {
+				return nil, fmt.Errorf("%s: %d of %d messages done: %w", op.verb, done, len(ids), err)
+			}
+			return nil, err
+		}
+		done += len(chunk)
+		if len(chunks) > 1 && u != nil {
+			u.Err().Printf("%s: %d/%d", op.verb, done, len(ids))
+		}
+	}
+	return ids, nil
+}
+
+func writeGmailBatchDryRun(ctx context.Context, svc *gmail.Service, sel GmailBatchSelector, op gmailBatchOp, ids []string) error {
+	sampleIDs := ids
+	if len(sampleIDs) > batchSampleSize {
+		sampleIDs = sampleIDs[:batchSampleSize]
+	}
+
+	type sampleItem struct {
+		ID      string `json:"id"`
+		From    string `json:"from"`
+		Subject string `json:"subject"`
+		Date    string `json:"date"`
+	}
+	sample := make([]sampleItem, 0, len(sampleIDs))
+	for _, id := range sampleIDs {
+		msg, err := svc.Users.Messages.Get("me", id).
+			Format("metadata").
+			MetadataHeaders("From", "Subject", "Date").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		sample = append(sample, sampleItem{
+			ID:      id,
+			From:    headerValue(msg.Payload, "From"),
+			Subject: headerValue(msg.Payload, "Subject"),
+			Date:    formatGmailDate(headerValue(msg.Payload, "Date")),
+		})
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{
+			"dryRun": true,
+			"action": op.verb,
+			"count":  len(ids),
+			"sample": sample,
+		}
+		if sel.Query != "" {
+			out["query"] = sel.Query
+		}
+		if len(op.add) > 0 {
+			out["addedLabels"] = op.add
+		}
+		if len(op.remove) > 0 {
+			out["removedLabels"] = op.remove
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
+	}
+
+	u := ui.FromContext(ctx)
+	u.Err().Printf("Dry run: would %s %d messages", op.verb, len(ids))
+	if len(sample) == 0 {
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tDATE\tFROM\tSUBJECT")
+	for _, it := range sample {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.ID, it.Date, sanitizeTab(it.From), sanitizeTab(it.Subject))
+	}
+	return nil
+}
+
+func chunkStrings(items []string, size int) [][]string {
+	var chunks [][]string
+	for len(items) > size {
+		chunks = append(chunks, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
 }
 
 type GmailBatchDeleteCmd struct {
-	MessageIDs []string `arg:"" name:"messageId" help:"Message IDs"`
+	GmailBatchSelector
 }
 
 func (c *GmailBatchDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -32,42 +251,103 @@ func (c *GmailBatchDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	err = svc.Users.Messages.BatchDelete("me", &gmail.BatchDeleteMessagesRequest{
-		Ids: c.MessageIDs,
-	}).Do()
-	if err != nil {
+	ids, err := runGmailBatch(ctx, flags, c.GmailBatchSelector, svc, gmailBatchOp{verb: "delete", delete: true})
+	if err != nil || c.DryRun {
 		return err
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"deleted": c.MessageIDs,
-			"count":   len(c.MessageIDs),
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"deleted": ids,
+			"count":   len(ids),
 		})
 	}
 
-	u.Out().Printf("Deleted %d messages", len(c.MessageIDs))
+	u.Out().Printf("Deleted %d messages", len(ids))
 	return nil
 }
 
 type GmailBatchModifyCmd struct {
-	MessageIDs []string `arg:"" name:"messageId" help:"Message IDs"`
-	Add        string   `name:"add" help:"Labels to add (comma-separated, name or ID)"`
-	Remove     string   `name:"remove" help:"Labels to remove (comma-separated, name or ID)"`
+	GmailBatchSelector
+	Add    string `name:"add" aliases:"add-labels" help:"Labels to add (comma-separated, name or ID)"`
+	Remove string `name:"remove" aliases:"remove-labels" help:"Labels to remove (comma-separated, name or ID)"`
 }
 
 func (c *GmailBatchModifyCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
-	if err != nil {
-		return err
-	}
-
 	addLabels := splitCSV(c.Add)
 	removeLabels := splitCSV(c.Remove)
 	if len(addLabels) == 0 && len(removeLabels) == 0 {
 		return errors.New("must specify --add and/or --remove")
 	}
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "modify", addLabels, removeLabels)
+}
+
+type GmailBatchLabelCmd struct {
+	GmailBatchSelector
+	AddLabels string `name:"add-labels" aliases:"add" help:"Labels to add (comma-separated, name or ID)" required:""`
+}
+
+func (c *GmailBatchLabelCmd) Run(ctx context.Context, flags *RootFlags) error {
+	addLabels := splitCSV(c.AddLabels)
+	if len(addLabels) == 0 {
+		return usage("empty --add-labels")
+	}
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "label", addLabels, nil)
+}
+
+type GmailBatchUnlabelCmd struct {
+	GmailBatchSelector
+	RemoveLabels string `name:"remove-labels" aliases:"remove" help:"Labels to remove (comma-separated, name or ID)" required:""`
+}
+
+func (c *GmailBatchUnlabelCmd) Run(ctx context.Context, flags *RootFlags) error {
+	removeLabels := splitCSV(c.RemoveLabels)
+	if len(removeLabels) == 0 {
+		return usage("empty --remove-labels")
+	}
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "unlabel", nil, removeLabels)
+}
+
+type GmailBatchMarkReadCmd struct {
+	GmailBatchSelector
+}
+
+func (c *GmailBatchMarkReadCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "mark-read", nil, []string{"UNREAD"})
+}
+
+type GmailBatchMarkUnreadCmd struct {
+	GmailBatchSelector
+}
+
+func (c *GmailBatchMarkUnreadCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "mark-unread", []string{"UNREAD"}, nil)
+}
+
+type GmailBatchArchiveCmd struct {
+	GmailBatchSelector
+}
+
+func (c *GmailBatchArchiveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "archive", nil, []string{"INBOX"})
+}
+
+type GmailBatchTrashCmd struct {
+	GmailBatchSelector
+}
+
+func (c *GmailBatchTrashCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runGmailBatchLabels(ctx, flags, c.GmailBatchSelector, "trash", []string{"TRASH"}, nil)
+}
+
+// runGmailBatchLabels is the shared Run for the label-changing batch verbs.
+// Label names are resolved to IDs; system label IDs pass through unchanged.
+func runGmailBatchLabels(ctx context.Context, flags *RootFlags, sel GmailBatchSelector, verb string, addLabels, removeLabels []string) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
 
 	svc, err := newGmailService(ctx, account)
 	if err != nil {
@@ -82,24 +362,21 @@ func (c *GmailBatchModifyCmd) Run(ctx context.Context, flags *RootFlags) error {
 	addIDs := resolveLabelIDs(addLabels, idMap)
 	removeIDs := resolveLabelIDs(removeLabels, idMap)
 
-	err = svc.Users.Messages.BatchModify("me", &gmail.BatchModifyMessagesRequest{
-		Ids:            c.MessageIDs,
-		AddLabelIds:    addIDs,
-		RemoveLabelIds: removeIDs,
-	}).Do()
-	if err != nil {
+	ids, err := runGmailBatch(ctx, flags, sel, svc, gmailBatchOp{verb: verb, add: addIDs, remove: removeIDs})
+	if err != nil || sel.DryRun {
 		return err
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"modified":      c.MessageIDs,
-			"count":         len(c.MessageIDs),
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"action":        verb,
+			"modified":      ids,
+			"count":         len(ids),
 			"addedLabels":   addIDs,
 			"removedLabels": removeIDs,
 		})
 	}
 
-	u.Out().Printf("Modified %d messages", len(c.MessageIDs))
+	u.Out().Printf("Modified %d messages", len(ids))
 	return nil
 }
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// newBatchTestServer serves n matching messages in pages of the requested
+// size and records every batchModify/batchDelete request body.
+func newBatchTestServer(t *testing.T, n int) (*gmail.Service, *[]gmail.BatchModifyMessagesRequest, *[]string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var modifies []gmail.BatchModifyMessagesRequest
+	var queries []string
+
+	svc := stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"labels": []map[string]any{
+					{"id": "INBOX", "name": "INBOX", "type": "system"},
+					{"id": "Label_1", "name": "Boss", "type": "user"},
+				},
+			})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/users/me/messages"):
+			mu.Lock()
+			queries = append(queries, r.URL.Query().Get("q"))
+			mu.Unlock()
+			start := 0
+			if tok := r.URL.Query().Get("pageToken"); tok != "" {
+				_, _ = fmt.Sscanf(tok, "p%d", &start)
+			}
+			size := 100
+			_, _ = fmt.Sscanf(r.URL.Query().Get("maxResults"), "%d", &size)
+			end := min(start+size, n)
+			msgs := make([]map[string]any, 0, end-start)
+			for i := start; i < end; i++ {
+				msgs = append(msgs, map[string]any{"id": fmt.Sprintf("m%d", i)})
+			}
+			resp := map[string]any{"messages": msgs}
+			if end < n {
+				resp["nextPageToken"] = fmt.Sprintf("p%d", end)
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/users/me/messages/"):
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": id,
+				"payload": map[string]any{
+					"headers": []map[string]any{
+						{"name": "From", "value": "boss@example.com"},
+						{"name": "Subject", "value": "Subject " + id},
+					},
+				},
+			})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/messages/batchModify"):
+			var req gmail.BatchModifyMessagesRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			modifies = append(modifies, req)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return svc, &modifies, &queries
+}
+
+func TestGmailBatchArchive_QueryChunks(t *testing.T) {
+	_, modifies, queries := newBatchTestServer(t, 2300)
+
+	var stderr strings.Builder
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	out := captureStdout(t, func() {
+		ctx := testJSONContext(t, &stderr)
+		if err := runKong(t, &GmailBatchArchiveCmd{}, []string{"--query", "older_than:1y"}, ctx, flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	if len(*queries) != 5 || (*queries)[0] != "older_than:1y" {
+		t.Fatalf("unexpected list calls: %v", *queries)
+	}
+	if len(*modifies) != 3 {
+		t.Fatalf("expected 3 batchModify calls, got %d", len(*modifies))
+	}
+	sizes := []int{len((*modifies)[0].Ids), len((*modifies)[1].Ids), len((*modifies)[2].Ids)}
+	if sizes[0] != 1000 || sizes[1] != 1000 || sizes[2] != 300 {
+		t.Fatalf("unexpected chunk sizes: %v", sizes)
+	}
+	if rm := (*modifies)[0].RemoveLabelIds; len(rm) != 1 || rm[0] != "INBOX" {
+		t.Fatalf("unexpected removeLabelIds: %v", rm)
+	}
+	if !strings.Contains(stderr.String(), "archive: 2300/2300") {
+		t.Fatalf("missing progress on stderr: %q", stderr.String())
+	}
+
+	var parsed struct {
+		Action string `json:"action"`
+		Count  int    `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.Action != "archive" || parsed.Count != 2300 {
+		t.Fatalf("unexpected output: %+v", parsed)
+	}
+}
+
+func TestGmailBatchLabel_DryRun(t *testing.T) {
+	_, modifies, _ := newBatchTestServer(t, 25)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	out := captureStdout(t, func() {
+		ctx := testJSONContext(t, io.Discard)
+		if err := runKong(t, &GmailBatchLabelCmd{}, []string{"--query", "from:boss@example.com", "--add-labels", "Boss", "--dry-run"}, ctx, flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	if len(*modifies) != 0 {
+		t.Fatalf("dry run modified messages: %v", *modifies)
+	}
+
+	var parsed struct {
+		DryRun      bool     `json:"dryRun"`
+		Count       int      `json:"count"`
+		AddedLabels []string `json:"addedLabels"`
+		Sample      []struct {
+			ID      string `json:"id"`
+			Subject string `json:"subject"`
+		} `json:"sample"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if !parsed.DryRun || parsed.Count != 25 || len(parsed.Sample) != batchSampleSize {
+		t.Fatalf("unexpected dry run: %+v", parsed)
+	}
+	if parsed.Sample[0].ID != "m0" || parsed.Sample[0].Subject != "Subject m0" {
+		t.Fatalf("unexpected sample: %+v", parsed.Sample[0])
+	}
+	if len(parsed.AddedLabels) != 1 || parsed.AddedLabels[0] != "Label_1" {
+		t.Fatalf("unexpected addedLabels: %v", parsed.AddedLabels)
+	}
+}
+
+func TestGmailBatchMarkRead_ConfirmAboveThreshold(t *testing.T) {
+	_, modifies, _ := newBatchTestServer(t, batchConfirmThreshold+1)
+
+	flags := &RootFlags{Account: "a@b.com", NoInput: true}
+	ctx := testJSONContext(t, io.Discard)
+	err := runKong(t, &GmailBatchMarkReadCmd{}, []string{"--query", "is:unread"}, ctx, flags)
+	if err == nil || !strings.Contains(err.Error(), "without --force") {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+	if len(*modifies) != 0 {
+		t.Fatalf("modified without confirmation: %v", *modifies)
+	}
+}
+
+func TestGmailBatch_SelectorValidation(t *testing.T) {
+	newBatchTestServer(t, 1)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := testJSONContext(t, io.Discard)
+	if err := runKong(t, &GmailBatchTrashCmd{}, []string{}, ctx, flags); err == nil || !strings.Contains(err.Error(), "missing message IDs") {
+		t.Fatalf("expected missing selection error, got %v", err)
+	}
+	if err := runKong(t, &GmailBatchTrashCmd{}, []string{"m1", "--query", "x"}, ctx, flags); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+func TestChunkStrings(t *testing.T) {
+	got := chunkStrings([]string{"a", "b", "c", "d", "e"}, 2)
+	if len(got) != 3 || len(got[0]) != 2 || len(got[2]) != 1 {
+		t.Fatalf("unexpected chunks: %v", got)
+	}
+	if chunkStrings(nil, 2) != nil {
+		t.Fatalf("expected no chunks for empty input")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"google.golang.org/api/gmail/v1"
 
@@ -40,7 +39,7 @@ func (c *GmailDelegatesListCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"delegates": resp.Delegates})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"delegates": resp.Delegates})
 	}
 
 	if len(resp.Delegates) == 0 {
@@ -48,14 +47,14 @@ func (c *GmailDelegatesListCmd) Run(ctx context.Context, flags *RootFlags) error
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "EMAIL\tSTATUS")
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "EMAIL\tSTATUS")
 	for _, d := range resp.Delegates {
-		fmt.Fprintf(tw, "%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\n",
 			d.DelegateEmail,
 			d.VerificationStatus)
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
 
@@ -85,7 +84,7 @@ func (c *GmailDelegatesGetCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"delegate": delegate})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"delegate": delegate})
 	}
 
 	u.Out().Printf("delegate_email\t%s", delegate.DelegateEmail)
@@ -123,7 +122,7 @@ func (c *GmailDelegatesAddCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"delegate": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"delegate": created})
 	}
 
 	u.Out().Println("Delegate added successfully")
@@ -159,7 +158,7 @@ func (c *GmailDelegatesRemoveCmd) Run(ctx context.Context, flags *RootFlags) err
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"success":       true,
 			"delegateEmail": delegateEmail,
 		})
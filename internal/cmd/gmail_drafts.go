@@ -23,12 +23,18 @@ type GmailDraftsCmd struct {
 }
 
 type GmailDraftsListCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results" default:"20"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (per page with --all)" default:"20"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
+}
+
+type draftItem struct {
+	ID        string `json:"id"`
+	MessageID string `json:"messageId,omitempty"`
+	ThreadID  string `json:"threadId,omitempty"`
 }
 
 func (c *GmailDraftsListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -39,17 +45,12 @@ func (c *GmailDraftsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	resp, err := svc.Users.Drafts.List("me").MaxResults(c.Max).PageToken(c.Page).Do()
-	if err != nil {
-		return err
-	}
-	if outfmt.IsJSON(ctx) {
-		type item struct {
-			ID        string `json:"id"`
-			MessageID string `json:"messageId,omitempty"`
-			ThreadID  string `json:"threadId,omitempty"`
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]draftItem, string, error) {
+		resp, err := svc.Users.Drafts.List("me").MaxResults(pageSize).PageToken(pageToken).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
 		}
-		items := make([]item, 0, len(resp.Drafts))
+		items := make([]draftItem, 0, len(resp.Drafts))
 		for _, d := range resp.Drafts {
 			if d == nil {
 				continue
@@ -59,30 +60,19 @@ func (c *GmailDraftsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 				msgID = d.Message.Id
 				threadID = d.Message.ThreadId
 			}
-			items = append(items, item{ID: d.Id, MessageID: msgID, ThreadID: threadID})
+			items = append(items, draftItem{ID: d.Id, MessageID: msgID, ThreadID: threadID})
 		}
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"drafts":        items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-	if len(resp.Drafts) == 0 {
-		u.Err().Println("No drafts")
-		return nil
+		return items, resp.NextPageToken, nil
 	}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tMESSAGE_ID")
-	for _, d := range resp.Drafts {
-		msgID := ""
-		if d.Message != nil {
-			msgID = d.Message.Id
-		}
-		fmt.Fprintf(w, "%s\t%s\n", d.Id, msgID)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[draftItem]{
+		Key:    "drafts",
+		Empty:  "No drafts",
+		Header: "ID\tMESSAGE_ID",
+		Row: func(d draftItem) string {
+			return fmt.Sprintf("%s\t%s", d.ID, d.MessageID)
+		},
+	}, fetch)
 }
 
 type GmailDraftsGetCmd struct {
@@ -112,7 +102,7 @@ func (c *GmailDraftsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 	if draft.Message == nil {
 		if outfmt.IsJSON(ctx) {
-			return outfmt.WriteJSON(os.Stdout, map[string]any{"draft": draft})
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"draft": draft})
 		}
 		u.Err().Println("Empty draft")
 		return nil
@@ -149,7 +139,7 @@ func (c *GmailDraftsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 			}
 			out["downloaded"] = downloaded
 		}
-		return outfmt.WriteJSON(os.Stdout, out)
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
 	}
 
 	u.Out().Printf("Draft-ID: %s", draft.Id)
@@ -223,7 +213,7 @@ func (c *GmailDraftsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"deleted": true, "draftId": draftID})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"deleted": true, "draftId": draftID})
 	}
 	u.Out().Printf("deleted\ttrue")
 	u.Out().Printf("draft_id\t%s", draftID)
@@ -255,7 +245,7 @@ func (c *GmailDraftsSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"messageId": msg.Id,
 			"threadId":  msg.ThreadId,
 		})
@@ -274,10 +264,13 @@ type GmailDraftsCreateCmd struct {
 	Subject          string   `name:"subject" help:"Subject (required)"`
 	Body             string   `name:"body" help:"Body (plain text; required unless --body-html is set)"`
 	BodyHTML         string   `name:"body-html" help:"Body (HTML; optional)"`
+	BodyMarkdown     string   `name:"body-markdown" help:"Body (Markdown; rendered to HTML plus a plain text alternative, local images inlined)"`
+	BodyFile         string   `name:"body-file" help:"Read the body from a file (.md/.markdown = Markdown, .html/.htm = HTML, otherwise plain text)"`
 	ReplyToMessageID string   `name:"reply-to-message-id" help:"Reply to Gmail message ID (sets In-Reply-To/References and thread)"`
 	ReplyTo          string   `name:"reply-to" help:"Reply-To header address"`
 	Attach           []string `name:"attach" help:"Attachment file path (repeatable)"`
 	From             string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	GmailMIMEFlags
 }
 
 func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -289,8 +282,12 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 	if strings.TrimSpace(c.To) == "" || strings.TrimSpace(c.Subject) == "" {
 		return usage("required: --to, --subject")
 	}
-	if strings.TrimSpace(c.Body) == "" && strings.TrimSpace(c.BodyHTML) == "" {
-		return usage("required: --body or --body-html")
+	body, err := resolveMailBody(c.Body, c.BodyHTML, c.BodyMarkdown, c.BodyFile)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(body.Plain) == "" && strings.TrimSpace(body.HTML) == "" {
+		return usage("required: --body, --body-html, --body-markdown or --body-file")
 	}
 
 	svc, err := newGmailService(ctx, account)
@@ -327,19 +324,24 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		atts = append(atts, mailAttachment{Path: p})
 	}
 
-	raw, err := buildRFC822(mailOptions{
+	opts := mailOptions{
 		From:        fromAddr,
 		To:          splitCSV(c.To),
 		Cc:          splitCSV(c.Cc),
 		Bcc:         splitCSV(c.Bcc),
 		ReplyTo:     c.ReplyTo,
 		Subject:     c.Subject,
-		Body:        c.Body,
-		BodyHTML:    c.BodyHTML,
+		Body:        body.Plain,
+		BodyHTML:    body.HTML,
 		InReplyTo:   inReplyTo,
 		References:  references,
 		Attachments: atts,
-	})
+		Inline:      body.Inline,
+	}
+	if err := c.GmailMIMEFlags.apply(&opts); err != nil {
+		return err
+	}
+	raw, err := buildRFC822(opts)
 	if err != nil {
 		return err
 	}
@@ -356,7 +358,7 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"draftId":  draft.Id,
 			"message":  draft.Message,
 			"threadId": threadID,
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// gmailExportStateFile sits in the export directory and makes re-runs
+// incremental.
+const gmailExportStateFile = ".gog-export.json"
+
+type GmailExportCmd struct {
+	Query            string `name:"query" help:"Gmail search query selecting the messages (default: all mail)"`
+	Format           string `name:"format" help:"Output format: mbox|maildir|eml" enum:"mbox,maildir,eml" default:"mbox"`
+	Out              string `name:"out" help:"Output directory (holds the mailbox and the resume state)" required:""`
+	Concurrency      int    `name:"concurrency" help:"Messages fetched in parallel" default:"8"`
+	IncludeSpamTrash bool   `name:"include-spam-trash" help:"Include spam and trash"`
+}
+
+// gmailExportState records what an export directory already contains.
+type gmailExportState struct {
+	Account   string          `json:"account"`
+	Query     string          `json:"query"`
+	Format    string          `json:"format"`
+	HistoryID string          `json:"historyId,omitempty"` // mailbox history ID at the last complete run
+	Exported  map[string]bool `json:"exported"`            // Gmail message IDs already written
+	// MboxSize is the length of messages.mbox when Exported was saved.
+	// Anything past it was appended by a run killed before its next save and
+	// is cut off on resume, since those messages are exported again.
+	MboxSize  int64     `json:"mboxSize,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func loadGmailExportState(dir string) (*gmailExportState, error) {
+	data, err := os.ReadFile(filepath.Join(dir, gmailExportStateFile)) //nolint:gosec // user-provided output dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var st gmailExportState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("read export state: %w", err)
+	}
+	if st.Exported == nil {
+		st.Exported = map[string]bool{}
+	}
+	return &st, nil
+}
+
+func (st *gmailExportState) save(dir string) error {
+	if st.Format == mailboxFormatMbox {
+		info, err := os.Stat(filepath.Join(dir, mboxFileName))
+		switch {
+		case err == nil:
+			st.MboxSize = info.Size()
+		case errors.Is(err, os.ErrNotExist):
+			st.MboxSize = 0
+		default:
+			return err
+		}
+	}
+	st.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, gmailExportStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// trimMbox cuts messages.mbox back to MboxSize, dropping messages a killed
+// run appended after its last save. It returns the number of bytes removed.
+func (st *gmailExportState) trimMbox(dir string) (int64, error) {
+	if st.Format != mailboxFormatMbox {
+		return 0, nil
+	}
+	path := filepath.Join(dir, mboxFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	extra := info.Size() - st.MboxSize
+	if extra <= 0 {
+		return 0, nil
+	}
+	return extra, os.Truncate(path, st.MboxSize)
+}
+
+func (c *GmailExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	query := strings.TrimSpace(c.Query)
+	if c.Concurrency < 1 {
+		return usage("--concurrency must be at least 1")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Out, 0o700); err != nil {
+		return err
+	}
+	state, err := loadGmailExportState(c.Out)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		// Save before the first write so every appended message is covered
+		// by a recorded MboxSize.
+		state = &gmailExportState{Account: account, Query: query, Format: c.Format, Exported: map[string]bool{}}
+		if err := state.save(c.Out); err != nil {
+			return err
+		}
+	} else if !strings.EqualFold(state.Account, account) || state.Query != query || state.Format != c.Format {
+		return usagef("%s already holds a different export (account %s, query %q, format %s); use another --out", c.Out, state.Account, state.Query, state.Format)
+	} else if trimmed, err := state.trimMbox(c.Out); err != nil {
+		return err
+	} else if trimmed > 0 {
+		u.Err().Printf("Removed %d bytes of messages written after the last saved state; they will be exported again", trimmed)
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	fetchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	// Take the history ID before listing so changes made during the export
+	// are seen by the next run.
+	profile, err := svc.Users.GetProfile("me").Context(fetchCtx).Do()
+	if err != nil {
+		return err
+	}
+
+	upToDate := false
+	if state.HistoryID != "" {
+		upToDate, err = gmailExportUpToDate(fetchCtx, svc, state.HistoryID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var pending []string
+	total := len(state.Exported)
+	if !upToDate {
+		ids, err := listGmailExportIDs(fetchCtx, svc, query, c.IncludeSpamTrash)
+		if err != nil {
+			return interruptedOr(fetchCtx, err)
+		}
+		for _, id := range ids {
+			if !state.Exported[id] {
+				pending = append(pending, id)
+			}
+		}
+		total = len(ids)
+	}
+
+	w, err := newMailboxWriter(c.Format, c.Out)
+	if err != nil {
+		return err
+	}
+	written, exportErr := exportGmailMessages(fetchCtx, svc, w, pending, c.Concurrency, func(id string, done int) error {
+		state.Exported[id] = true
+		if done%100 == 0 {
+			u.Err().Printf("Exported %d/%d", done, len(pending))
+			return state.save(c.Out)
+		}
+		return nil
+	})
+	if err := w.Close(); err != nil && exportErr == nil {
+		exportErr = err
+	}
+	if exportErr == nil {
+		state.HistoryID = formatHistoryID(profile.HistoryId)
+	}
+	if err := state.save(c.Out); err != nil && exportErr == nil {
+		exportErr = err
+	}
+	if exportErr != nil {
+		if fetchCtx.Err() != nil {
+			u.Err().Printf("Interrupted after %d messages; re-run to resume", written)
+		}
+		return interruptedOr(fetchCtx, exportErr)
+	}
+
+	skipped := total - len(pending)
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"out":       c.Out,
+			"format":    c.Format,
+			"exported":  written,
+			"skipped":   skipped,
+			"total":     len(state.Exported),
+			"historyId": state.HistoryID,
+		})
+	}
+	u.Out().Printf("Exported %d messages to %s (%d already exported)", written, c.Out, skipped)
+	return nil
+}
+
+// gmailExportUpToDate reports whether nothing was added or relabeled since
+// historyID. A stale history ID just means "not known to be up to date".
+func gmailExportUpToDate(ctx context.Context, svc *gmail.Service, historyID string) (bool, error) {
+	startID, err := parseHistoryID(historyID)
+	if err != nil {
+		return false, nil //nolint:nilerr // corrupt state: fall back to a full listing
+	}
+	resp, err := svc.Users.History.List("me").
+		StartHistoryId(startID).
+		HistoryTypes("messageAdded", "labelAdded").
+		MaxResults(1).
+		Context(ctx).
+		Do()
+	if err != nil {
+		if isStaleHistoryError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(resp.History) == 0 && resp.NextPageToken == "", nil
+}
+
+func listGmailExportIDs(ctx context.Context, svc *gmail.Service, query string, includeSpamTrash bool) ([]string, error) {
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]string, string, error) {
+		call := svc.Users.Messages.List("me").
+			MaxResults(pageSize).
+			IncludeSpamTrash(includeSpamTrash).
+			Fields("messages(id),nextPageToken").
+			Context(ctx)
+		if query != "" {
+			call = call.Q(query)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		ids := make([]string, 0, len(resp.Messages))
+		for _, m := range resp.Messages {
+			if m != nil && m.Id != "" {
+				ids = append(ids, m.Id)
+			}
+		}
+		return ids, resp.NextPageToken, nil
+	}
+
+	var ids []string
+	_, err := fetchPages(ctx, pageOptions{Max: batchListPageSize, All: true}, fetch, func(page []string) error {
+		ids = append(ids, page...)
+		return nil
+	})
+	// The API lists newest first; export oldest first so the mbox reads
+	// chronologically and resumed runs append in order.
+	slices.Reverse(ids)
+	return ids, err
+}
+
+// exportGmailMessages fetches raw messages with bounded concurrency and
+// writes them in order from a single goroutine. A fetch slot is freed only
+// once its message is written, so at most concurrency messages are held in
+// memory even when one fetch is slow. onWritten runs after each successful
+// write with the running count.
+func exportGmailMessages(ctx context.Context, svc *gmail.Service, w mailboxWriter, ids []string, concurrency int, onWritten func(id string, done int) error) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		msg mailboxMessage
+		err error
+	}
+	// Message i lands in ring[i%concurrency]; the semaphore keeps fetches
+	// within concurrency of the writer, so a slot is always drained before
+	// it is reused.
+	ring := make([]chan result, concurrency)
+	for i := range ring {
+		ring[i] = make(chan result, 1)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	feederDone := make(chan struct{})
+	go func() {
+		defer close(feederDone)
+		for i, id := range ids {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(slot chan result, id string) {
+				defer wg.Done()
+				msg, err := fetchRawMessage(ctx, svc, id)
+				slot <- result{msg: msg, err: err}
+			}(ring[i%concurrency], id)
+		}
+	}()
+	stop := func() {
+		cancel()
+		<-feederDone
+		wg.Wait()
+	}
+
+	written := 0
+	for i, id := range ids {
+		var res result
+		select {
+		case res = <-ring[i%concurrency]:
+		case <-ctx.Done():
+			res.err = ctx.Err()
+		}
+		if res.err != nil {
+			stop()
+			return written, fmt.Errorf("message %s: %w", id, res.err)
+		}
+		if err := w.Write(res.msg); err != nil {
+			stop()
+			return written, err
+		}
+		<-sem
+		written++
+		if err := onWritten(res.msg.ID, written); err != nil {
+			stop()
+			return written, err
+		}
+	}
+	stop()
+	return written, nil
+}
+
+func fetchRawMessage(ctx context.Context, svc *gmail.Service, id string) (mailboxMessage, error) {
+	msg, err := svc.Users.Messages.Get("me", id).Format("raw").Context(ctx).Do()
+	if err != nil {
+		return mailboxMessage{}, err
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(msg.Raw, "="))
+	if err != nil {
+		return mailboxMessage{}, fmt.Errorf("decode raw message: %w", err)
+	}
+	return mailboxMessage{
+		ID:           msg.Id,
+		Raw:          raw,
+		LabelIDs:     msg.LabelIds,
+		InternalDate: time.UnixMilli(msg.InternalDate),
+	}, nil
+}
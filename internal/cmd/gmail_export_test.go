@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// fakeExportServer serves a tiny mailbox: message list (newest first), raw
+// messages, profile history ID and a history list that reports changes when
+// changed is set.
+type fakeExportServer struct {
+	mu        sync.Mutex
+	ids       []string // newest first, like the API
+	historyID string
+	changed   bool
+	fetched   []string
+	queries   []string
+	hold      chan struct{} // when set, fetching m1 blocks until it is closed
+}
+
+func newFakeExportServer(t *testing.T, ids ...string) *fakeExportServer {
+	t.Helper()
+
+	f := &fakeExportServer{ids: ids, historyID: "100"}
+	stubGmailService(t, http.HandlerFunc(f.serve))
+
+	return f
+}
+
+func (f *fakeExportServer) serve(w http.ResponseWriter, r *http.Request) {
+	if f.hold != nil && strings.HasSuffix(r.URL.Path, "/messages/m1") {
+		<-f.hold
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/users/me/profile"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": f.historyID})
+	case strings.HasSuffix(path, "/users/me/history"):
+		history := []map[string]any{}
+		if f.changed {
+			history = append(history, map[string]any{"id": "101"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"history": history, "historyId": f.historyID})
+	case strings.HasSuffix(path, "/users/me/messages"):
+		f.queries = append(f.queries, r.URL.Query().Get("q"))
+		msgs := make([]map[string]any, 0, len(f.ids))
+		for _, id := range f.ids {
+			msgs = append(msgs, map[string]any{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"messages": msgs})
+	case strings.Contains(path, "/users/me/messages/"):
+		id := path[strings.LastIndex(path, "/")+1:]
+		f.fetched = append(f.fetched, id)
+		raw := "From: sender@example.com\r\nSubject: " + id + "\r\n\r\nFrom the body of " + id + "\r\n"
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":           id,
+			"raw":          base64.URLEncoding.EncodeToString([]byte(raw)),
+			"labelIds":     []string{"INBOX"},
+			"internalDate": "1700000000000",
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func runGmailExport(t *testing.T, args ...string) map[string]any {
+	t.Helper()
+	out := captureStdout(t, func() {
+		u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+		if err != nil {
+			t.Fatalf("ui.New: %v", err)
+		}
+		ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+		if err := runKong(t, &GmailExportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	return parsed
+}
+
+func TestGmailExport_MboxIncremental(t *testing.T) {
+	f := newFakeExportServer(t, "m3", "m2", "m1")
+	dir := t.TempDir()
+
+	res := runGmailExport(t, "--query", "label:project", "--out", dir)
+	if res["exported"] != float64(3) || res["historyId"] != "100" {
+		t.Fatalf("unexpected first run: %v", res)
+	}
+	if f.queries[0] != "label:project" {
+		t.Fatalf("unexpected query: %v", f.queries)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "messages.mbox"))
+	if err != nil {
+		t.Fatalf("read mbox: %v", err)
+	}
+	mbox := string(data)
+	if strings.Count(mbox, "\nFrom sender@example.com ") != 2 || !strings.HasPrefix(mbox, "From sender@example.com ") {
+		t.Fatalf("expected 3 From_ lines:\n%s", mbox)
+	}
+	if strings.Index(mbox, "Subject: m1") > strings.Index(mbox, "Subject: m3") {
+		t.Fatalf("expected oldest first:\n%s", mbox)
+	}
+	if !strings.Contains(mbox, "\n>From the body of m1") {
+		t.Fatalf("body From not quoted:\n%s", mbox)
+	}
+
+	// Nothing changed: the history check skips listing entirely.
+	res = runGmailExport(t, "--query", "label:project", "--out", dir)
+	if res["exported"] != float64(0) || len(f.queries) != 1 {
+		t.Fatalf("expected no-op run, got %v (queries %v)", res, f.queries)
+	}
+
+	// A new message: only it is fetched and appended.
+	f.mu.Lock()
+	f.ids = append([]string{"m4"}, f.ids...)
+	f.changed = true
+	f.historyID = "105"
+	f.fetched = nil
+	f.mu.Unlock()
+	res = runGmailExport(t, "--query", "label:project", "--out", dir)
+	if res["exported"] != float64(1) || res["skipped"] != float64(3) || res["historyId"] != "105" {
+		t.Fatalf("unexpected incremental run: %v", res)
+	}
+	if len(f.fetched) != 1 || f.fetched[0] != "m4" {
+		t.Fatalf("unexpected fetches: %v", f.fetched)
+	}
+
+	state, err := loadGmailExportState(dir)
+	if err != nil || state == nil || len(state.Exported) != 4 {
+		t.Fatalf("unexpected state: %+v (%v)", state, err)
+	}
+}
+
+func TestGmailExport_MboxResumeAfterKill(t *testing.T) {
+	f := newFakeExportServer(t, "m1")
+	dir := t.TempDir()
+	runGmailExport(t, "--out", dir)
+
+	// A run killed between state saves leaves m2 in the mbox but not in
+	// the state.
+	path := filepath.Join(dir, "messages.mbox")
+	mbox, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open mbox: %v", err)
+	}
+	_, _ = mbox.WriteString("From sender@example.com Tue Nov 14 22:13:20 2023\nSubject: m2\n\npartial")
+	_ = mbox.Close()
+
+	f.mu.Lock()
+	f.ids = []string{"m2", "m1"}
+	f.changed = true
+	f.historyID = "105"
+	f.mu.Unlock()
+	res := runGmailExport(t, "--out", dir)
+	if res["exported"] != float64(1) {
+		t.Fatalf("unexpected resume: %v", res)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read mbox: %v", err)
+	}
+	if got := strings.Count(string(data), "Subject: m2\n"); got != 1 || strings.Contains(string(data), "partial") {
+		t.Fatalf("expected m2 exactly once without the partial copy:\n%s", data)
+	}
+	state, err := loadGmailExportState(dir)
+	if err != nil || state.MboxSize != int64(len(data)) {
+		t.Fatalf("state MboxSize %d, mbox %d bytes (%v)", state.MboxSize, len(data), err)
+	}
+}
+
+func TestGmailExport_EMLAndMismatch(t *testing.T) {
+	newFakeExportServer(t, "m2", "m1")
+	dir := t.TempDir()
+
+	runGmailExport(t, "--format", "eml", "--out", dir, "--concurrency", "1")
+	for _, id := range []string{"m1", "m2"} {
+		data, err := os.ReadFile(filepath.Join(dir, id+".eml"))
+		if err != nil || !strings.Contains(string(data), "Subject: "+id+"\r\n") {
+			t.Fatalf("%s.eml: %q (%v)", id, data, err)
+		}
+	}
+
+	err := runKong(t, &GmailExportCmd{}, []string{"--format", "mbox", "--out", dir}, context.Background(), &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "different export") {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+}
+
+// memoryWriter collects written messages.
+type memoryWriter struct{ ids []string }
+
+func (w *memoryWriter) Write(m mailboxMessage) error {
+	w.ids = append(w.ids, m.ID)
+	return nil
+}
+
+func (w *memoryWriter) Close() error { return nil }
+
+func TestExportGmailMessages_SlowMessageBoundsPrefetch(t *testing.T) {
+	f := newFakeExportServer(t)
+	f.hold = make(chan struct{})
+	svc, _ := newGmailService(context.Background(), "a@b.com")
+
+	ids := []string{"m1", "m2", "m3", "m4", "m5", "m6"}
+	w := &memoryWriter{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := exportGmailMessages(context.Background(), svc, w, ids, 2, func(string, int) error { return nil })
+		done <- err
+	}()
+
+	// While m1 is stuck, only one more message may be fetched and held.
+	time.Sleep(200 * time.Millisecond)
+	f.mu.Lock()
+	fetched := len(f.fetched)
+	f.mu.Unlock()
+	close(f.hold)
+	if err := <-done; err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if fetched > 1 {
+		t.Fatalf("expected at most 1 message fetched ahead of a slow one, got %d", fetched)
+	}
+	if strings.Join(w.ids, ",") != strings.Join(ids, ",") {
+		t.Fatalf("unexpected write order: %v", w.ids)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"google.golang.org/api/gmail/v1"
 
@@ -19,6 +18,9 @@ type GmailFiltersCmd struct {
 	Get    GmailFiltersGetCmd    `cmd:"" name:"get" help:"Get a specific filter"`
 	Create GmailFiltersCreateCmd `cmd:"" name:"create" help:"Create a new email filter"`
 	Delete GmailFiltersDeleteCmd `cmd:"" name:"delete" help:"Delete a filter"`
+	Export GmailFiltersExportCmd `cmd:"" name:"export" help:"Export all filters as a YAML/JSON file (or Gmail's mailFilters.xml)"`
+	Import GmailFiltersImportCmd `cmd:"" name:"import" help:"Import filters from Gmail's mailFilters.xml"`
+	Apply  GmailFiltersApplyCmd  `cmd:"" name:"apply" help:"Make the account's filters match a YAML/JSON file"`
 }
 
 type GmailFiltersListCmd struct{}
@@ -41,7 +43,7 @@ func (c *GmailFiltersListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"filters": resp.Filter})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"filters": resp.Filter})
 	}
 
 	if len(resp.Filter) == 0 {
@@ -49,8 +51,8 @@ func (c *GmailFiltersListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "ID\tFROM\tTO\tSUBJECT\tQUERY")
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "ID\tFROM\tTO\tSUBJECT\tQUERY")
 	for _, f := range resp.Filter {
 		criteria := f.Criteria
 		from := ""
@@ -63,14 +65,14 @@ func (c *GmailFiltersListCmd) Run(ctx context.Context, flags *RootFlags) error {
 			subject = criteria.Subject
 			query = criteria.Query
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			f.Id,
 			sanitizeTab(from),
 			sanitizeTab(to),
 			sanitizeTab(subject),
 			sanitizeTab(query))
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
 
@@ -100,7 +102,7 @@ func (c *GmailFiltersGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"filter": filter})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"filter": filter})
 	}
 
 	u.Out().Printf("id\t%s", filter.Id)
@@ -293,7 +295,7 @@ func (c *GmailFiltersCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"filter": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"filter": created})
 	}
 
 	u.Out().Println("Filter created successfully")
@@ -342,7 +344,7 @@ func (c *GmailFiltersDeleteCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"success":  true,
 			"filterId": filterID,
 		})
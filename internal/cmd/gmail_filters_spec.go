@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// filterFile is the declarative filter set read by `filters apply` and
+// written by `filters export`.
+type filterFile struct {
+	Filters []filterSpec `json:"filters" yaml:"filters"`
+}
+
+// filterSpec is one Gmail filter with labels referenced by name.
+type filterSpec struct {
+	Criteria filterCriteriaSpec `json:"criteria" yaml:"criteria"`
+	Action   filterActionSpec   `json:"action" yaml:"action"`
+}
+
+type filterCriteriaSpec struct {
+	From           string `json:"from,omitempty" yaml:"from,omitempty"`
+	To             string `json:"to,omitempty" yaml:"to,omitempty"`
+	Subject        string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Query          string `json:"query,omitempty" yaml:"query,omitempty"`
+	NegatedQuery   string `json:"negatedQuery,omitempty" yaml:"negatedQuery,omitempty"`
+	HasAttachment  bool   `json:"hasAttachment,omitempty" yaml:"hasAttachment,omitempty"`
+	ExcludeChats   bool   `json:"excludeChats,omitempty" yaml:"excludeChats,omitempty"`
+	Size           int64  `json:"size,omitempty" yaml:"size,omitempty"`
+	SizeComparison string `json:"sizeComparison,omitempty" yaml:"sizeComparison,omitempty"` // larger|smaller
+}
+
+// filterActionSpec mirrors the `filters create` flags: the booleans are
+// shorthands for the matching system labels.
+type filterActionSpec struct {
+	AddLabels    []string `json:"addLabels,omitempty" yaml:"addLabels,omitempty"`
+	RemoveLabels []string `json:"removeLabels,omitempty" yaml:"removeLabels,omitempty"`
+	Archive      bool     `json:"archive,omitempty" yaml:"archive,omitempty"`
+	MarkRead     bool     `json:"markRead,omitempty" yaml:"markRead,omitempty"`
+	Star         bool     `json:"star,omitempty" yaml:"star,omitempty"`
+	Trash        bool     `json:"trash,omitempty" yaml:"trash,omitempty"`
+	NeverSpam    bool     `json:"neverSpam,omitempty" yaml:"neverSpam,omitempty"`
+	Important    bool     `json:"important,omitempty" yaml:"important,omitempty"`
+	Forward      string   `json:"forward,omitempty" yaml:"forward,omitempty"`
+}
+
+func (c filterCriteriaSpec) empty() bool {
+	return c == filterCriteriaSpec{}
+}
+
+func (a filterActionSpec) empty() bool {
+	return len(a.AddLabels) == 0 && len(a.RemoveLabels) == 0 && a.Forward == "" &&
+		!a.Archive && !a.MarkRead && !a.Star && !a.Trash && !a.NeverSpam && !a.Important
+}
+
+// parseFilterFile decodes a filter file; JSON when format is "json",
+// YAML otherwise.
+func parseFilterFile(data []byte, format string) (filterFile, error) {
+	var f filterFile
+	if format == "json" {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return filterFile{}, fmt.Errorf("parse filters JSON: %w", err)
+		}
+	} else {
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil {
+			return filterFile{}, fmt.Errorf("parse filters YAML: %w", err)
+		}
+	}
+
+	for i, s := range f.Filters {
+		if s.Criteria.empty() {
+			return filterFile{}, fmt.Errorf("filters[%d]: no criteria", i)
+		}
+		if s.Action.empty() {
+			return filterFile{}, fmt.Errorf("filters[%d]: no action", i)
+		}
+		switch strings.ToLower(s.Criteria.SizeComparison) {
+		case "", "larger", "smaller":
+		default:
+			return filterFile{}, fmt.Errorf("filters[%d]: invalid sizeComparison %q (expected larger|smaller)", i, s.Criteria.SizeComparison)
+		}
+	}
+	return f, nil
+}
+
+// systemLabelShorthands maps the action booleans to the label they add or
+// remove, in the order `filters create` applies them.
+var systemLabelShorthands = []struct {
+	label  string
+	remove bool
+	get    func(*filterActionSpec) *bool
+}{
+	{"INBOX", true, func(a *filterActionSpec) *bool { return &a.Archive }},
+	{"UNREAD", true, func(a *filterActionSpec) *bool { return &a.MarkRead }},
+	{"STARRED", false, func(a *filterActionSpec) *bool { return &a.Star }},
+	{"TRASH", false, func(a *filterActionSpec) *bool { return &a.Trash }},
+	{"SPAM", true, func(a *filterActionSpec) *bool { return &a.NeverSpam }},
+	{"IMPORTANT", false, func(a *filterActionSpec) *bool { return &a.Important }},
+}
+
+// filterSpecFromGmail converts an API filter, naming labels via idToName
+// and folding system labels back into the action shorthands.
+func filterSpecFromGmail(f *gmail.Filter, idToName map[string]string) filterSpec {
+	var s filterSpec
+	if c := f.Criteria; c != nil {
+		s.Criteria = filterCriteriaSpec{
+			From:           c.From,
+			To:             c.To,
+			Subject:        c.Subject,
+			Query:          c.Query,
+			NegatedQuery:   c.NegatedQuery,
+			HasAttachment:  c.HasAttachment,
+			ExcludeChats:   c.ExcludeChats,
+			Size:           c.Size,
+			SizeComparison: c.SizeComparison,
+		}
+	}
+	a := f.Action
+	if a == nil {
+		return s
+	}
+	s.Action.Forward = a.Forward
+
+	labels := func(ids []string, remove bool) []string {
+		var out []string
+	next:
+		for _, id := range ids {
+			for _, sh := range systemLabelShorthands {
+				if sh.remove == remove && sh.label == id {
+					*sh.get(&s.Action) = true
+					continue next
+				}
+			}
+			if name, ok := idToName[id]; ok {
+				out = append(out, name)
+			} else {
+				out = append(out, id)
+			}
+		}
+		return out
+	}
+	s.Action.AddLabels = labels(a.AddLabelIds, false)
+	s.Action.RemoveLabels = labels(a.RemoveLabelIds, true)
+	return s
+}
+
+// labelNames returns every label name the spec references (shorthands excluded).
+func (s filterSpec) labelNames() []string {
+	out := make([]string, 0, len(s.Action.AddLabels)+len(s.Action.RemoveLabels))
+	out = append(out, s.Action.AddLabels...)
+	return append(out, s.Action.RemoveLabels...)
+}
+
+// toGmail builds the API filter, resolving label names through nameToID
+// (lowercase names/IDs, as from fetchLabelNameToID).
+func (s filterSpec) toGmail(nameToID map[string]string) *gmail.Filter {
+	c := s.Criteria
+	action := &gmail.FilterAction{
+		AddLabelIds:    resolveLabelIDs(s.Action.AddLabels, nameToID),
+		RemoveLabelIds: resolveLabelIDs(s.Action.RemoveLabels, nameToID),
+		Forward:        strings.TrimSpace(s.Action.Forward),
+	}
+	for _, sh := range systemLabelShorthands {
+		if !*sh.get(&s.Action) {
+			continue
+		}
+		if sh.remove {
+			action.RemoveLabelIds = appendUnique(action.RemoveLabelIds, sh.label)
+		} else {
+			action.AddLabelIds = appendUnique(action.AddLabelIds, sh.label)
+		}
+	}
+	return &gmail.Filter{
+		Criteria: &gmail.FilterCriteria{
+			From:           c.From,
+			To:             c.To,
+			Subject:        c.Subject,
+			Query:          c.Query,
+			NegatedQuery:   c.NegatedQuery,
+			HasAttachment:  c.HasAttachment,
+			ExcludeChats:   c.ExcludeChats,
+			Size:           c.Size,
+			SizeComparison: strings.ToLower(c.SizeComparison),
+		},
+		Action: action,
+	}
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+// filterKey identifies a filter by its criteria and action (label IDs
+// sorted), since Gmail filters have no stable name and cannot be updated.
+func filterKey(f *gmail.Filter) string {
+	type key struct {
+		Criteria gmail.FilterCriteria
+		Add      []string
+		Remove   []string
+		Forward  string
+	}
+	var k key
+	if f.Criteria != nil {
+		k.Criteria = *f.Criteria
+		k.Criteria.SizeComparison = strings.ToLower(k.Criteria.SizeComparison)
+		k.Criteria.ForceSendFields, k.Criteria.NullFields = nil, nil
+	}
+	if f.Action != nil {
+		k.Add = sortedCopy(f.Action.AddLabelIds)
+		k.Remove = sortedCopy(f.Action.RemoveLabelIds)
+		k.Forward = strings.ToLower(f.Action.Forward)
+	}
+	b, _ := json.Marshal(k)
+	return string(b)
+}
+
+func sortedCopy(in []string) []string {
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	return out
+}
+
+// describeFilter is a one-line summary, e.g. "from:a@b.com => +Work archive".
+func describeFilter(s filterSpec) string {
+	var crit []string
+	c := s.Criteria
+	add := func(k, v string) {
+		if v != "" {
+			crit = append(crit, k+":"+v)
+		}
+	}
+	add("from", c.From)
+	add("to", c.To)
+	add("subject", c.Subject)
+	add("query", c.Query)
+	add("-query", c.NegatedQuery)
+	if c.HasAttachment {
+		crit = append(crit, "has:attachment")
+	}
+	if c.ExcludeChats {
+		crit = append(crit, "-chats")
+	}
+	if c.Size > 0 {
+		crit = append(crit, fmt.Sprintf("size:%s:%d", strings.ToLower(c.SizeComparison), c.Size))
+	}
+
+	var acts []string
+	a := s.Action
+	for _, l := range a.AddLabels {
+		acts = append(acts, "+"+l)
+	}
+	for _, l := range a.RemoveLabels {
+		acts = append(acts, "-"+l)
+	}
+	for _, f := range []struct {
+		on   bool
+		name string
+	}{{a.Archive, "archive"}, {a.MarkRead, "mark-read"}, {a.Star, "star"}, {a.Trash, "trash"}, {a.NeverSpam, "never-spam"}, {a.Important, "important"}} {
+		if f.on {
+			acts = append(acts, f.name)
+		}
+	}
+	if a.Forward != "" {
+		acts = append(acts, "forward:"+a.Forward)
+	}
+	return strings.Join(crit, " ") + " => " + strings.Join(acts, " ")
+}
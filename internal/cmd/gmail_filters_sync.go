@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailFiltersExportCmd struct {
+	Format string `name:"format" help:"File format: yaml|json|xml (xml = Gmail's mailFilters.xml)" enum:"yaml,json,xml" default:"yaml"`
+	Out    string `name:"out" help:"Write to this file instead of stdout"`
+}
+
+func (c *GmailFiltersExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	idToName, err := fetchLabelIDToName(svc)
+	if err != nil {
+		return err
+	}
+
+	file := filterFile{Filters: make([]filterSpec, 0, len(resp.Filter))}
+	for _, f := range resp.Filter {
+		file.Filters = append(file.Filters, filterSpecFromGmail(f, idToName))
+	}
+
+	var data []byte
+	switch c.Format {
+	case "json":
+		data, err = json.MarshalIndent(file, "", "  ")
+		data = append(data, '\n')
+	case "xml":
+		var buf bytes.Buffer
+		var warnings []string
+		warnings, err = writeMailFiltersXML(&buf, file.Filters, account, time.Now())
+		for _, w := range warnings {
+			ui.FromContext(ctx).Err().Printf("warning: %s", w)
+		}
+		data = buf.Bytes()
+	default:
+		data, err = yaml.Marshal(file)
+	}
+	if err != nil {
+		return fmt.Errorf("encode filters: %w", err)
+	}
+
+	if c.Out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(c.Out, data, 0o600); err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": c.Out, "count": len(file.Filters)})
+	}
+	ui.FromContext(ctx).Err().Printf("Exported %d filters to %s", len(file.Filters), c.Out)
+	return nil
+}
+
+type GmailFiltersApplyCmd struct {
+	File   string `arg:"" name:"file" help:"Filter file (.yaml/.yml/.json) or '-' for stdin"`
+	Format string `name:"format" help:"File format: yaml|json (default: from the file extension, YAML for stdin)"`
+	Prune  bool   `name:"prune" help:"Delete existing filters that are not in the file"`
+	DryRun bool   `name:"dry-run" help:"Show the changes without applying them"`
+}
+
+// filterChange is one step of an apply plan.
+type filterChange struct {
+	Op      string `json:"op"` // create|delete|keep
+	ID      string `json:"id,omitempty"`
+	Summary string `json:"summary"`
+
+	spec filterSpec // for create
+}
+
+func (c *GmailFiltersApplyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	var data []byte
+	var err error
+	if c.File == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(c.File) //nolint:gosec // user-provided path
+	}
+	if err != nil {
+		return err
+	}
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	switch {
+	case format == "" && strings.EqualFold(filepath.Ext(c.File), ".json"):
+		format = "json"
+	case format == "", format == "yaml", format == "yml", format == "json":
+	default:
+		return usagef("invalid --format %q (expected yaml|json)", c.Format)
+	}
+	file, err := parseFilterFile(data, format)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return applyFilterSpecs(ctx, flags, svc, file.Filters, c.Prune, c.DryRun)
+}
+
+// applyFilterSpecs diffs desired against the account's filters (by criteria
+// and action), creates missing labels, creates missing filters and, with
+// prune, deletes the rest. Gmail has no filter update, so a changed filter
+// is a create plus (with prune) a delete.
+func applyFilterSpecs(ctx context.Context, flags *RootFlags, svc *gmail.Service, desired []filterSpec, prune, dryRun bool) error {
+	u := ui.FromContext(ctx)
+
+	resp, err := svc.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	labelsResp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	nameToID := map[string]string{}
+	idToName := map[string]string{}
+	for _, l := range labelsResp.Labels {
+		nameToID[strings.ToLower(l.Id)] = l.Id
+		nameToID[strings.ToLower(l.Name)] = l.Id
+		idToName[l.Id] = l.Name
+	}
+
+	var missingLabels []string
+	for _, s := range desired {
+		for _, name := range s.labelNames() {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := nameToID[strings.ToLower(name)]; !ok {
+				missingLabels = appendUnique(missingLabels, name)
+				nameToID[strings.ToLower(name)] = name // placeholder until created
+			}
+		}
+	}
+
+	existing := map[string]*gmail.Filter{}
+	for _, f := range resp.Filter {
+		existing[filterKey(f)] = f
+	}
+
+	var plan []filterChange
+	wanted := map[string]bool{}
+	for _, s := range desired {
+		f := s.toGmail(nameToID)
+		key := filterKey(f)
+		if wanted[key] {
+			continue // duplicate entry in the file
+		}
+		wanted[key] = true
+		if ex, ok := existing[key]; ok {
+			plan = append(plan, filterChange{Op: "keep", ID: ex.Id, Summary: describeFilter(s)})
+			continue
+		}
+		plan = append(plan, filterChange{Op: "create", Summary: describeFilter(s), spec: s})
+	}
+	unmanaged := 0
+	for _, f := range resp.Filter {
+		if wanted[filterKey(f)] {
+			continue
+		}
+		if !prune {
+			unmanaged++
+			continue
+		}
+		plan = append(plan, filterChange{Op: "delete", ID: f.Id, Summary: describeFilter(filterSpecFromGmail(f, idToName))})
+	}
+
+	counts := map[string]int{}
+	for _, ch := range plan {
+		counts[ch.Op]++
+	}
+
+	if !dryRun && counts["delete"] > 0 {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("delete %d filters", counts["delete"])); err != nil {
+			return err
+		}
+	}
+
+	if !dryRun {
+		for _, name := range missingLabels {
+			l, err := svc.Users.Labels.Create("me", &gmail.Label{Name: name}).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("create label %q: %w", name, err)
+			}
+			nameToID[strings.ToLower(name)] = l.Id
+		}
+		// Create before deleting so a failure never leaves the account with
+		// fewer rules than before.
+		for i, ch := range plan {
+			if ch.Op != "create" {
+				continue
+			}
+			created, err := svc.Users.Settings.Filters.Create("me", ch.spec.toGmail(nameToID)).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("create filter %s: %w", ch.Summary, err)
+			}
+			plan[i].ID = created.Id
+		}
+		for _, ch := range plan {
+			if ch.Op != "delete" {
+				continue
+			}
+			if err := svc.Users.Settings.Filters.Delete("me", ch.ID).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("delete filter %s: %w", ch.ID, err)
+			}
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dryRun":    dryRun,
+			"changes":   plan,
+			"created":   counts["create"],
+			"deleted":   counts["delete"],
+			"unchanged": counts["keep"],
+			"unmanaged": unmanaged,
+			"newLabels": missingLabels,
+		})
+	}
+
+	for _, name := range missingLabels {
+		u.Out().Printf("+ label\t%s", name)
+	}
+	for _, ch := range plan {
+		switch ch.Op {
+		case "create":
+			u.Out().Printf("+ filter\t%s", ch.Summary)
+		case "delete":
+			u.Out().Printf("- filter\t%s\t%s", ch.ID, ch.Summary)
+		}
+	}
+	if dryRun {
+		u.Err().Printf("Dry run: %d to create, %d to delete, %d unchanged", counts["create"], counts["delete"], counts["keep"])
+	} else {
+		u.Err().Printf("Created %d, deleted %d, unchanged %d", counts["create"], counts["delete"], counts["keep"])
+	}
+	if unmanaged > 0 {
+		u.Err().Printf("%d existing filters are not in the file (use --prune to delete them)", unmanaged)
+	}
+	return nil
+}
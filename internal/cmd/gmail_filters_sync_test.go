@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// fakeFilterServer is an in-memory Users.Settings.Filters + Users.Labels backend.
+type fakeFilterServer struct {
+	mu      sync.Mutex
+	filters []*gmail.Filter
+	labels  []*gmail.Label
+	nextID  int
+	deleted []string
+}
+
+func newFakeFilterServer(t *testing.T, filters []*gmail.Filter, labels []*gmail.Label) *fakeFilterServer {
+	t.Helper()
+
+	f := &fakeFilterServer{filters: filters, labels: labels}
+	stubGmailService(t, http.HandlerFunc(f.serve))
+
+	return f
+}
+
+func (f *fakeFilterServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/users/me/labels") && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": f.labels})
+	case strings.HasSuffix(r.URL.Path, "/users/me/labels") && r.Method == http.MethodPost:
+		var l gmail.Label
+		_ = json.NewDecoder(r.Body).Decode(&l)
+		f.nextID++
+		l.Id = fmt.Sprintf("Label_new%d", f.nextID)
+		f.labels = append(f.labels, &l)
+		_ = json.NewEncoder(w).Encode(&l)
+	case strings.HasSuffix(r.URL.Path, "/settings/filters") && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"filter": f.filters})
+	case strings.HasSuffix(r.URL.Path, "/settings/filters") && r.Method == http.MethodPost:
+		var flt gmail.Filter
+		_ = json.NewDecoder(r.Body).Decode(&flt)
+		f.nextID++
+		flt.Id = fmt.Sprintf("f_new%d", f.nextID)
+		f.filters = append(f.filters, &flt)
+		_ = json.NewEncoder(w).Encode(&flt)
+	case strings.Contains(r.URL.Path, "/settings/filters/") && r.Method == http.MethodDelete:
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		f.deleted = append(f.deleted, id)
+		kept := f.filters[:0]
+		for _, flt := range f.filters {
+			if flt.Id != id {
+				kept = append(kept, flt)
+			}
+		}
+		f.filters = kept
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func baseFilterFixtures() ([]*gmail.Filter, []*gmail.Label) {
+	labels := []*gmail.Label{
+		{Id: "INBOX", Name: "INBOX", Type: "system"},
+		{Id: "UNREAD", Name: "UNREAD", Type: "system"},
+		{Id: "Label_1", Name: "Notifications", Type: "user"},
+	}
+	filters := []*gmail.Filter{
+		{
+			Id:       "f1",
+			Criteria: &gmail.FilterCriteria{From: "noreply@example.com"},
+			Action:   &gmail.FilterAction{AddLabelIds: []string{"Label_1"}, RemoveLabelIds: []string{"INBOX"}},
+		},
+		{
+			Id:       "f2",
+			Criteria: &gmail.FilterCriteria{Subject: "old rule"},
+			Action:   &gmail.FilterAction{RemoveLabelIds: []string{"UNREAD"}},
+		},
+	}
+	return filters, labels
+}
+
+func TestGmailFiltersExport_YAML(t *testing.T) {
+	filters, labels := baseFilterFixtures()
+	newFakeFilterServer(t, filters, labels)
+
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersExportCmd{}, []string{}, testUIContext(t, io.Discard), &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	for _, want := range []string{"from: noreply@example.com", "- Notifications", "archive: true", "markRead: true"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in export:\n%s", want, out)
+		}
+	}
+	parsed, err := parseFilterFile([]byte(out), "yaml")
+	if err != nil {
+		t.Fatalf("re-parse export: %v", err)
+	}
+	if len(parsed.Filters) != 2 {
+		t.Fatalf("unexpected round trip: %+v", parsed)
+	}
+}
+
+func TestGmailFiltersApply_DryRunPrune(t *testing.T) {
+	filters, labels := baseFilterFixtures()
+	f := newFakeFilterServer(t, filters, labels)
+
+	path := filepath.Join(t.TempDir(), "filters.yaml")
+	spec := `filters:
+  - criteria:
+      from: noreply@example.com
+    action:
+      addLabels: [notifications]
+      archive: true
+  - criteria:
+      to: team@example.com
+    action:
+      addLabels: [Team]
+`
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune", "--dry-run"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	var parsed struct {
+		DryRun    bool     `json:"dryRun"`
+		Created   int      `json:"created"`
+		Deleted   int      `json:"deleted"`
+		Unchanged int      `json:"unchanged"`
+		NewLabels []string `json:"newLabels"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if !parsed.DryRun || parsed.Created != 1 || parsed.Deleted != 1 || parsed.Unchanged != 1 {
+		t.Fatalf("unexpected plan: %+v", parsed)
+	}
+	if len(parsed.NewLabels) != 1 || parsed.NewLabels[0] != "Team" {
+		t.Fatalf("unexpected new labels: %v", parsed.NewLabels)
+	}
+	if len(f.filters) != 2 || len(f.labels) != 3 || len(f.deleted) != 0 {
+		t.Fatalf("dry run changed state: filters=%d labels=%d deleted=%v", len(f.filters), len(f.labels), f.deleted)
+	}
+}
+
+func TestGmailFiltersApply_CreatesLabelsAndPrunes(t *testing.T) {
+	filters, labels := baseFilterFixtures()
+	f := newFakeFilterServer(t, filters, labels)
+
+	path := filepath.Join(t.TempDir(), "filters.json")
+	spec := `{"filters": [
+  {"criteria": {"from": "noreply@example.com"}, "action": {"addLabels": ["Notifications"], "archive": true}},
+  {"criteria": {"to": "team@example.com", "hasAttachment": true}, "action": {"addLabels": ["Team"], "star": true}}
+]}`
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Deleting needs confirmation.
+	err := runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com", NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "without --force") {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	if len(f.deleted) != 1 || f.deleted[0] != "f2" {
+		t.Fatalf("unexpected deletes: %v", f.deleted)
+	}
+	if len(f.filters) != 2 {
+		t.Fatalf("unexpected filters: %+v", f.filters)
+	}
+	created := f.filters[1]
+	if created.Criteria.To != "team@example.com" || !created.Criteria.HasAttachment {
+		t.Fatalf("unexpected created criteria: %+v", created.Criteria)
+	}
+	if got := strings.Join(created.Action.AddLabelIds, ","); !strings.HasPrefix(got, "Label_new") || !strings.HasSuffix(got, ",STARRED") {
+		t.Fatalf("unexpected created labels: %v", created.Action.AddLabelIds)
+	}
+
+	// Re-applying is a no-op.
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com", NoInput: true}); err != nil {
+			t.Fatalf("re-apply: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"unchanged": 2`) {
+		t.Fatalf("expected no-op re-apply, got %s", out)
+	}
+}
+
+func TestParseFilterFile_Validation(t *testing.T) {
+	cases := map[string]string{
+		"no criteria": "filters:\n  - action:\n      star: true\n",
+		"no action":   "filters:\n  - criteria:\n      from: a@b.com\n",
+		"field":       "filters:\n  - criteria:\n      sender: a@b.com\n",
+		"sizeCompar":  "filters:\n  - criteria:\n      size: 5\n      sizeComparison: bigger\n    action:\n      star: true\n",
+	}
+	for name, in := range cases {
+		if _, err := parseFilterFile([]byte(in), "yaml"); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestFilterSpec_RoundTrip(t *testing.T) {
+	orig := &gmail.Filter{
+		Criteria: &gmail.FilterCriteria{From: "a@b.com", Size: 10, SizeComparison: "larger"},
+		Action:   &gmail.FilterAction{AddLabelIds: []string{"IMPORTANT", "Label_1"}, RemoveLabelIds: []string{"SPAM", "INBOX"}, Forward: "x@y.com"},
+	}
+	spec := filterSpecFromGmail(orig, map[string]string{"Label_1": "Work"})
+	if !spec.Action.Important || !spec.Action.NeverSpam || !spec.Action.Archive || len(spec.Action.AddLabels) != 1 {
+		t.Fatalf("unexpected spec: %+v", spec.Action)
+	}
+	back := spec.toGmail(map[string]string{"work": "Label_1"})
+	if filterKey(back) != filterKey(orig) {
+		t.Fatalf("round trip mismatch:\n%s\n%s", filterKey(back), filterKey(orig))
+	}
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// Gmail's web UI exports/imports filters as an Atom feed (mailFilters.xml):
+// one <entry> per filter, with criteria and actions as <apps:property> pairs.
+const (
+	atomNS = "http://www.w3.org/2005/Atom"
+	appsNS = "http://schemas.google.com/apps/2006"
+)
+
+type mailFiltersFeed struct {
+	XMLName xml.Name          `xml:"feed"`
+	Entries []mailFilterEntry `xml:"entry"`
+}
+
+type mailFilterEntry struct {
+	Properties []mailFilterProperty `xml:"property"`
+}
+
+type mailFilterProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// smartLabels maps mailFilters.xml smartLabelToApply values to category labels.
+var smartLabels = map[string]string{
+	"^smartlabel_personal":     "CATEGORY_PERSONAL",
+	"^smartlabel_social":       "CATEGORY_SOCIAL",
+	"^smartlabel_promo":        "CATEGORY_PROMOTIONS",
+	"^smartlabel_notification": "CATEGORY_UPDATES",
+	"^smartlabel_group":        "CATEGORY_FORUMS",
+}
+
+// sizeUnits maps mailFilters.xml sizeUnit values to bytes.
+var sizeUnits = map[string]int64{
+	"s_sb":  1,
+	"s_skb": 1 << 10,
+	"s_smb": 1 << 20,
+}
+
+// parseMailFiltersXML converts a mailFilters.xml feed to filter specs.
+// Unknown properties are returned as warnings rather than failing the import.
+func parseMailFiltersXML(data []byte) ([]filterSpec, []string, error) {
+	var feed mailFiltersFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, nil, fmt.Errorf("parse mailFilters.xml: %w", err)
+	}
+
+	var specs []filterSpec
+	var warnings []string
+	for i, e := range feed.Entries {
+		var s filterSpec
+		var size int64
+		unit := int64(1)
+		for _, p := range e.Properties {
+			v := p.Value
+			isTrue := strings.EqualFold(v, "true")
+			switch p.Name {
+			case "from":
+				s.Criteria.From = v
+			case "to":
+				s.Criteria.To = v
+			case "subject":
+				s.Criteria.Subject = v
+			case "hasTheWord":
+				s.Criteria.Query = v
+			case "doesNotHaveTheWord":
+				s.Criteria.NegatedQuery = v
+			case "hasAttachment":
+				s.Criteria.HasAttachment = isTrue
+			case "excludeChats":
+				s.Criteria.ExcludeChats = isTrue
+			case "size":
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return nil, nil, fmt.Errorf("entry %d: invalid size %q", i+1, v)
+				}
+				size = n
+			case "sizeOperator":
+				switch v {
+				case "s_sl":
+					s.Criteria.SizeComparison = "larger"
+				case "s_ss":
+					s.Criteria.SizeComparison = "smaller"
+				default:
+					return nil, nil, fmt.Errorf("entry %d: invalid sizeOperator %q", i+1, v)
+				}
+			case "sizeUnit":
+				u, ok := sizeUnits[v]
+				if !ok {
+					return nil, nil, fmt.Errorf("entry %d: invalid sizeUnit %q", i+1, v)
+				}
+				unit = u
+			case "label":
+				s.Action.AddLabels = append(s.Action.AddLabels, v)
+			case "smartLabelToApply":
+				label, ok := smartLabels[strings.ToLower(v)]
+				if !ok {
+					warnings = append(warnings, fmt.Sprintf("entry %d: unknown smartLabelToApply %q ignored", i+1, v))
+					continue
+				}
+				s.Action.AddLabels = append(s.Action.AddLabels, label)
+			case "shouldArchive":
+				s.Action.Archive = isTrue
+			case "shouldMarkAsRead":
+				s.Action.MarkRead = isTrue
+			case "shouldStar":
+				s.Action.Star = isTrue
+			case "shouldTrash":
+				s.Action.Trash = isTrue
+			case "shouldNeverSpam":
+				s.Action.NeverSpam = isTrue
+			case "shouldAlwaysMarkAsImportant":
+				s.Action.Important = isTrue
+			case "shouldNeverMarkAsImportant":
+				if isTrue {
+					s.Action.RemoveLabels = append(s.Action.RemoveLabels, "IMPORTANT")
+				}
+			case "forwardTo":
+				s.Action.Forward = v
+			default:
+				warnings = append(warnings, fmt.Sprintf("entry %d: unsupported property %q ignored", i+1, p.Name))
+			}
+		}
+		if size > 0 {
+			s.Criteria.Size = size * unit
+		} else {
+			s.Criteria.SizeComparison = "" // Gmail writes the operator even without a size
+		}
+		if s.Criteria.empty() || s.Action.empty() {
+			warnings = append(warnings, fmt.Sprintf("entry %d: no usable criteria or action, skipped", i+1))
+			continue
+		}
+		specs = append(specs, s)
+	}
+	return specs, warnings, nil
+}
+
+// writeMailFiltersXML renders specs as mailFilters.xml. The format allows one
+// label per filter, so a spec adding several labels becomes several entries;
+// actions with no XML equivalent are returned as warnings.
+func writeMailFiltersXML(w io.Writer, specs []filterSpec, account string, now time.Time) ([]string, error) {
+	var warnings []string
+	var buf bytes.Buffer
+	updated := now.UTC().Format(time.RFC3339)
+
+	buf.WriteString("<?xml version='1.0' encoding='UTF-8'?>\n")
+	fmt.Fprintf(&buf, "<feed xmlns='%s' xmlns:apps='%s'>\n", atomNS, appsNS)
+	buf.WriteString("\t<title>Mail Filters</title>\n")
+	fmt.Fprintf(&buf, "\t<id>tag:mail.google.com,2008:filters:%d</id>\n", now.Unix())
+	fmt.Fprintf(&buf, "\t<updated>%s</updated>\n", updated)
+	if account != "" {
+		fmt.Fprintf(&buf, "\t<author>\n\t\t<name>%s</name>\n\t\t<email>%s</email>\n\t</author>\n", xmlEscape(account), xmlEscape(account))
+	}
+
+	n := 0
+	for i, s := range specs {
+		props, labels, warn := mailFilterProperties(s)
+		for _, msg := range warn {
+			warnings = append(warnings, fmt.Sprintf("filter %d: %s", i+1, msg))
+		}
+		if len(labels) == 0 {
+			labels = []mailFilterProperty{{}}
+		}
+		for j, label := range labels {
+			entryProps := props
+			if j > 0 {
+				entryProps = criteriaProperties(s.Criteria)
+			}
+			if label.Name != "" {
+				entryProps = append(append([]mailFilterProperty(nil), entryProps...), label)
+			}
+			n++
+			buf.WriteString("\t<entry>\n")
+			buf.WriteString("\t\t<category term='filter'></category>\n")
+			buf.WriteString("\t\t<title>Mail Filter</title>\n")
+			fmt.Fprintf(&buf, "\t\t<id>tag:mail.google.com,2008:filter:%d</id>\n", n)
+			fmt.Fprintf(&buf, "\t\t<updated>%s</updated>\n", updated)
+			buf.WriteString("\t\t<content></content>\n")
+			for _, p := range entryProps {
+				fmt.Fprintf(&buf, "\t\t<apps:property name='%s' value='%s'/>\n", p.Name, xmlEscape(p.Value))
+			}
+			buf.WriteString("\t</entry>\n")
+		}
+	}
+	buf.WriteString("</feed>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return warnings, err
+}
+
+func criteriaProperties(c filterCriteriaSpec) []mailFilterProperty {
+	var props []mailFilterProperty
+	add := func(name, value string) {
+		if value != "" {
+			props = append(props, mailFilterProperty{Name: name, Value: value})
+		}
+	}
+	add("from", c.From)
+	add("to", c.To)
+	add("subject", c.Subject)
+	add("hasTheWord", c.Query)
+	add("doesNotHaveTheWord", c.NegatedQuery)
+	if c.HasAttachment {
+		add("hasAttachment", "true")
+	}
+	if c.ExcludeChats {
+		add("excludeChats", "true")
+	}
+	if c.Size > 0 {
+		size, unit := c.Size, "s_sb"
+		switch {
+		case size%(1<<20) == 0:
+			size, unit = size>>20, "s_smb"
+		case size%(1<<10) == 0:
+			size, unit = size>>10, "s_skb"
+		}
+		op := "s_sl"
+		if strings.EqualFold(c.SizeComparison, "smaller") {
+			op = "s_ss"
+		}
+		add("size", strconv.FormatInt(size, 10))
+		add("sizeOperator", op)
+		add("sizeUnit", unit)
+	}
+	return props
+}
+
+// mailFilterProperties returns a spec's criteria + non-label action
+// properties, its label properties (one per entry), and warnings.
+func mailFilterProperties(s filterSpec) ([]mailFilterProperty, []mailFilterProperty, []string) {
+	props := criteriaProperties(s.Criteria)
+	var warnings []string
+	add := func(name string, on bool) {
+		if on {
+			props = append(props, mailFilterProperty{Name: name, Value: "true"})
+		}
+	}
+	a := s.Action
+	add("shouldArchive", a.Archive)
+	add("shouldMarkAsRead", a.MarkRead)
+	add("shouldStar", a.Star)
+	add("shouldTrash", a.Trash)
+	add("shouldNeverSpam", a.NeverSpam)
+	add("shouldAlwaysMarkAsImportant", a.Important)
+	for _, l := range a.RemoveLabels {
+		if strings.EqualFold(l, "IMPORTANT") {
+			add("shouldNeverMarkAsImportant", true)
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("removing label %q has no mailFilters.xml equivalent; skipped", l))
+	}
+	if a.Forward != "" {
+		props = append(props, mailFilterProperty{Name: "forwardTo", Value: a.Forward})
+	}
+
+	var labels []mailFilterProperty
+	for _, l := range a.AddLabels {
+		smart := ""
+		for k, v := range smartLabels {
+			if strings.EqualFold(v, l) {
+				smart = k
+			}
+		}
+		if smart != "" {
+			props = append(props, mailFilterProperty{Name: "smartLabelToApply", Value: smart})
+			continue
+		}
+		labels = append(labels, mailFilterProperty{Name: "label", Value: l})
+	}
+	return props, labels, warnings
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return strings.ReplaceAll(b.String(), "'", "&#39;")
+}
+
+type GmailFiltersImportCmd struct {
+	File   string `arg:"" name:"file" help:"mailFilters.xml exported from Gmail settings, or '-' for stdin"`
+	Prune  bool   `name:"prune" help:"Delete existing filters that are not in the file"`
+	DryRun bool   `name:"dry-run" help:"Show the changes without applying them"`
+}
+
+func (c *GmailFiltersImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	var data []byte
+	var err error
+	if c.File == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(c.File) //nolint:gosec // user-provided path
+	}
+	if err != nil {
+		return err
+	}
+
+	specs, warnings, err := parseMailFiltersXML(data)
+	if err != nil {
+		return newUsageError(err)
+	}
+	for _, w := range warnings {
+		u.Err().Printf("warning: %s", w)
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return applyFilterSpecs(ctx, flags, svc, specs, c.Prune, c.DryRun)
+}
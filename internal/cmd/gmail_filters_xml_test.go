@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleMailFiltersXML = `<?xml version='1.0' encoding='UTF-8'?><feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+	<title>Mail Filters</title>
+	<id>tag:mail.google.com,2008:filters:z0000001687000000000*1234</id>
+	<updated>2024-01-02T03:04:05Z</updated>
+	<author>
+		<name>Me</name>
+		<email>me@example.com</email>
+	</author>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<id>tag:mail.google.com,2008:filter:z0000001687000000000*1234</id>
+		<updated>2024-01-02T03:04:05Z</updated>
+		<content></content>
+		<apps:property name='from' value='noreply@example.com'/>
+		<apps:property name='label' value='Notifications'/>
+		<apps:property name='shouldArchive' value='true'/>
+		<apps:property name='shouldMarkAsRead' value='true'/>
+		<apps:property name='sizeOperator' value='s_sl'/>
+		<apps:property name='sizeUnit' value='s_smb'/>
+	</entry>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<content></content>
+		<apps:property name='hasTheWord' value='list:dev.example.com'/>
+		<apps:property name='size' value='5'/>
+		<apps:property name='sizeOperator' value='s_ss'/>
+		<apps:property name='sizeUnit' value='s_smb'/>
+		<apps:property name='smartLabelToApply' value='^smartlabel_group'/>
+		<apps:property name='shouldNeverMarkAsImportant' value='true'/>
+		<apps:property name='someFutureThing' value='x'/>
+	</entry>
+</feed>`
+
+func TestParseMailFiltersXML(t *testing.T) {
+	specs, warnings, err := parseMailFiltersXML([]byte(sampleMailFiltersXML))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 specs, got %d", len(specs))
+	}
+	first := specs[0]
+	if first.Criteria.From != "noreply@example.com" || !first.Action.Archive || !first.Action.MarkRead {
+		t.Fatalf("unexpected first spec: %+v", first)
+	}
+	if len(first.Action.AddLabels) != 1 || first.Action.AddLabels[0] != "Notifications" {
+		t.Fatalf("unexpected labels: %v", first.Action.AddLabels)
+	}
+	second := specs[1]
+	if second.Criteria.Query != "list:dev.example.com" || second.Criteria.Size != 5<<20 || second.Criteria.SizeComparison != "smaller" {
+		t.Fatalf("unexpected second criteria: %+v", second.Criteria)
+	}
+	if second.Action.AddLabels[0] != "CATEGORY_FORUMS" || second.Action.RemoveLabels[0] != "IMPORTANT" {
+		t.Fatalf("unexpected second action: %+v", second.Action)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "someFutureThing") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestWriteMailFiltersXML_RoundTrip(t *testing.T) {
+	specs := []filterSpec{
+		{
+			Criteria: filterCriteriaSpec{From: "a&b@example.com", Size: 2048, SizeComparison: "larger"},
+			Action:   filterActionSpec{AddLabels: []string{"Work", "Clients/Acme's"}, Star: true},
+		},
+		{
+			Criteria: filterCriteriaSpec{Subject: "promo"},
+			Action:   filterActionSpec{AddLabels: []string{"CATEGORY_PROMOTIONS"}, RemoveLabels: []string{"Custom"}, Trash: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	warnings, err := writeMailFiltersXML(&buf, specs, "me@example.com", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Custom") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	out := buf.String()
+	for _, want := range []string{"<email>me@example.com</email>", "value='a&amp;b@example.com'", "name='sizeUnit' value='s_skb'", "name='smartLabelToApply' value='^smartlabel_promo'"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	back, _, err := parseMailFiltersXML(buf.Bytes())
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	// Two labels on the first spec become two entries.
+	if len(back) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(back), back)
+	}
+	if !back[0].Action.Star || back[0].Action.AddLabels[0] != "Work" || back[0].Criteria.Size != 2048 {
+		t.Fatalf("unexpected first entry: %+v", back[0])
+	}
+	if back[1].Action.Star || back[1].Action.AddLabels[0] != "Clients/Acme's" || back[1].Criteria.From != "a&b@example.com" {
+		t.Fatalf("unexpected split entry: %+v", back[1])
+	}
+	if !back[2].Action.Trash || back[2].Action.AddLabels[0] != "CATEGORY_PROMOTIONS" {
+		t.Fatalf("unexpected third entry: %+v", back[2])
+	}
+}
+
+func TestGmailFiltersImport_DryRun(t *testing.T) {
+	filters, labels := baseFilterFixtures()
+	f := newFakeFilterServer(t, filters, labels)
+
+	path := filepath.Join(t.TempDir(), "mailFilters.xml")
+	if err := os.WriteFile(path, []byte(sampleMailFiltersXML), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersImportCmd{}, []string{path, "--dry-run"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	var parsed struct {
+		Created   int `json:"created"`
+		Unmanaged int `json:"unmanaged"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.Created != 2 || parsed.Unmanaged != 2 {
+		t.Fatalf("unexpected plan: %+v", parsed)
+	}
+	if len(f.filters) != 2 {
+		t.Fatalf("dry run changed filters: %+v", f.filters)
+	}
+}
+
+func TestGmailFiltersExport_XML(t *testing.T) {
+	filters, labels := baseFilterFixtures()
+	newFakeFilterServer(t, filters, labels)
+
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailFiltersExportCmd{}, []string{"--format", "xml"}, testUIContext(t, io.Discard), &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	specs, _, err := parseMailFiltersXML([]byte(out))
+	if err != nil {
+		t.Fatalf("parse export: %v\n%s", err, out)
+	}
+	if len(specs) != 2 || specs[0].Action.AddLabels[0] != "Notifications" || !specs[0].Action.Archive || !specs[1].Action.MarkRead {
+		t.Fatalf("unexpected export: %+v", specs)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"google.golang.org/api/gmail/v1"
 
@@ -40,7 +39,7 @@ func (c *GmailForwardingListCmd) Run(ctx context.Context, flags *RootFlags) erro
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"forwardingAddresses": resp.ForwardingAddresses})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"forwardingAddresses": resp.ForwardingAddresses})
 	}
 
 	if len(resp.ForwardingAddresses) == 0 {
@@ -48,14 +47,14 @@ func (c *GmailForwardingListCmd) Run(ctx context.Context, flags *RootFlags) erro
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "EMAIL\tSTATUS")
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "EMAIL\tSTATUS")
 	for _, f := range resp.ForwardingAddresses {
-		fmt.Fprintf(tw, "%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\n",
 			f.ForwardingEmail,
 			f.VerificationStatus)
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
 
@@ -85,7 +84,7 @@ func (c *GmailForwardingGetCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"forwardingAddress": address})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"forwardingAddress": address})
 	}
 
 	u.Out().Printf("forwarding_email\t%s", address.ForwardingEmail)
@@ -123,7 +122,7 @@ func (c *GmailForwardingCreateCmd) Run(ctx context.Context, flags *RootFlags) er
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"forwardingAddress": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"forwardingAddress": created})
 	}
 
 	u.Out().Println("Forwarding address created successfully")
@@ -160,7 +159,7 @@ func (c *GmailForwardingDeleteCmd) Run(ctx context.Context, flags *RootFlags) er
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"success":         true,
 			"forwardingEmail": forwardingEmail,
 		})
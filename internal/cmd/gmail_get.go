@@ -64,7 +64,7 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"message": msg})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"message": msg})
 	}
 
 	u.Out().Printf("id\t%s", msg.Id)
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// gmailImportRawLimit is the largest message sent inline as base64 "raw";
+// bigger messages go through media upload, which the client switches to a
+// resumable upload for very large ones.
+const gmailImportRawLimit = 1 << 20
+
+type GmailImportCmd struct {
+	Path               string   `arg:"" name:"path" help:"mbox file, Maildir, .eml file or directory of .eml files"`
+	Labels             []string `name:"label" help:"Label to add to every imported message (name or ID; repeatable; created if missing)"`
+	NeverMarkSpam      bool     `name:"never-mark-spam" help:"Never send imported messages to spam"`
+	InternalDateSource string   `name:"internal-date-source" help:"Message date: receivedTime|dateHeader" enum:"receivedTime,dateHeader" default:"receivedTime"`
+}
+
+// gmailImportState remembers what was already imported from a source so an
+// interrupted import resumes without duplicates.
+type gmailImportState struct {
+	Account   string            `json:"account"`
+	Source    string            `json:"source"`
+	Imported  map[string]string `json:"imported"` // message key -> Gmail ID ("" when skipped as an existing duplicate)
+	UpdatedAt time.Time         `json:"updatedAt"`
+
+	path string
+}
+
+func loadGmailImportState(account, source string) (*gmailImportState, error) {
+	dir, err := config.EnsureGmailImportDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(source))
+	path := filepath.Join(dir, sanitizeAccountForPath(account)+"-"+hex.EncodeToString(sum[:8])+".json")
+
+	st := &gmailImportState{Account: account, Source: source, path: path}
+	data, err := os.ReadFile(path) //nolint:gosec // state file in config dir
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("read import state: %w", err)
+		}
+	}
+	if st.Imported == nil {
+		st.Imported = map[string]string{}
+	}
+	return st, nil
+}
+
+func (st *gmailImportState) save() error {
+	st.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, st.path)
+}
+
+func (c *GmailImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	source, err := filepath.Abs(c.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(source); err != nil {
+		return usagef("cannot read %s: %v", c.Path, err)
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	labelIDs, created, err := ensureImportLabels(ctx, svc, c.Labels)
+	if err != nil {
+		return err
+	}
+	for _, name := range created {
+		u.Err().Printf("Created label %s", name)
+	}
+
+	state, err := loadGmailImportState(account, source)
+	if err != nil {
+		return err
+	}
+
+	importCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var imported, skipped, seen int
+	format, importErr := readMailbox(source, func(m mailboxMessage) error {
+		if err := importCtx.Err(); err != nil {
+			return err
+		}
+		seen++
+		messageID, key := importMessageKey(m.Raw)
+		if _, ok := state.Imported[key]; ok {
+			skipped++
+			return nil
+		}
+		if messageID != "" {
+			exists, err := gmailHasMessageID(importCtx, svc, messageID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", m.ID, err)
+			}
+			if exists {
+				state.Imported[key] = ""
+				skipped++
+				return nil
+			}
+		}
+
+		msg, err := c.importMessage(importCtx, svc, m, labelIDs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.ID, err)
+		}
+		state.Imported[key] = msg.Id
+		imported++
+		if imported%25 == 0 {
+			u.Err().Printf("Imported %d (%d skipped)", imported, skipped)
+			return state.save()
+		}
+		return nil
+	})
+	if err := state.save(); err != nil && importErr == nil {
+		importErr = err
+	}
+	if importErr != nil {
+		if importCtx.Err() != nil {
+			u.Err().Printf("Interrupted after %d messages; re-run to resume", imported)
+		}
+		return interruptedOr(importCtx, importErr)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"source":   source,
+			"format":   format,
+			"imported": imported,
+			"skipped":  skipped,
+			"total":    seen,
+			"labels":   c.Labels,
+		})
+	}
+	u.Out().Printf("Imported %d messages from %s (%d skipped as duplicates)", imported, c.Path, skipped)
+	return nil
+}
+
+func (c *GmailImportCmd) importMessage(ctx context.Context, svc *gmail.Service, m mailboxMessage, labelIDs []string) (*gmail.Message, error) {
+	labels := append(append([]string(nil), labelIDs...), m.LabelIDs...)
+	meta := &gmail.Message{LabelIds: labels}
+	large := len(m.Raw) > gmailImportRawLimit
+	if !large {
+		meta.Raw = base64.RawURLEncoding.EncodeToString(m.Raw)
+	}
+
+	call := svc.Users.Messages.Import("me", meta).
+		InternalDateSource(c.InternalDateSource).
+		NeverMarkSpam(c.NeverMarkSpam).
+		Context(ctx)
+	if large {
+		call = call.Media(bytes.NewReader(m.Raw), googleapi.ContentType("message/rfc822"))
+	}
+	return call.Do()
+}
+
+// importMessageKey returns the message's Message-ID (without brackets) and the
+// key used in the resume state: the Message-ID, or a content hash when the
+// message has none.
+func importMessageKey(raw []byte) (string, string) {
+	if msg, err := mail.ReadMessage(bytes.NewReader(raw)); err == nil {
+		id := strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>")
+		if id != "" {
+			return id, "<" + id + ">"
+		}
+	}
+	sum := sha256.Sum256(raw)
+	return "", "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// gmailHasMessageID reports whether the mailbox (including spam and trash)
+// already holds a message with this Message-ID.
+func gmailHasMessageID(ctx context.Context, svc *gmail.Service, messageID string) (bool, error) {
+	resp, err := svc.Users.Messages.List("me").
+		Q("rfc822msgid:" + messageID).
+		IncludeSpamTrash(true).
+		MaxResults(1).
+		Fields("messages(id)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Messages) > 0, nil
+}
+
+// ensureImportLabels resolves label names/IDs, creating user labels that do
+// not exist yet.
+func ensureImportLabels(ctx context.Context, svc *gmail.Service, labels []string) ([]string, []string, error) {
+	if len(labels) == 0 {
+		return nil, nil, nil
+	}
+	nameToID, err := fetchLabelNameToID(svc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ids, created []string
+	for _, name := range labels {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if id, ok := nameToID[strings.ToLower(name)]; ok {
+			ids = appendUnique(ids, id)
+			continue
+		}
+		l, err := svc.Users.Labels.Create("me", &gmail.Label{Name: name}).Context(ctx).Do()
+		if err != nil {
+			return nil, nil, fmt.Errorf("create label %q: %w", name, err)
+		}
+		nameToID[strings.ToLower(name)] = l.Id
+		ids = appendUnique(ids, l.Id)
+		created = append(created, name)
+	}
+	return ids, created, nil
+}
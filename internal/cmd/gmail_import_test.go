@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// fakeImportServer records imports. existing holds Message-IDs already in
+// the mailbox; failOn makes the import of a message with that subject fail.
+type fakeImportServer struct {
+	mu            sync.Mutex
+	existing      map[string]bool
+	failOn        string
+	imports       []gmail.Message
+	uploads       int
+	createdLabels []string
+	queryParams   []string
+}
+
+func newFakeImportServer(t *testing.T) *fakeImportServer {
+	t.Helper()
+
+	f := &fakeImportServer{existing: map[string]bool{}}
+	stubGmailService(t, http.HandlerFunc(f.serve))
+
+	return f
+}
+
+func (f *fakeImportServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/users/me/labels") && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+			{"id": "INBOX", "name": "INBOX", "type": "system"},
+			{"id": "Label_1", "name": "Archive", "type": "user"},
+		}})
+	case strings.HasSuffix(path, "/users/me/labels") && r.Method == http.MethodPost:
+		var l gmail.Label
+		_ = json.NewDecoder(r.Body).Decode(&l)
+		f.createdLabels = append(f.createdLabels, l.Name)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_new", "name": l.Name})
+	case strings.HasSuffix(path, "/users/me/messages") && r.Method == http.MethodGet:
+		q := strings.TrimPrefix(r.URL.Query().Get("q"), "rfc822msgid:")
+		msgs := []map[string]any{}
+		if f.existing[q] {
+			msgs = append(msgs, map[string]any{"id": "old"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"messages": msgs})
+	case strings.HasSuffix(path, "/users/me/messages/import"):
+		f.queryParams = append(f.queryParams, r.URL.RawQuery)
+		var meta gmail.Message
+		var raw []byte
+		if strings.Contains(path, "/upload/") {
+			f.uploads++
+			body, _ := io.ReadAll(r.Body)
+			raw = body // multipart: metadata part + message part
+			if i := strings.Index(string(body), "\r\n\r\n{"); i >= 0 {
+				_ = json.NewDecoder(strings.NewReader(string(body[i+4:]))).Decode(&meta)
+			}
+		} else {
+			_ = json.NewDecoder(r.Body).Decode(&meta)
+			raw, _ = base64.RawURLEncoding.DecodeString(meta.Raw)
+		}
+		if f.failOn != "" && strings.Contains(string(raw), "Subject: "+f.failOn) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "boom"}})
+			return
+		}
+		meta.Raw = ""
+		f.imports = append(f.imports, meta)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "new" + strings.Repeat("x", len(f.imports))})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeTestMaildir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return dir
+}
+
+func runGmailImport(t *testing.T, args ...string) (map[string]any, error) {
+	t.Helper()
+	var runErr error
+	out := captureStdout(t, func() {
+		u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+		if err != nil {
+			t.Fatalf("ui.New: %v", err)
+		}
+		ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+		runErr = runKong(t, &GmailImportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"})
+	})
+	if runErr != nil {
+		return nil, runErr
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	return parsed, nil
+}
+
+func TestGmailImport_MaildirDedupeAndResume(t *testing.T) {
+	withTempConfigHome(t)
+	f := newFakeImportServer(t)
+	f.existing["dup@example.com"] = true
+	f.failOn = "three"
+
+	large := "Message-ID: <big@example.com>\nSubject: big\n\n" + strings.Repeat("x", gmailImportRawLimit) + "\n"
+	dir := writeTestMaildir(t, map[string]string{
+		"new/1.host":      "Message-ID: <one@example.com>\nSubject: one\n\nhi\n",
+		"cur/2.host:2,S":  "Message-ID: <dup@example.com>\nSubject: dup\n\nhi\n",
+		"cur/3.host:2,FS": "Message-ID: <three@example.com>\nSubject: three\n\nhi\n",
+		"cur/4.host:2,S":  large,
+	})
+
+	// The first run stops at "three"; "one" is recorded and not re-sent.
+	if _, err := runGmailImport(t, dir, "--label", "Archive", "--label", "Migrated", "--never-mark-spam", "--internal-date-source", "dateHeader"); err == nil {
+		t.Fatalf("expected failure")
+	}
+	if len(f.imports) != 1 || strings.Join(f.imports[0].LabelIds, ",") != "Label_1,Label_new,UNREAD" {
+		t.Fatalf("unexpected first import: %+v", f.imports)
+	}
+	if len(f.createdLabels) != 1 || f.createdLabels[0] != "Migrated" {
+		t.Fatalf("unexpected created labels: %v", f.createdLabels)
+	}
+	if !strings.Contains(f.queryParams[0], "internalDateSource=dateHeader") || !strings.Contains(f.queryParams[0], "neverMarkSpam=true") {
+		t.Fatalf("unexpected import params: %s", f.queryParams[0])
+	}
+
+	f.failOn = ""
+	res, err := runGmailImport(t, dir, "--label", "Archive")
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if res["imported"] != float64(2) || res["skipped"] != float64(2) || res["total"] != float64(4) || res["format"] != "maildir" {
+		t.Fatalf("unexpected resume result: %v", res)
+	}
+	if len(f.imports) != 3 || f.uploads != 1 {
+		t.Fatalf("expected 3 imports with 1 media upload, got %d/%d", len(f.imports), f.uploads)
+	}
+	if got := strings.Join(f.imports[1].LabelIds, ","); got != "Label_1,STARRED" {
+		t.Fatalf("unexpected flag labels: %s", got)
+	}
+
+	// Everything is recorded now: nothing is imported again.
+	res, err = runGmailImport(t, dir, "--label", "Archive")
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if res["imported"] != float64(0) || res["skipped"] != float64(4) || len(f.imports) != 3 {
+		t.Fatalf("unexpected rerun: %v (imports %d)", res, len(f.imports))
+	}
+}
@@ -15,6 +15,10 @@ import (
 type GmailLabelsCmd struct {
 	List   GmailLabelsListCmd   `cmd:"" name:"list" help:"List labels"`
 	Get    GmailLabelsGetCmd    `cmd:"" name:"get" help:"Get label details (including counts)"`
+	Tree   GmailLabelsTreeCmd   `cmd:"" name:"tree" help:"Show nested labels as a tree with message counts"`
+	Create GmailLabelsCreateCmd `cmd:"" name:"create" help:"Create a label"`
+	Update GmailLabelsUpdateCmd `cmd:"" name:"update" aliases:"rename" help:"Rename or restyle a label (nested labels follow a rename)"`
+	Delete GmailLabelsDeleteCmd `cmd:"" name:"delete" help:"Delete a label"`
 	Modify GmailLabelsModifyCmd `cmd:"" name:"modify" help:"Modify labels on threads"`
 }
 
@@ -51,7 +55,7 @@ func (c *GmailLabelsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"label": l})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"label": l})
 	}
 	u := ui.FromContext(ctx)
 	u.Out().Printf("id\t%s", l.Id)
@@ -83,7 +87,7 @@ func (c *GmailLabelsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"labels": resp.Labels})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"labels": resp.Labels})
 	}
 	if len(resp.Labels) == 0 {
 		u.Err().Println("No labels")
@@ -156,7 +160,7 @@ func (c *GmailLabelsModifyCmd) Run(ctx context.Context, flags *RootFlags) error
 		}
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"results": results})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"results": results})
 	}
 	return nil
 }
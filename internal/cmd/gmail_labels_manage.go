@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// gmailLabelPalette is the fixed set of colors Gmail accepts for label
+// backgrounds and text; any other value is rejected by the API.
+var gmailLabelPalette = strings.Fields(`
+#000000 #434343 #666666 #999999 #cccccc #efefef #f3f3f3 #ffffff
+#fb4c2f #ffad47 #fad165 #16a766 #43d692 #4a86e8 #a479e2 #f691b3
+#f6c5be #ffe6c7 #fef1d1 #b9e4d0 #c6f3de #c9daf8 #e4d7f5 #fcdee8
+#efa093 #ffd6a2 #fce8b3 #89d3b2 #a0eac9 #a4c2f4 #d0bcf1 #fbc8d9
+#e66550 #ffbc6b #fcda83 #44b984 #68dfa9 #6d9eeb #b694e8 #f7a7c0
+#cc3a21 #eaa041 #f2c960 #149e60 #3dc789 #3c78d8 #8e63ce #e07798
+#ac2b16 #cf8933 #d5ae49 #0b804b #2a9c68 #285bac #653e9b #b65775
+#822111 #a46a21 #aa8831 #076239 #1a764d #1c4587 #41236d #83334c
+#464646 #e7e7e7 #0d3472 #b6cff5 #98d7e4 #e3d7ff #fbd3e0 #f2b2a8
+#c2c2c2 #4986e7 #2da2bb #b99aff #994a64 #f691b2 #ff7537 #ffad46
+#662e37 #ebdbde #cca6ac #094228 #42d692 #16a765
+`)
+
+// GmailLabelStyleFlags are the appearance settings shared by create/update.
+type GmailLabelStyleFlags struct {
+	LabelListVisibility   string `name:"label-list-visibility" help:"Show in the label list: labelShow|labelShowIfUnread|labelHide"`
+	MessageListVisibility string `name:"message-list-visibility" help:"Show on messages: show|hide"`
+	BackgroundColor       string `name:"background-color" help:"Background color from Gmail's palette, e.g. #16a766 (requires --text-color)"`
+	TextColor             string `name:"text-color" help:"Text color from Gmail's palette, e.g. #ffffff (requires --background-color)"`
+}
+
+// apply validates the flags and copies the set ones onto l.
+func (f GmailLabelStyleFlags) apply(l *gmail.Label) error {
+	if v := strings.TrimSpace(f.LabelListVisibility); v != "" {
+		switch strings.ToLower(v) {
+		case "labelshow", "show":
+			l.LabelListVisibility = "labelShow"
+		case "labelshowifunread", "showifunread":
+			l.LabelListVisibility = "labelShowIfUnread"
+		case "labelhide", "hide":
+			l.LabelListVisibility = "labelHide"
+		default:
+			return usagef("invalid --label-list-visibility %q (expected labelShow|labelShowIfUnread|labelHide)", v)
+		}
+	}
+	if v := strings.TrimSpace(f.MessageListVisibility); v != "" {
+		switch strings.ToLower(v) {
+		case "show", "hide":
+			l.MessageListVisibility = strings.ToLower(v)
+		default:
+			return usagef("invalid --message-list-visibility %q (expected show|hide)", v)
+		}
+	}
+
+	bg := strings.ToLower(strings.TrimSpace(f.BackgroundColor))
+	fg := strings.ToLower(strings.TrimSpace(f.TextColor))
+	if bg == "" && fg == "" {
+		return nil
+	}
+	if bg == "" || fg == "" {
+		return usage("--background-color and --text-color must be set together")
+	}
+	for _, c := range []string{bg, fg} {
+		if !isGmailLabelColor(c) {
+			return usagef("color %q is not in Gmail's label palette (e.g. #16a766, #4a86e8, #ffffff)", c)
+		}
+	}
+	l.Color = &gmail.LabelColor{BackgroundColor: bg, TextColor: fg}
+	return nil
+}
+
+func isGmailLabelColor(c string) bool {
+	for _, p := range gmailLabelPalette {
+		if p == c {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLabelPath trims each "/"-separated segment of a nested label name.
+func normalizeLabelPath(name string) string {
+	parts := strings.Split(name, "/")
+	out := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+// findLabel looks up a label by ID or (case-insensitive) name in labels.
+func findLabel(labels []*gmail.Label, idOrName string) *gmail.Label {
+	raw := strings.TrimSpace(idOrName)
+	for _, l := range labels {
+		if l.Id == raw {
+			return l
+		}
+	}
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, raw) {
+			return l
+		}
+	}
+	return nil
+}
+
+// childLabels returns the labels nested under name ("name/..."), sorted.
+func childLabels(labels []*gmail.Label, name string) []*gmail.Label {
+	prefix := strings.ToLower(name) + "/"
+	var out []*gmail.Label
+	for _, l := range labels {
+		if strings.HasPrefix(strings.ToLower(l.Name), prefix) {
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type GmailLabelsCreateCmd struct {
+	Name    string `arg:"" name:"name" help:"Label name (use Parent/Child for nesting)"`
+	Parents bool   `name:"parents" help:"Also create missing parent labels"`
+	GmailLabelStyleFlags
+}
+
+func (c *GmailLabelsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name := normalizeLabelPath(c.Name)
+	if name == "" {
+		return usage("empty label name")
+	}
+
+	label := &gmail.Label{Name: name}
+	if err := c.apply(label); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	var created []*gmail.Label
+	if c.Parents {
+		resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(name, "/")
+		for i := 1; i < len(parts); i++ {
+			parent := strings.Join(parts[:i], "/")
+			if findLabel(resp.Labels, parent) != nil {
+				continue
+			}
+			l, err := svc.Users.Labels.Create("me", &gmail.Label{Name: parent}).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("create parent %q: %w", parent, err)
+			}
+			created = append(created, l)
+		}
+	}
+
+	l, err := svc.Users.Labels.Create("me", label).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	created = append(created, l)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"label": l, "created": created})
+	}
+	for _, p := range created[:len(created)-1] {
+		u.Err().Printf("Created parent %s (%s)", p.Name, p.Id)
+	}
+	u.Out().Printf("id\t%s", l.Id)
+	u.Out().Printf("name\t%s", l.Name)
+	return nil
+}
+
+type GmailLabelsUpdateCmd struct {
+	Label      string `arg:"" name:"labelIdOrName" help:"Label ID or name"`
+	Name       string `name:"name" help:"New name: a plain name renames in place (keeps the parent); a name with / is the full new path"`
+	NoChildren bool   `name:"no-children" help:"Do not rename nested labels along with the parent"`
+	GmailLabelStyleFlags
+}
+
+func (c *GmailLabelsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	raw := strings.TrimSpace(c.Label)
+	if raw == "" {
+		return usage("empty label")
+	}
+
+	patch := &gmail.Label{}
+	if err := c.apply(patch); err != nil {
+		return err
+	}
+	newName := normalizeLabelPath(c.Name)
+	if c.Name != "" && newName == "" {
+		return usage("empty --name")
+	}
+	if newName == "" && patch.LabelListVisibility == "" && patch.MessageListVisibility == "" && patch.Color == nil {
+		return usage("nothing to update (set --name, visibility or colors)")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	label := findLabel(resp.Labels, raw)
+	if label == nil {
+		return usagef("label not found: %s", raw)
+	}
+	if label.Type == "system" {
+		return usagef("cannot modify system label %s", label.Id)
+	}
+
+	type renamed struct {
+		ID      string `json:"id"`
+		OldName string `json:"oldName"`
+		NewName string `json:"newName"`
+	}
+	var renames []renamed
+
+	if newName != "" {
+		// A plain name renames the leaf and keeps the label under its parent.
+		if !strings.Contains(newName, "/") {
+			if i := strings.LastIndex(label.Name, "/"); i >= 0 {
+				newName = label.Name[:i+1] + newName
+			}
+		}
+		if newName != label.Name {
+			if other := findLabel(resp.Labels, newName); other != nil && other.Id != label.Id {
+				return usagef("label %q already exists", other.Name)
+			}
+			patch.Name = newName
+		}
+	}
+
+	// Check every nested name up front so a collision cannot leave the
+	// hierarchy half renamed.
+	var children []renamed
+	if patch.Name != "" && !c.NoChildren {
+		for _, child := range childLabels(resp.Labels, label.Name) {
+			childName := patch.Name + child.Name[len(label.Name):]
+			if other := findLabel(resp.Labels, childName); other != nil && other.Id != child.Id {
+				return usagef("label %q already exists (nested label %q would be renamed to it)", other.Name, child.Name)
+			}
+			children = append(children, renamed{ID: child.Id, OldName: child.Name, NewName: childName})
+		}
+	}
+
+	updated, err := svc.Users.Labels.Patch("me", label.Id, patch).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if patch.Name != "" {
+		renames = append(renames, renamed{ID: label.Id, OldName: label.Name, NewName: updated.Name})
+	}
+
+	for _, child := range children {
+		if _, err := svc.Users.Labels.Patch("me", child.ID, &gmail.Label{Name: child.NewName}).Context(ctx).Do(); err != nil {
+			done := make([]string, 0, len(renames))
+			for _, r := range renames {
+				done = append(done, r.OldName+" -> "+r.NewName)
+			}
+			return fmt.Errorf("rename nested label %q: %w (already renamed: %s)", child.OldName, err, strings.Join(done, ", "))
+		}
+		renames = append(renames, child)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"label": updated, "renamed": renames})
+	}
+	u.Out().Printf("id\t%s", updated.Id)
+	u.Out().Printf("name\t%s", updated.Name)
+	if len(renames) > 1 {
+		for _, r := range renames[1:] {
+			u.Err().Printf("Renamed %s -> %s", r.OldName, r.NewName)
+		}
+	}
+	return nil
+}
+
+type GmailLabelsDeleteCmd struct {
+	Label string `arg:"" name:"labelIdOrName" help:"Label ID or name"`
+}
+
+func (c *GmailLabelsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	raw := strings.TrimSpace(c.Label)
+	if raw == "" {
+		return usage("empty label")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	label := findLabel(resp.Labels, raw)
+	if label == nil {
+		return usagef("label not found: %s", raw)
+	}
+	if label.Type == "system" {
+		return usagef("cannot delete system label %s", label.Id)
+	}
+
+	if err := confirmDestructive(ctx, flags, fmt.Sprintf("delete label %s", label.Name)); err != nil {
+		return err
+	}
+
+	if err := svc.Users.Labels.Delete("me", label.Id).Context(ctx).Do(); err != nil {
+		return err
+	}
+
+	children := childLabels(resp.Labels, label.Name)
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"deleted":    true,
+			"id":         label.Id,
+			"name":       label.Name,
+			"keptNested": len(children),
+		})
+	}
+	u.Out().Printf("deleted\ttrue")
+	u.Out().Printf("id\t%s", label.Id)
+	u.Out().Printf("name\t%s", label.Name)
+	if len(children) > 0 {
+		u.Err().Printf("Kept %d nested labels (delete them separately)", len(children))
+	}
+	return nil
+}
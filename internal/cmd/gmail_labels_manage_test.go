@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// fakeLabelServer is an in-memory Users.Labels backend.
+type fakeLabelServer struct {
+	mu      sync.Mutex
+	labels  map[string]*gmail.Label
+	nextID  int
+	deleted []string
+	failID  string // PATCH of this label fails
+}
+
+func newFakeLabelServer(t *testing.T, labels ...*gmail.Label) *fakeLabelServer {
+	t.Helper()
+
+	f := &fakeLabelServer{labels: map[string]*gmail.Label{}, nextID: 100}
+	for _, l := range labels {
+		f.labels[l.Id] = l
+	}
+
+	stubGmailService(t, http.HandlerFunc(f.serve))
+
+	return f
+}
+
+func (f *fakeLabelServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	const base = "/users/me/labels"
+	i := strings.Index(r.URL.Path, base)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path[i+len(base):], "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		list := make([]*gmail.Label, 0, len(f.labels))
+		for _, l := range f.labels {
+			list = append(list, &gmail.Label{Id: l.Id, Name: l.Name, Type: l.Type})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": list})
+	case id == "" && r.Method == http.MethodPost:
+		var l gmail.Label
+		_ = json.NewDecoder(r.Body).Decode(&l)
+		f.nextID++
+		l.Id = fmt.Sprintf("Label_%d", f.nextID)
+		l.Type = "user"
+		f.labels[l.Id] = &l
+		_ = json.NewEncoder(w).Encode(&l)
+	case r.Method == http.MethodGet:
+		l, ok := f.labels[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(l)
+	case r.Method == http.MethodPatch:
+		l, ok := f.labels[id]
+		if !ok || id == f.failID {
+			http.NotFound(w, r)
+			return
+		}
+		var patch gmail.Label
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		if patch.Name != "" {
+			l.Name = patch.Name
+		}
+		if patch.Color != nil {
+			l.Color = patch.Color
+		}
+		_ = json.NewEncoder(w).Encode(l)
+	case r.Method == http.MethodDelete:
+		delete(f.labels, id)
+		f.deleted = append(f.deleted, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeLabelServer) names() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := map[string]string{}
+	for id, l := range f.labels {
+		out[id] = l.Name
+	}
+	return out
+}
+
+func TestGmailLabelsCreate_ParentsAndColors(t *testing.T) {
+	f := newFakeLabelServer(t, &gmail.Label{Id: "INBOX", Name: "INBOX", Type: "system"})
+
+	flags := &RootFlags{Account: "a@b.com"}
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailLabelsCreateCmd{}, []string{
+			"Clients / Acme", "--parents",
+			"--background-color", "#16A766", "--text-color", "#ffffff",
+			"--label-list-visibility", "labelShowIfUnread",
+		}, testJSONContext(t, io.Discard), flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	var parsed struct {
+		Label   gmail.Label   `json:"label"`
+		Created []gmail.Label `json:"created"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Created) != 2 || parsed.Created[0].Name != "Clients" || parsed.Label.Name != "Clients/Acme" {
+		t.Fatalf("unexpected created labels: %+v", parsed.Created)
+	}
+	if parsed.Label.Color == nil || parsed.Label.Color.BackgroundColor != "#16a766" || parsed.Label.LabelListVisibility != "labelShowIfUnread" {
+		t.Fatalf("unexpected label style: %+v", parsed.Label)
+	}
+	if len(f.names()) != 3 {
+		t.Fatalf("unexpected labels: %v", f.names())
+	}
+}
+
+func TestGmailLabelsCreate_InvalidColor(t *testing.T) {
+	newFakeLabelServer(t)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	err := runKong(t, &GmailLabelsCreateCmd{}, []string{"X", "--background-color", "#123456", "--text-color", "#ffffff"}, testJSONContext(t, io.Discard), flags)
+	if err == nil || !strings.Contains(err.Error(), "palette") {
+		t.Fatalf("expected palette error, got %v", err)
+	}
+	err = runKong(t, &GmailLabelsCreateCmd{}, []string{"X", "--background-color", "#16a766"}, testJSONContext(t, io.Discard), flags)
+	if err == nil || !strings.Contains(err.Error(), "set together") {
+		t.Fatalf("expected paired color error, got %v", err)
+	}
+}
+
+func TestGmailLabelsUpdate_RenameCascades(t *testing.T) {
+	f := newFakeLabelServer(t,
+		&gmail.Label{Id: "Label_1", Name: "Work", Type: "user"},
+		&gmail.Label{Id: "Label_2", Name: "Work/Clients", Type: "user"},
+		&gmail.Label{Id: "Label_3", Name: "Work/Clients/Acme", Type: "user"},
+		&gmail.Label{Id: "Label_4", Name: "Workshop", Type: "user"},
+	)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailLabelsUpdateCmd{}, []string{"work/clients", "--name", "Customers"}, testJSONContext(t, io.Discard), flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	names := f.names()
+	if names["Label_2"] != "Work/Customers" || names["Label_3"] != "Work/Customers/Acme" {
+		t.Fatalf("unexpected rename: %v", names)
+	}
+	if names["Label_1"] != "Work" || names["Label_4"] != "Workshop" {
+		t.Fatalf("unrelated labels changed: %v", names)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailLabelsUpdateCmd{}, []string{"Label_1", "--name", "Archive/Work", "--no-children"}, testJSONContext(t, io.Discard), flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	names = f.names()
+	if names["Label_1"] != "Archive/Work" || names["Label_2"] != "Work/Customers" {
+		t.Fatalf("unexpected move: %v", names)
+	}
+}
+
+func TestGmailLabelsUpdate_NestedCollision(t *testing.T) {
+	f := newFakeLabelServer(t,
+		&gmail.Label{Id: "Label_1", Name: "Work", Type: "user"},
+		&gmail.Label{Id: "Label_2", Name: "Work/Clients", Type: "user"},
+		&gmail.Label{Id: "Label_3", Name: "Archive/Clients", Type: "user"},
+	)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	err := runKong(t, &GmailLabelsUpdateCmd{}, []string{"Work", "--name", "Archive"}, testJSONContext(t, io.Discard), flags)
+	if err == nil || !strings.Contains(err.Error(), `"Archive/Clients" already exists`) {
+		t.Fatalf("expected nested collision error, got %v", err)
+	}
+	if names := f.names(); names["Label_1"] != "Work" || names["Label_2"] != "Work/Clients" {
+		t.Fatalf("labels changed despite collision: %v", names)
+	}
+
+	// A failure partway reports what was already renamed.
+	f.failID = "Label_2"
+	err = runKong(t, &GmailLabelsUpdateCmd{}, []string{"Work", "--name", "Jobs"}, testJSONContext(t, io.Discard), flags)
+	if err == nil || !strings.Contains(err.Error(), "already renamed: Work -> Jobs") {
+		t.Fatalf("expected partial rename report, got %v", err)
+	}
+}
+
+func TestGmailLabelsUpdate_SystemLabel(t *testing.T) {
+	newFakeLabelServer(t, &gmail.Label{Id: "INBOX", Name: "INBOX", Type: "system"})
+
+	flags := &RootFlags{Account: "a@b.com"}
+	err := runKong(t, &GmailLabelsUpdateCmd{}, []string{"INBOX", "--name", "Box"}, testJSONContext(t, io.Discard), flags)
+	if err == nil || !strings.Contains(err.Error(), "system label") {
+		t.Fatalf("expected system label error, got %v", err)
+	}
+}
+
+func TestGmailLabelsDelete_Confirm(t *testing.T) {
+	f := newFakeLabelServer(t, &gmail.Label{Id: "Label_1", Name: "Old", Type: "user"})
+
+	err := runKong(t, &GmailLabelsDeleteCmd{}, []string{"Old"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com", NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "without --force") {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailLabelsDeleteCmd{}, []string{"Old"}, testJSONContext(t, io.Discard), &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+	if len(f.deleted) != 1 || f.deleted[0] != "Label_1" {
+		t.Fatalf("unexpected deletes: %v", f.deleted)
+	}
+}
+
+func TestGmailLabelsTree_JSON(t *testing.T) {
+	newFakeLabelServer(t,
+		&gmail.Label{Id: "INBOX", Name: "INBOX", Type: "system", MessagesTotal: 9},
+		&gmail.Label{Id: "Label_1", Name: "Work", Type: "user", MessagesTotal: 3, MessagesUnread: 1},
+		&gmail.Label{Id: "Label_2", Name: "Work/Clients", Type: "user", MessagesTotal: 2},
+		&gmail.Label{Id: "Label_3", Name: "Projects/Alpha", Type: "user", MessagesUnread: 4},
+	)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailLabelsTreeCmd{}, []string{}, testJSONContext(t, io.Discard), flags); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	var parsed struct {
+		Labels []labelNode `json:"labels"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Labels) != 2 {
+		t.Fatalf("expected 2 roots, got %+v", parsed.Labels)
+	}
+	projects, work := parsed.Labels[0], parsed.Labels[1]
+	if projects.Name != "Projects" || projects.ID != "" || len(projects.Children) != 1 || projects.Children[0].MessagesUnread != 4 {
+		t.Fatalf("unexpected synthesized parent: %+v", projects)
+	}
+	if work.Name != "Work" || work.MessagesTotal != 3 || len(work.Children) != 1 || work.Children[0].Path != "Work/Clients" {
+		t.Fatalf("unexpected work node: %+v", work)
+	}
+}
+
+func TestBuildLabelTree_SystemFirst(t *testing.T) {
+	roots := buildLabelTree([]*gmail.Label{
+		{Id: "Label_1", Name: "a", Type: "user"},
+		{Id: "INBOX", Name: "INBOX", Type: "system"},
+	})
+	if len(roots) != 2 || roots[0].ID != "INBOX" {
+		t.Fatalf("unexpected order: %+v", roots)
+	}
+}
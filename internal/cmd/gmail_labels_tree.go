@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailLabelsTreeCmd struct {
+	System bool `name:"system" help:"Include system labels (INBOX, SENT, ...)"`
+}
+
+// labelNode is one level of the label hierarchy. Intermediate levels without
+// a label of their own (e.g. "A" when only "A/B" exists) have no ID.
+type labelNode struct {
+	Name           string       `json:"name"`
+	Path           string       `json:"path"`
+	ID             string       `json:"id,omitempty"`
+	Type           string       `json:"type,omitempty"`
+	MessagesTotal  int64        `json:"messagesTotal"`
+	MessagesUnread int64        `json:"messagesUnread"`
+	Children       []*labelNode `json:"children,omitempty"`
+}
+
+func (c *GmailLabelsTreeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	labels := make([]*gmail.Label, 0, len(resp.Labels))
+	for _, l := range resp.Labels {
+		if l.Type == "system" && !c.System {
+			continue
+		}
+		labels = append(labels, l)
+	}
+
+	// Labels.List omits counts; fetch each label for them.
+	detailed, err := fetchLabelDetails(ctx, svc, labels)
+	if err != nil {
+		return err
+	}
+	roots := buildLabelTree(detailed)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"labels": roots})
+	}
+	if len(roots) == 0 {
+		ui.FromContext(ctx).Err().Println("No labels")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "NAME\tMESSAGES\tUNREAD\tID")
+	var walk func(nodes []*labelNode, depth int)
+	walk = func(nodes []*labelNode, depth int) {
+		for _, n := range nodes {
+			fmt.Fprintf(w, "%s%s\t%d\t%d\t%s\n", strings.Repeat("  ", depth), sanitizeTab(n.Name), n.MessagesTotal, n.MessagesUnread, n.ID)
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(roots, 0)
+	return nil
+}
+
+func fetchLabelDetails(ctx context.Context, svc *gmail.Service, labels []*gmail.Label) ([]*gmail.Label, error) {
+	const maxConcurrency = 10 // Limit parallel requests to avoid rate limiting
+	sem := make(chan struct{}, maxConcurrency)
+
+	out := make([]*gmail.Label, len(labels))
+	errs := make([]error, len(labels))
+	var wg sync.WaitGroup
+	for i, l := range labels {
+		wg.Add(1)
+		go func(idx int, id string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			out[idx], errs[idx] = svc.Users.Labels.Get("me", id).Context(ctx).Do()
+		}(i, l.Id)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// buildLabelTree nests labels by their "/"-separated names. Siblings are
+// sorted case-insensitively; system labels come first.
+func buildLabelTree(labels []*gmail.Label) []*labelNode {
+	var roots []*labelNode
+	index := map[string]*labelNode{}
+
+	node := func(path string) *labelNode {
+		key := strings.ToLower(path)
+		if n, ok := index[key]; ok {
+			return n
+		}
+		parts := strings.Split(path, "/")
+		n := &labelNode{Name: parts[len(parts)-1], Path: path}
+		index[key] = n
+		return n
+	}
+
+	for _, l := range labels {
+		if l == nil || l.Name == "" {
+			continue
+		}
+		path := l.Name
+		if l.Type != "system" {
+			path = normalizeLabelPath(l.Name)
+		}
+		n := node(path)
+		n.ID = l.Id
+		n.Type = l.Type
+		n.MessagesTotal = l.MessagesTotal
+		n.MessagesUnread = l.MessagesUnread
+	}
+
+	// Synthesize missing intermediate levels, then link children to parents.
+	nodes := make([]*labelNode, 0, len(index))
+	for _, n := range index {
+		nodes = append(nodes, n)
+	}
+	for _, n := range nodes {
+		if n.Type == "system" {
+			continue
+		}
+		for p := n.Path; strings.Contains(p, "/"); {
+			p = p[:strings.LastIndex(p, "/")]
+			node(p)
+		}
+	}
+	for _, n := range index {
+		if n.Type == "system" || !strings.Contains(n.Path, "/") {
+			roots = append(roots, n)
+			continue
+		}
+		parent := index[strings.ToLower(n.Path[:strings.LastIndex(n.Path, "/")])]
+		parent.Children = append(parent.Children, n)
+	}
+
+	sortLabelNodes(roots)
+	return roots
+}
+
+func sortLabelNodes(nodes []*labelNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		si, sj := nodes[i].Type == "system", nodes[j].Type == "system"
+		if si != sj {
+			return si
+		}
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, n := range nodes {
+		sortLabelNodes(n.Children)
+	}
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	mailboxFormatMbox    = "mbox"
+	mailboxFormatMaildir = "maildir"
+	mailboxFormatEML     = "eml"
+
+	mboxFileName = "messages.mbox"
+)
+
+// mailboxMessage is one RFC 822 message plus the Gmail metadata the local
+// formats can carry (mbox From_ date, Maildir flags).
+type mailboxMessage struct {
+	ID           string
+	Raw          []byte
+	LabelIDs     []string
+	InternalDate time.Time
+}
+
+// mailboxWriter appends messages to a local mailbox.
+type mailboxWriter interface {
+	Write(m mailboxMessage) error
+	Close() error
+}
+
+func newMailboxWriter(format, dir string) (mailboxWriter, error) {
+	switch format {
+	case mailboxFormatMbox:
+		return newMboxWriter(filepath.Join(dir, mboxFileName))
+	case mailboxFormatMaildir:
+		return newMaildirWriter(dir)
+	case mailboxFormatEML:
+		return &emlWriter{dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailbox format %q", format)
+	}
+}
+
+// mboxWriter writes mboxrd: each message starts with a "From " line and any
+// body line matching ^>*From  gets one more ">" so readers can undo it.
+type mboxWriter struct {
+	f *os.File
+}
+
+func newMboxWriter(path string) (*mboxWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // user-provided output dir
+	if err != nil {
+		return nil, err
+	}
+	return &mboxWriter{f: f}, nil
+}
+
+func (w *mboxWriter) Write(m mailboxMessage) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", mboxSender(m.Raw), m.InternalDate.UTC().Format(time.ANSIC))
+	buf.Write(mboxQuote(m.Raw))
+	buf.WriteString("\n")
+	// One write per message keeps an interrupted export from splicing messages.
+	_, err := w.f.Write(buf.Bytes())
+	return err
+}
+
+func (w *mboxWriter) Close() error { return w.f.Close() }
+
+// mboxSender returns the envelope sender for the From_ line.
+func mboxSender(raw []byte) string {
+	if msg, err := mail.ReadMessage(bytes.NewReader(raw)); err == nil {
+		for _, h := range []string{"Return-Path", "Sender", "From"} {
+			if addr, err := mail.ParseAddress(msg.Header.Get(h)); err == nil && addr.Address != "" {
+				return addr.Address
+			}
+		}
+	}
+	return "MAILER-DAEMON"
+}
+
+// mboxQuote normalizes line endings to LF, applies mboxrd From_ quoting and
+// ensures the message ends with a newline.
+func mboxQuote(raw []byte) []byte {
+	raw = bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	var buf bytes.Buffer
+	buf.Grow(len(raw) + 64)
+	for _, line := range lines {
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			buf.WriteByte('>')
+		}
+		buf.Write(line)
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// maildirWriter delivers into cur/ with flags derived from Gmail labels,
+// writing through tmp/ so readers never see partial files.
+type maildirWriter struct {
+	dir string
+}
+
+func newMaildirWriter(dir string) (*maildirWriter, error) {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return &maildirWriter{dir: dir}, nil
+}
+
+func (w *maildirWriter) Write(m mailboxMessage) error {
+	name := fmt.Sprintf("%d.%s.gog", m.InternalDate.Unix(), m.ID)
+	tmp := filepath.Join(w.dir, "tmp", name)
+	if err := os.WriteFile(tmp, m.Raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(w.dir, "cur", name+":2,"+maildirFlags(m.LabelIDs)))
+}
+
+func (w *maildirWriter) Close() error { return nil }
+
+// maildirFlags maps Gmail labels to Maildir info flags (ASCII-sorted):
+// D draft, F starred, S read, T trash.
+func maildirFlags(labels []string) string {
+	has := map[string]bool{}
+	for _, l := range labels {
+		has[l] = true
+	}
+	var flags []string
+	if has["DRAFT"] {
+		flags = append(flags, "D")
+	}
+	if has["STARRED"] {
+		flags = append(flags, "F")
+	}
+	if !has["UNREAD"] {
+		flags = append(flags, "S")
+	}
+	if has["TRASH"] {
+		flags = append(flags, "T")
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, "")
+}
+
+// emlWriter writes one <id>.eml file per message.
+type emlWriter struct {
+	dir string
+}
+
+func (w *emlWriter) Write(m mailboxMessage) error {
+	path := filepath.Join(w.dir, m.ID+".eml")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, m.Raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (w *emlWriter) Close() error { return nil }
+
+// readMailbox calls fn for every message under path and returns the detected
+// format: a Maildir (a directory with cur/ or new/), a directory of .eml
+// files, an .eml file, or an mbox file. Message IDs are source locations
+// ("cur/<file>", "messages.mbox#3"); LabelIDs carry UNREAD/STARRED derived
+// from Maildir flags or mbox Status/X-Status headers.
+func readMailbox(path string, fn func(mailboxMessage) error) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		if isMaildir(path) {
+			return mailboxFormatMaildir, readMaildir(path, fn)
+		}
+		return mailboxFormatEML, readEMLDir(path, fn)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".eml") {
+		return mailboxFormatEML, readEMLFile(path, filepath.Base(path), fn)
+	}
+	f, err := os.Open(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return mailboxFormatMbox, readMbox(f, filepath.Base(path), fn)
+}
+
+func isMaildir(dir string) bool {
+	for _, sub := range []string{"cur", "new"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+func readMaildir(dir string, fn func(mailboxMessage) error) error {
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			raw, err := os.ReadFile(filepath.Join(dir, sub, e.Name())) //nolint:gosec // user-provided dir
+			if err != nil {
+				return err
+			}
+			// new/ holds undelivered mail without an info suffix: unread.
+			flags := ""
+			if i := strings.LastIndex(e.Name(), ":2,"); i >= 0 && sub == "cur" {
+				flags = e.Name()[i+3:]
+			}
+			if err := fn(mailboxMessage{ID: sub + "/" + e.Name(), Raw: raw, LabelIDs: labelsFromMaildirFlags(flags)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// labelsFromMaildirFlags is the inverse of maildirFlags for the flags Gmail
+// import can apply.
+func labelsFromMaildirFlags(flags string) []string {
+	var labels []string
+	if !strings.Contains(flags, "S") {
+		labels = append(labels, "UNREAD")
+	}
+	if strings.Contains(flags, "F") {
+		labels = append(labels, "STARRED")
+	}
+	return labels
+}
+
+func readEMLDir(dir string, fn func(mailboxMessage) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".eml") {
+			continue
+		}
+		if err := readEMLFile(filepath.Join(dir, e.Name()), e.Name(), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEMLFile(path, id string, fn func(mailboxMessage) error) error {
+	raw, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+	return fn(mailboxMessage{ID: id, Raw: raw, LabelIDs: mboxStatusLabels(raw)})
+}
+
+// readMbox splits an mbox on "From " lines and undoes mboxrd quoting. The
+// blank line before each separator belongs to the separator, not the message.
+func readMbox(r io.Reader, name string, fn func(mailboxMessage) error) error {
+	br := bufio.NewReaderSize(r, 64<<10)
+	var cur bytes.Buffer
+	n := 0
+	inMessage := false
+	flush := func() error {
+		if !inMessage {
+			return nil
+		}
+		raw := bytes.TrimSuffix(cur.Bytes(), []byte("\n"))
+		raw = append([]byte(nil), raw...)
+		cur.Reset()
+		n++
+		return fn(mailboxMessage{ID: fmt.Sprintf("%s#%d", name, n), Raw: raw, LabelIDs: mboxStatusLabels(raw)})
+	}
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case bytes.HasPrefix(line, []byte("From ")):
+				if err := flush(); err != nil {
+					return err
+				}
+				inMessage = true
+			case !inMessage:
+				if len(bytes.TrimSpace(line)) > 0 {
+					return errors.New("not an mbox file (expected a \"From \" line first)")
+				}
+			default:
+				if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+					line = line[1:]
+				}
+				cur.Write(line)
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return flush()
+			}
+			return err
+		}
+	}
+}
+
+// mboxStatusLabels maps the Status (R = read) and X-Status (F = flagged)
+// headers written by mbox clients to UNREAD/STARRED. Without a Status header
+// the read state is unknown and the message is imported as read.
+func mboxStatusLabels(raw []byte) []string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	status, ok := msg.Header["Status"]
+	flags := ""
+	if !ok || strings.Contains(strings.Join(status, ""), "R") {
+		flags += "S"
+	}
+	if strings.Contains(msg.Header.Get("X-Status"), "F") {
+		flags += "F"
+	}
+	return labelsFromMaildirFlags(flags)
+}
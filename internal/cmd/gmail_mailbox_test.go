@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMboxQuote(t *testing.T) {
+	raw := "Subject: x\r\n\r\nFrom the start\r\n>From quoted\r\nnot From here\r\nend"
+	got := string(mboxQuote([]byte(raw)))
+	want := "Subject: x\n\n>From the start\n>>From quoted\nnot From here\nend\n"
+	if got != want {
+		t.Fatalf("mboxQuote:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestMboxWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.mbox")
+	w, err := newMboxWriter(path)
+	if err != nil {
+		t.Fatalf("newMboxWriter: %v", err)
+	}
+	date := time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC)
+	if err := w.Write(mailboxMessage{ID: "m1", Raw: []byte("From: Alice <alice@example.com>\r\nSubject: hi\r\n\r\nbody\r\n"), InternalDate: date}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Write(mailboxMessage{ID: "m2", Raw: []byte("Subject: no sender\r\n\r\nFrom me\r\n"), InternalDate: date}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "From alice@example.com Tue Mar  5 07:08:09 2024\nFrom: Alice <alice@example.com>\nSubject: hi\n\nbody\n\n" +
+		"From MAILER-DAEMON Tue Mar  5 07:08:09 2024\nSubject: no sender\n\n>From me\n\n"
+	if string(data) != want {
+		t.Fatalf("mbox:\n got %q\nwant %q", data, want)
+	}
+}
+
+func TestMaildirWriter_Flags(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newMaildirWriter(dir)
+	if err != nil {
+		t.Fatalf("newMaildirWriter: %v", err)
+	}
+	date := time.Unix(1700000000, 0)
+	if err := w.Write(mailboxMessage{ID: "m1", Raw: []byte("Subject: a\r\n\r\n"), LabelIDs: []string{"INBOX", "STARRED"}, InternalDate: date}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Write(mailboxMessage{ID: "m2", Raw: []byte("Subject: b\r\n\r\n"), LabelIDs: []string{"UNREAD", "TRASH"}, InternalDate: date}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cur"))
+	if err != nil {
+		t.Fatalf("readdir: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	got := strings.Join(names, " ")
+	if got != "1700000000.m1.gog:2,FS 1700000000.m2.gog:2,T" {
+		t.Fatalf("unexpected maildir files: %s", got)
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Fatalf("tmp not empty: %v", tmp)
+	}
+}
+
+func TestMaildirFlags(t *testing.T) {
+	if got := maildirFlags([]string{"DRAFT", "UNREAD"}); got != "D" {
+		t.Fatalf("draft: %q", got)
+	}
+	if got := maildirFlags(nil); got != "S" {
+		t.Fatalf("read: %q", got)
+	}
+}
+
+func TestReadMbox_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.mbox")
+	w, err := newMboxWriter(path)
+	if err != nil {
+		t.Fatalf("newMboxWriter: %v", err)
+	}
+	msgs := []string{
+		"From: a@example.com\nStatus: RO\nX-Status: F\nSubject: one\n\nFrom the top\n>From quoted\n",
+		"From: b@example.com\nStatus: O\nSubject: two\n\nbody\n",
+		"From: c@example.com\nSubject: three\n\nno status\n",
+	}
+	for i, raw := range msgs {
+		if err := w.Write(mailboxMessage{ID: strings.Repeat("m", i+1), Raw: []byte(raw), InternalDate: time.Unix(0, 0)}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	var got []mailboxMessage
+	format, err := readMailbox(path, func(m mailboxMessage) error {
+		got = append(got, m)
+		return nil
+	})
+	if err != nil || format != mailboxFormatMbox {
+		t.Fatalf("readMailbox: %q %v", format, err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(got))
+	}
+	for i, m := range got {
+		if string(m.Raw) != msgs[i] {
+			t.Fatalf("message %d:\n got %q\nwant %q", i, m.Raw, msgs[i])
+		}
+	}
+	if got[0].ID != "messages.mbox#1" || strings.Join(got[0].LabelIDs, ",") != "STARRED" {
+		t.Fatalf("unexpected first message: %+v", got[0])
+	}
+	if strings.Join(got[1].LabelIDs, ",") != "UNREAD" || len(got[2].LabelIDs) != 0 {
+		t.Fatalf("unexpected labels: %v %v", got[1].LabelIDs, got[2].LabelIDs)
+	}
+}
+
+func TestReadMbox_NotMbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := readMailbox(path, func(mailboxMessage) error { return nil }); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestReadMaildir(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	files := map[string]string{
+		"new/1.a.host":      "Subject: new\n\n",
+		"cur/2.b.host:2,FS": "Subject: starred\n\n",
+		"cur/3.c.host:2,":   "Subject: unread\n\n",
+		"tmp/4.d.host":      "Subject: partial\n\n",
+		"cur/.hidden":       "junk",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	got := map[string]string{}
+	format, err := readMailbox(dir, func(m mailboxMessage) error {
+		got[m.ID] = strings.Join(m.LabelIDs, ",")
+		return nil
+	})
+	if err != nil || format != mailboxFormatMaildir {
+		t.Fatalf("readMailbox: %q %v", format, err)
+	}
+	want := map[string]string{
+		"new/1.a.host":      "UNREAD",
+		"cur/2.b.host:2,FS": "STARRED",
+		"cur/3.c.host:2,":   "UNREAD",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected messages: %v", got)
+	}
+	for id, labels := range want {
+		if got[id] != labels {
+			t.Fatalf("%s: got %q want %q", id, got[id], labels)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// mailBody is a resolved message body for buildRFC822.
+type mailBody struct {
+	HTML   string
+	Plain  string
+	Inline []mailAttachment
+}
+
+// renderMarkdown renders Markdown (GitHub flavored) to HTML and a plaintext
+// alternative. Raw HTML is dropped and javascript:/data: style links are
+// neutralized by the renderer's safe mode. Images pointing at local files
+// inside baseDir become inline parts referenced by cid:; other local paths
+// are rejected, since merge data substituted into the Markdown must not be
+// able to mail arbitrary files.
+func renderMarkdown(src []byte, baseDir string) (mailBody, error) {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var out mailBody
+	cids := map[string]string{}
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !entering || !ok || !isLocalImage(string(img.Destination)) {
+			return ast.WalkContinue, nil
+		}
+		path, err := markdownImagePath(string(img.Destination), baseDir)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		cid, ok := cids[path]
+		if !ok {
+			cid = fmt.Sprintf("img%d@gogcli.local", len(cids)+1)
+			cids[path] = cid
+			out.Inline = append(out.Inline, mailAttachment{Path: path, ContentID: cid})
+		}
+		img.Destination = []byte("cid:" + cid)
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return mailBody{}, err
+	}
+
+	var html bytes.Buffer
+	if err := md.Renderer().Render(&html, src, doc); err != nil {
+		return mailBody{}, fmt.Errorf("render markdown: %w", err)
+	}
+	out.HTML = html.String()
+	out.Plain = markdownPlainText(doc, src)
+	return out, nil
+}
+
+// Body source kinds for --body-file and --template, by file extension.
+const (
+	mailBodyPlain    = "plain"
+	mailBodyHTML     = "html"
+	mailBodyMarkdown = "markdown"
+)
+
+func mailBodyKind(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm":
+		return mailBodyHTML
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return mailBodyMarkdown
+	default:
+		return mailBodyPlain
+	}
+}
+
+// resolveMailBody combines --body, --body-html, --body-markdown and
+// --body-file (kind from its extension). Markdown produces both the HTML
+// and the plaintext part, so it cannot be combined with --body/--body-html.
+func resolveMailBody(body, bodyHTML, markdown, file string) (mailBody, error) {
+	out := mailBody{Plain: body, HTML: bodyHTML}
+	mdDir := "."
+	if file != "" {
+		if markdown != "" {
+			return mailBody{}, usage("use only one of --body-markdown or --body-file")
+		}
+		data, err := os.ReadFile(file) //nolint:gosec // user-provided path
+		if err != nil {
+			return mailBody{}, err
+		}
+		switch mailBodyKind(file) {
+		case mailBodyMarkdown:
+			markdown, mdDir = string(data), filepath.Dir(file)
+		case mailBodyHTML:
+			if strings.TrimSpace(bodyHTML) != "" {
+				return mailBody{}, usage("use only one of --body-html or --body-file (HTML)")
+			}
+			out.HTML = string(data)
+		default:
+			if strings.TrimSpace(body) != "" {
+				return mailBody{}, usage("use only one of --body or --body-file (text)")
+			}
+			out.Plain = string(data)
+		}
+	}
+	if markdown == "" {
+		return out, nil
+	}
+	if strings.TrimSpace(body) != "" || strings.TrimSpace(bodyHTML) != "" {
+		return mailBody{}, usage("a Markdown body replaces --body and --body-html")
+	}
+	md, err := renderMarkdown([]byte(markdown), mdDir)
+	if err != nil {
+		return mailBody{}, newUsageError(err)
+	}
+	return md, nil
+}
+
+// markdownImagePath resolves a local image destination against baseDir,
+// following symlinks, and rejects images outside baseDir.
+func markdownImagePath(dest, baseDir string) (string, error) {
+	path := dest
+	if p, err := url.PathUnescape(path); err == nil {
+		path = p
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("markdown image: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("markdown image: %w", err)
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("markdown image: %w", err)
+	}
+	resolvedAbs, err := filepath.Abs(resolved)
+	if err != nil {
+		return "", fmt.Errorf("markdown image: %w", err)
+	}
+	rel, err := filepath.Rel(rootAbs, resolvedAbs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("markdown image %q is outside %s; only images in the Markdown file's directory are embedded", dest, baseDir)
+	}
+	return path, nil
+}
+
+func isLocalImage(dest string) bool {
+	if dest == "" || strings.HasPrefix(dest, "#") {
+		return false
+	}
+	if u, err := url.Parse(dest); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		return false // http:, https:, cid:, data:, ... (one-letter schemes are Windows drives)
+	}
+	return true
+}
+
+// markdownPlainText renders the document as readable text: markup removed,
+// list markers and quotes kept, link targets in parentheses.
+func markdownPlainText(doc ast.Node, src []byte) string {
+	return strings.TrimSpace(plainBlocks(doc, src, "\n\n")) + "\n"
+}
+
+func plainBlocks(parent ast.Node, src []byte, sep string) string {
+	var blocks []string
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		if s := plainBlock(n, src); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	return strings.Join(blocks, sep)
+}
+
+func plainBlock(n ast.Node, src []byte) string {
+	switch n := n.(type) {
+	case *ast.Heading, *ast.Paragraph, *ast.TextBlock:
+		return plainInline(n, src)
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		var b strings.Builder
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			b.WriteString("    ")
+			b.Write(seg.Value(src))
+		}
+		return strings.TrimRight(b.String(), "\n")
+	case *ast.Blockquote:
+		return prefixLines(plainBlocks(n, src, "\n\n"), "> ", "> ")
+	case *ast.List:
+		sep := "\n\n"
+		if n.IsTight {
+			sep = "\n"
+		}
+		var items []string
+		i := 0
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", n.Start+i)
+			}
+			i++
+			items = append(items, prefixLines(plainBlocks(item, src, sep), marker, strings.Repeat(" ", len(marker))))
+		}
+		return strings.Join(items, sep)
+	case *ast.ThematicBreak:
+		return "----"
+	case *ast.HTMLBlock:
+		return ""
+	case *extast.Table:
+		var rows []string
+		for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, plainInline(cell, src))
+			}
+			rows = append(rows, strings.Join(cells, " | "))
+		}
+		return strings.Join(rows, "\n")
+	default:
+		return plainBlocks(n, src, "\n\n")
+	}
+}
+
+func plainInline(n ast.Node, src []byte) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		writePlainInline(&b, c, src)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func writePlainInline(b *strings.Builder, n ast.Node, src []byte) {
+	switch n := n.(type) {
+	case *ast.Text:
+		b.Write(n.Value(src))
+		if n.SoftLineBreak() || n.HardLineBreak() {
+			b.WriteByte('\n')
+		}
+	case *ast.String:
+		b.Write(n.Value)
+	case *ast.AutoLink:
+		b.Write(n.URL(src))
+	case *ast.Link:
+		label := plainInline(n, src)
+		dest := string(n.Destination)
+		b.WriteString(label)
+		if dest != "" && dest != label && dest != "mailto:"+label {
+			b.WriteString(" (" + dest + ")")
+		}
+	case *ast.Image:
+		if alt := plainInline(n, src); alt != "" {
+			b.WriteString("[" + alt + "]")
+		}
+	case *ast.RawHTML:
+	default:
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			writePlainInline(b, c, src)
+		}
+	}
+}
+
+// prefixLines prefixes the first line with first and the others with rest.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if line == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + line
+	}
+	return strings.Join(lines, "\n")
+}
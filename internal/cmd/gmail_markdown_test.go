@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	src := "# Monthly notice\n\nHello **Ada**, see [the docs](https://example.com/docs) or <https://example.com>.\n\n" +
+		"![Logo](logo.png) ![again](logo.png) ![remote](https://example.com/x.png)\n\n" +
+		"- one\n- two\n  continued\n\n1. first\n2. second\n\n> quoted\n\n    code line\n\n" +
+		"<script>alert(1)</script>\n\n[click](javascript:alert(1))\n\n| a | b |\n|---|---|\n| 1 | 2 |\n"
+
+	body, err := renderMarkdown([]byte(src), dir)
+	if err != nil {
+		t.Fatalf("renderMarkdown: %v", err)
+	}
+
+	for _, want := range []string{"<h1>Monthly notice</h1>", "<strong>Ada</strong>", `src="cid:img1@gogcli.local"`, `src="https://example.com/x.png"`, "<table>"} {
+		if !strings.Contains(body.HTML, want) {
+			t.Fatalf("HTML missing %q:\n%s", want, body.HTML)
+		}
+	}
+	for _, bad := range []string{"<script", "javascript:"} {
+		if strings.Contains(body.HTML, bad) {
+			t.Fatalf("HTML not sanitized (%q):\n%s", bad, body.HTML)
+		}
+	}
+	if strings.Count(body.HTML, "cid:img1@gogcli.local") != 2 || len(body.Inline) != 1 || body.Inline[0].Path != filepath.Join(dir, "logo.png") {
+		t.Fatalf("unexpected inline parts: %+v\n%s", body.Inline, body.HTML)
+	}
+
+	want := "Monthly notice\n\n" +
+		"Hello Ada, see the docs (https://example.com/docs) or https://example.com.\n\n" +
+		"[Logo] [again] [remote]\n\n" +
+		"- one\n- two\n  continued\n\n1. first\n2. second\n\n> quoted\n\n    code line\n\n" +
+		"click (javascript:alert(1))\n\na | b\n1 | 2\n"
+	if body.Plain != want {
+		t.Fatalf("plain text:\n got %q\nwant %q", body.Plain, want)
+	}
+}
+
+func TestRenderMarkdown_MissingImage(t *testing.T) {
+	if _, err := renderMarkdown([]byte("![x](nope.png)"), t.TempDir()); err == nil {
+		t.Fatalf("expected error for a missing local image")
+	}
+}
+
+func TestRenderMarkdown_ImageOutsideBaseDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tmpl")
+	if err := os.MkdirAll(filepath.Join(dir, "img"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	secret := filepath.Join(root, "secret.json")
+	for _, p := range []string{secret, filepath.Join(dir, "img", "ok.png")} {
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	if body, err := renderMarkdown([]byte("![ok](img/ok.png)"), dir); err != nil || len(body.Inline) != 1 {
+		t.Fatalf("image inside base dir: %+v (%v)", body.Inline, err)
+	}
+	for _, src := range []string{"![x](" + secret + ")", "![x](../secret.json)"} {
+		if _, err := renderMarkdown([]byte(src), dir); err == nil || !strings.Contains(err.Error(), "outside") {
+			t.Fatalf("%s: expected outside error, got %v", src, err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		link := filepath.Join(dir, "link.png")
+		if err := os.Symlink(secret, link); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+		if _, err := renderMarkdown([]byte("![x](link.png)"), dir); err == nil {
+			t.Fatalf("expected symlink escaping the base dir to be rejected")
+		}
+	}
+}
+
+func TestResolveMailBody(t *testing.T) {
+	dir := t.TempDir()
+	md := filepath.Join(dir, "body.md")
+	if err := os.WriteFile(md, []byte("*hi*"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	html := filepath.Join(dir, "body.html")
+	if err := os.WriteFile(html, []byte("<p>hi</p>"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got, err := resolveMailBody("", "", "", md)
+	if err != nil || !strings.Contains(got.HTML, "<em>hi</em>") || got.Plain != "hi\n" {
+		t.Fatalf("markdown file: %+v (%v)", got, err)
+	}
+	got, err = resolveMailBody("plain", "", "", html)
+	if err != nil || got.HTML != "<p>hi</p>" || got.Plain != "plain" {
+		t.Fatalf("html file: %+v (%v)", got, err)
+	}
+	if _, err := resolveMailBody("plain", "", "**x**", ""); err == nil {
+		t.Fatalf("expected conflict between --body and --body-markdown")
+	}
+	if _, err := resolveMailBody("", "<p>x</p>", "", html); err == nil {
+		t.Fatalf("expected conflict between --body-html and an HTML --body-file")
+	}
+}
+
+func TestGmailDraftsCreateCmd_BodyMarkdown(t *testing.T) {
+	var raw string
+	stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/users/me/drafts") || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var d gmail.Draft
+		_ = json.NewDecoder(r.Body).Decode(&d)
+		b, _ := base64.RawURLEncoding.DecodeString(d.Message.Raw)
+		raw = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "d1", "message": map[string]any{"id": "m1"}})
+	}))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "chart.png"), []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mdPath := filepath.Join(dir, "notice.md")
+	if err := os.WriteFile(mdPath, []byte("Numbers are **up**.\n\n![chart](chart.png)\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailDraftsCreateCmd{}, []string{"--to", "a@example.com", "--subject", "S", "--body-file", mdPath}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	for _, want := range []string{"multipart/alternative", "Numbers are up.", "<strong>up</strong>", "multipart/related", "Content-ID: <img1@gogcli.local>", `src="cid:img1@gogcli.local"`} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("draft missing %q:\n%s", want, raw)
+		}
+	}
+}
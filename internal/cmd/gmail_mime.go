@@ -11,15 +11,17 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 type mailAttachment struct {
-	Path     string
-	Filename string
-	MIMEType string
-	Data     []byte
+	Path      string
+	Filename  string
+	MIMEType  string
+	Data      []byte
+	ContentID string // inline parts: referenced from the HTML body as cid:<ContentID>
 }
 
 type mailOptions struct {
@@ -35,6 +37,8 @@ type mailOptions struct {
 	References        string
 	AdditionalHeaders map[string]string
 	Attachments       []mailAttachment
+	Inline            []mailAttachment // multipart/related parts for the HTML body
+	Calendar          string           // iCalendar invitation (text/calendar part + invite.ics)
 }
 
 func buildRFC822(opts mailOptions) ([]byte, error) {
@@ -47,6 +51,9 @@ func buildRFC822(opts mailOptions) ([]byte, error) {
 	if strings.TrimSpace(opts.Subject) == "" {
 		return nil, errors.New("missing Subject")
 	}
+	if len(opts.Inline) > 0 && strings.TrimSpace(opts.BodyHTML) == "" {
+		return nil, errors.New("inline parts require an HTML body")
+	}
 
 	var b bytes.Buffer
 
@@ -98,7 +105,13 @@ func buildRFC822(opts mailOptions) ([]byte, error) {
 		}
 		writeHeader(&b, "References", strings.TrimSpace(opts.References))
 	}
-	for k, v := range opts.AdditionalHeaders {
+	headerNames := make([]string, 0, len(opts.AdditionalHeaders))
+	for k := range opts.AdditionalHeaders {
+		headerNames = append(headerNames, k)
+	}
+	sort.Strings(headerNames)
+	for _, k := range headerNames {
+		v := opts.AdditionalHeaders[k]
 		if strings.TrimSpace(k) != "" && strings.TrimSpace(v) != "" {
 			if err := validateHeaderValue(v); err != nil {
 				return nil, fmt.Errorf("invalid header %s: %w", k, err)
@@ -107,38 +120,23 @@ func buildRFC822(opts mailOptions) ([]byte, error) {
 		}
 	}
 
-	plainBody := normalizeCRLF(opts.Body)
-	htmlBody := normalizeCRLF(opts.BodyHTML)
-	hasPlain := strings.TrimSpace(plainBody) != ""
-	hasHTML := strings.TrimSpace(htmlBody) != ""
+	calendar, method, err := prepareCalendar(opts.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	body := mailBodyParts{
+		plain:    normalizeCRLF(opts.Body),
+		html:     normalizeCRLF(opts.BodyHTML),
+		inline:   opts.Inline,
+		calendar: calendar,
+		method:   method,
+	}
 
-	if len(opts.Attachments) == 0 {
-		switch {
-		case hasPlain && hasHTML:
-			altBoundary, err := randomBoundary()
-			if err != nil {
-				return nil, err
-			}
-			writeHeader(&b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
-			b.WriteString("\r\n")
-
-			writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-			writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
-			b.WriteString(fmt.Sprintf("--%s--\r\n", altBoundary))
-			return b.Bytes(), nil
-		case hasHTML && !hasPlain:
-			writeHeader(&b, "Content-Type", "text/html; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", "7bit")
-			b.WriteString("\r\n")
-			writeBodyWithTrailingCRLF(&b, htmlBody)
-			return b.Bytes(), nil
-		default:
-			writeHeader(&b, "Content-Type", "text/plain; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", "7bit")
-			b.WriteString("\r\n")
-			writeBodyWithTrailingCRLF(&b, plainBody)
-			return b.Bytes(), nil
+	if len(opts.Attachments) == 0 && calendar == "" {
+		if err := body.write(&b); err != nil {
+			return nil, err
 		}
+		return b.Bytes(), nil
 	}
 
 	mixedBoundary, err := randomBoundary()
@@ -151,47 +149,37 @@ func buildRFC822(opts mailOptions) ([]byte, error) {
 
 	// Body part
 	b.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
-	switch {
-	case hasPlain && hasHTML:
-		altBoundary, err := randomBoundary()
-		if err != nil {
-			return nil, err
-		}
-		b.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n\r\n", altBoundary))
-		writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-		writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
-		b.WriteString(fmt.Sprintf("--%s--\r\n", altBoundary))
-	case hasHTML && !hasPlain:
-		b.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
-		b.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		writeBodyWithTrailingCRLF(&b, htmlBody)
-	default:
-		b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
-		b.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		writeBodyWithTrailingCRLF(&b, plainBody)
+	if err := body.write(&b); err != nil {
+		return nil, err
+	}
+
+	// Invitations also travel as an invite.ics attachment, like the ones
+	// calendar servers send, for clients that ignore the text/calendar part.
+	attachments := opts.Attachments
+	if calendar != "" {
+		attachments = append([]mailAttachment{{Filename: "invite.ics", MIMEType: "application/ics", Data: []byte(calendar)}}, attachments...)
 	}
 
 	// Attachments
-	for _, a := range opts.Attachments {
-		if a.Filename == "" {
-			a.Filename = filepath.Base(a.Path)
-		}
-		if a.MIMEType == "" {
-			a.MIMEType = mime.TypeByExtension(strings.ToLower(filepath.Ext(a.Filename)))
-			if a.MIMEType == "" {
-				a.MIMEType = "application/octet-stream"
-			}
-		}
-		if len(a.Data) == 0 {
-			data, err := os.ReadFile(a.Path)
-			if err != nil {
-				return nil, err
-			}
-			a.Data = data
+	for _, a := range attachments {
+		a, err := loadMailAttachment(a)
+		if err != nil {
+			return nil, err
 		}
 
 		b.WriteString(fmt.Sprintf("\r\n--%s\r\n", mixedBoundary))
 		b.WriteString(fmt.Sprintf("Content-Type: %s\r\n", a.MIMEType))
+		// RFC 2046 forbids encoding message/rfc822: the message goes in as is.
+		if strings.EqualFold(a.MIMEType, "message/rfc822") {
+			encoding := "7bit"
+			if !isASCII(string(a.Data)) {
+				encoding = "8bit"
+			}
+			b.WriteString("Content-Transfer-Encoding: " + encoding + "\r\n")
+			b.WriteString(fmt.Sprintf("Content-Disposition: attachment; %s\r\n\r\n", contentDispositionFilename(a.Filename)))
+			writeBodyWithTrailingCRLF(&b, normalizeCRLF(string(a.Data)))
+			continue
+		}
 		b.WriteString("Content-Transfer-Encoding: base64\r\n")
 		b.WriteString(fmt.Sprintf("Content-Disposition: attachment; %s\r\n\r\n", contentDispositionFilename(a.Filename)))
 		b.WriteString(wrapBase64(a.Data))
@@ -202,6 +190,160 @@ func buildRFC822(opts mailOptions) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// loadMailAttachment fills in the filename, MIME type (from the extension)
+// and data (from Path) when they are not set.
+func loadMailAttachment(a mailAttachment) (mailAttachment, error) {
+	if a.Filename == "" {
+		a.Filename = filepath.Base(a.Path)
+	}
+	if a.MIMEType == "" {
+		a.MIMEType = mime.TypeByExtension(strings.ToLower(filepath.Ext(a.Filename)))
+		if a.MIMEType == "" {
+			a.MIMEType = "application/octet-stream"
+		}
+	}
+	if len(a.Data) == 0 {
+		data, err := os.ReadFile(a.Path)
+		if err != nil {
+			return a, err
+		}
+		a.Data = data
+	}
+	return a, nil
+}
+
+// mailBodyParts are the alternative renderings of the message body.
+type mailBodyParts struct {
+	plain, html string
+	inline      []mailAttachment
+	calendar    string // iCalendar data, CRLF-normalized
+	method      string // iCalendar METHOD, e.g. REQUEST
+}
+
+// write writes the body as one MIME entity (headers, blank line, content):
+// a single part, or multipart/alternative over plain text, HTML (with its
+// inline parts) and calendar, in increasing order of preference.
+func (p mailBodyParts) write(b *bytes.Buffer) error {
+	hasPlain := strings.TrimSpace(p.plain) != ""
+	hasHTML := strings.TrimSpace(p.html) != ""
+	hasCalendar := p.calendar != ""
+	calendarType := fmt.Sprintf("text/calendar; charset=\"utf-8\"; method=%s", p.method)
+
+	n := 0
+	for _, has := range []bool{hasPlain, hasHTML, hasCalendar} {
+		if has {
+			n++
+		}
+	}
+	if n > 1 {
+		altBoundary, err := randomBoundary()
+		if err != nil {
+			return err
+		}
+		writeHeader(b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+		b.WriteString("\r\n")
+		if hasPlain {
+			writeTextPart(b, altBoundary, "text/plain; charset=\"utf-8\"", p.plain)
+		}
+		if hasHTML {
+			if err := writeHTMLPart(b, altBoundary, p.html, p.inline); err != nil {
+				return err
+			}
+		}
+		if hasCalendar {
+			writeTextPart(b, altBoundary, calendarType, p.calendar)
+		}
+		_, _ = fmt.Fprintf(b, "--%s--\r\n", altBoundary)
+		return nil
+	}
+
+	contentType, content := "text/plain; charset=\"utf-8\"", p.plain
+	switch {
+	case hasHTML && len(p.inline) > 0:
+		return writeRelated(b, p.html, p.inline)
+	case hasHTML:
+		contentType, content = "text/html; charset=\"utf-8\"", p.html
+	case hasCalendar:
+		contentType, content = calendarType, p.calendar
+	}
+	writeHeader(b, "Content-Type", contentType)
+	writeHeader(b, "Content-Transfer-Encoding", "7bit")
+	b.WriteString("\r\n")
+	writeBodyWithTrailingCRLF(b, content)
+	return nil
+}
+
+// prepareCalendar validates iCalendar data and returns it CRLF-normalized
+// with its METHOD, adding METHOD:REQUEST when the file has none (Outlook
+// only treats a part as an invitation when both agree).
+func prepareCalendar(ics string) (string, string, error) {
+	if strings.TrimSpace(ics) == "" {
+		return "", "", nil
+	}
+	lines := strings.Split(strings.TrimRight(normalizeCRLF(ics), "\r\n"), "\r\n")
+	if !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return "", "", errors.New("calendar data must start with BEGIN:VCALENDAR")
+	}
+	method := ""
+	for _, line := range lines {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "METHOD") {
+			method = strings.ToUpper(strings.TrimSpace(value))
+			break
+		}
+	}
+	if method == "" {
+		method = "REQUEST"
+		lines = append([]string{lines[0], "METHOD:REQUEST"}, lines[1:]...)
+	}
+	for _, r := range method {
+		if (r < 'A' || r > 'Z') && r != '-' {
+			return "", "", fmt.Errorf("invalid calendar METHOD %q", method)
+		}
+	}
+	return strings.Join(lines, "\r\n") + "\r\n", method, nil
+}
+
+// writeHTMLPart writes the HTML body as a part of boundary: text/html, or
+// multipart/related when it has inline parts.
+func writeHTMLPart(b *bytes.Buffer, boundary string, htmlBody string, inline []mailAttachment) error {
+	if len(inline) == 0 {
+		writeTextPart(b, boundary, "text/html; charset=\"utf-8\"", htmlBody)
+		return nil
+	}
+	_, _ = fmt.Fprintf(b, "--%s\r\n", boundary)
+	return writeRelated(b, htmlBody, inline)
+}
+
+// writeRelated writes a multipart/related entity (Content-Type header, blank
+// line, parts): the HTML body followed by its inline parts.
+func writeRelated(b *bytes.Buffer, htmlBody string, inline []mailAttachment) error {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return err
+	}
+	writeHeader(b, "Content-Type", fmt.Sprintf("multipart/related; boundary=%q; type=\"text/html\"", boundary))
+	b.WriteString("\r\n")
+	writeTextPart(b, boundary, "text/html; charset=\"utf-8\"", htmlBody)
+	for _, a := range inline {
+		a, err := loadMailAttachment(a)
+		if err != nil {
+			return err
+		}
+		if err := validateHeaderValue(a.ContentID); err != nil || strings.TrimSpace(a.ContentID) == "" || strings.ContainsAny(a.ContentID, "<> ") {
+			return fmt.Errorf("invalid inline content ID %q", a.ContentID)
+		}
+		_, _ = fmt.Fprintf(b, "--%s\r\n", boundary)
+		_, _ = fmt.Fprintf(b, "Content-Type: %s\r\n", a.MIMEType)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		_, _ = fmt.Fprintf(b, "Content-ID: <%s>\r\n", a.ContentID)
+		_, _ = fmt.Fprintf(b, "Content-Disposition: inline; %s\r\n\r\n", contentDispositionFilename(a.Filename))
+		b.WriteString(wrapBase64(a.Data))
+		b.WriteString("\r\n")
+	}
+	_, _ = fmt.Fprintf(b, "--%s--\r\n", boundary)
+	return nil
+}
+
 func writeHeader(b *bytes.Buffer, name, value string) {
 	b.WriteString(name)
 	b.WriteString(": ")
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GmailMIMEFlags are the extra message options shared by `gmail send` and
+// `gmail drafts create`.
+type GmailMIMEFlags struct {
+	Inline              []string `name:"inline" help:"Inline image for the HTML body as cid=path, referenced as <img src=\"cid:...\"> (repeatable)"`
+	Header              []string `name:"header" help:"Extra header as 'Name: value' (repeatable)"`
+	ListUnsubscribe     []string `name:"list-unsubscribe" help:"List-Unsubscribe target: https URL or mailto: address (repeatable)"`
+	OneClickUnsubscribe bool     `name:"one-click-unsubscribe" help:"Add List-Unsubscribe-Post for RFC 8058 one-click unsubscribe (needs an https --list-unsubscribe)"`
+	Priority            string   `name:"priority" help:"Message priority: high|normal|low"`
+	Calendar            string   `name:"calendar" help:"iCalendar (.ics) file to send as an invitation (method from the file, default REQUEST)"`
+}
+
+// reservedHeaders are set by buildRFC822 or by dedicated flags.
+var reservedHeaders = map[string]bool{
+	"from": true, "to": true, "cc": true, "bcc": true, "subject": true, "reply-to": true,
+	"date": true, "mime-version": true, "content-type": true, "content-transfer-encoding": true,
+	"in-reply-to": true, "references": true,
+}
+
+// priorityHeaders maps --priority to X-Priority/Importance/Priority, the
+// variants different clients read.
+var priorityHeaders = map[string][3]string{
+	"high":   {"1 (Highest)", "high", "urgent"},
+	"normal": {"3 (Normal)", "normal", "normal"},
+	"low":    {"5 (Lowest)", "low", "non-urgent"},
+}
+
+// apply adds the flags to opts. Errors are usage errors.
+func (f GmailMIMEFlags) apply(opts *mailOptions) error {
+	for _, spec := range f.Inline {
+		cid, path, ok := strings.Cut(spec, "=")
+		cid, path = strings.Trim(strings.TrimSpace(cid), "<>"), strings.TrimSpace(path)
+		if !ok || cid == "" || path == "" {
+			return usagef("invalid --inline %q (expected cid=path)", spec)
+		}
+		if strings.ContainsAny(cid, " <>\"") {
+			return usagef("invalid --inline content ID %q", cid)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return usagef("--inline %s: %v", cid, err)
+		}
+		opts.Inline = append(opts.Inline, mailAttachment{Path: path, ContentID: cid})
+	}
+
+	headers := map[string]string{}
+	set := func(name, value string) error {
+		if err := validateHeaderValue(value); err != nil {
+			return usagef("invalid header %s: %v", name, err)
+		}
+		for k := range headers {
+			if strings.EqualFold(k, name) {
+				return usagef("duplicate header %s", name)
+			}
+		}
+		headers[name] = value
+		return nil
+	}
+	for _, h := range f.Header {
+		name, value, ok := strings.Cut(h, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || !isHeaderName(name) || value == "" {
+			return usagef("invalid --header %q (expected 'Name: value')", h)
+		}
+		if reservedHeaders[strings.ToLower(name)] {
+			return usagef("--header cannot set %s; use the dedicated flag", name)
+		}
+		if err := set(name, value); err != nil {
+			return err
+		}
+	}
+
+	if len(f.ListUnsubscribe) > 0 {
+		targets := make([]string, 0, len(f.ListUnsubscribe))
+		hasHTTPS := false
+		for _, t := range f.ListUnsubscribe {
+			t = strings.Trim(strings.TrimSpace(t), "<>")
+			if !strings.Contains(t, ":") && strings.Contains(t, "@") {
+				t = "mailto:" + t
+			}
+			u, err := url.Parse(t)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "mailto") {
+				return usagef("invalid --list-unsubscribe %q (expected an https URL or mailto: address)", t)
+			}
+			hasHTTPS = hasHTTPS || u.Scheme == "https"
+			targets = append(targets, "<"+t+">")
+		}
+		if err := set("List-Unsubscribe", strings.Join(targets, ", ")); err != nil {
+			return err
+		}
+		if f.OneClickUnsubscribe {
+			if !hasHTTPS {
+				return usage("--one-click-unsubscribe needs an https --list-unsubscribe URL")
+			}
+			if err := set("List-Unsubscribe-Post", "List-Unsubscribe=One-Click"); err != nil {
+				return err
+			}
+		}
+	} else if f.OneClickUnsubscribe {
+		return usage("--one-click-unsubscribe needs --list-unsubscribe")
+	}
+
+	if p := strings.ToLower(strings.TrimSpace(f.Priority)); p != "" {
+		values, ok := priorityHeaders[p]
+		if !ok {
+			return usagef("invalid --priority %q (expected high|normal|low)", f.Priority)
+		}
+		for i, name := range []string{"X-Priority", "Importance", "Priority"} {
+			if err := set(name, values[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(headers) > 0 {
+		if opts.AdditionalHeaders == nil {
+			opts.AdditionalHeaders = map[string]string{}
+		}
+		for k, v := range headers {
+			opts.AdditionalHeaders[k] = v
+		}
+	}
+
+	if f.Calendar != "" {
+		data, err := os.ReadFile(f.Calendar) //nolint:gosec // user-provided path
+		if err != nil {
+			return err
+		}
+		if _, _, err := prepareCalendar(string(data)); err != nil {
+			return usagef("--calendar %s: %v", f.Calendar, err)
+		}
+		opts.Calendar = string(data)
+	}
+	return nil
+}
+
+// isHeaderName reports whether s is a valid RFC 5322 field name.
+func isHeaderName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r <= ' ' || r > '~' || r == ':' {
+			return false
+		}
+	}
+	return true
+}
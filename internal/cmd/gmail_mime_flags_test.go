@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGmailMIMEFlagsApply(t *testing.T) {
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(logo, []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	f := GmailMIMEFlags{
+		Inline:              []string{"<logo@x>=" + logo},
+		Header:              []string{"X-Campaign: spring", "X-Ticket:  42 "},
+		ListUnsubscribe:     []string{"https://example.com/u/1", "unsub@example.com"},
+		OneClickUnsubscribe: true,
+		Priority:            "High",
+	}
+	var opts mailOptions
+	if err := f.apply(&opts); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(opts.Inline) != 1 || opts.Inline[0].ContentID != "logo@x" || opts.Inline[0].Path != logo {
+		t.Fatalf("unexpected inline: %+v", opts.Inline)
+	}
+	want := map[string]string{
+		"X-Campaign":            "spring",
+		"X-Ticket":              "42",
+		"List-Unsubscribe":      "<https://example.com/u/1>, <mailto:unsub@example.com>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		"X-Priority":            "1 (Highest)",
+		"Importance":            "high",
+		"Priority":              "urgent",
+	}
+	if len(opts.AdditionalHeaders) != len(want) {
+		t.Fatalf("unexpected headers: %v", opts.AdditionalHeaders)
+	}
+	for k, v := range want {
+		if opts.AdditionalHeaders[k] != v {
+			t.Fatalf("%s = %q, want %q", k, opts.AdditionalHeaders[k], v)
+		}
+	}
+}
+
+func TestGmailMIMEFlagsApply_Errors(t *testing.T) {
+	for name, f := range map[string]GmailMIMEFlags{
+		"inline without cid":   {Inline: []string{"logo.png"}},
+		"inline missing file":  {Inline: []string{"a=/does/not/exist.png"}},
+		"header without colon": {Header: []string{"X-Foo bar"}},
+		"header bad name":      {Header: []string{"X Foo: bar"}},
+		"reserved header":      {Header: []string{"Subject: hi"}},
+		"duplicate header":     {Header: []string{"X-A: 1", "x-a: 2"}},
+		"priority clash":       {Header: []string{"Importance: low"}, Priority: "high"},
+		"bad unsubscribe":      {ListUnsubscribe: []string{"ftp://example.com"}},
+		"one-click no https":   {ListUnsubscribe: []string{"mailto:u@example.com"}, OneClickUnsubscribe: true},
+		"one-click alone":      {OneClickUnsubscribe: true},
+		"bad priority":         {Priority: "urgent"},
+	} {
+		var opts mailOptions
+		if err := f.apply(&opts); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGmailMIMEFlagsApply_Calendar(t *testing.T) {
+	dir := t.TempDir()
+	ics := filepath.Join(dir, "invite.ics")
+	if err := os.WriteFile(ics, []byte("BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VEVENT\nSUMMARY:Sync\nEND:VEVENT\nEND:VCALENDAR\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var opts mailOptions
+	if err := (GmailMIMEFlags{Calendar: ics}).apply(&opts); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !strings.Contains(opts.Calendar, "SUMMARY:Sync") {
+		t.Fatalf("calendar not loaded: %q", opts.Calendar)
+	}
+
+	bad := filepath.Join(dir, "bad.ics")
+	if err := os.WriteFile(bad, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := (GmailMIMEFlags{Calendar: bad}).apply(&mailOptions{}); err == nil {
+		t.Fatalf("expected error for non-iCalendar data")
+	}
+}
@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"regexp"
 	"strings"
 	"testing"
@@ -255,3 +261,142 @@ func TestRandomMessageID(t *testing.T) {
 		t.Fatalf("unexpected: %q", id)
 	}
 }
+
+func TestBuildRFC822InlineRelated(t *testing.T) {
+	for _, withAttachment := range []bool{false, true} {
+		opts := mailOptions{
+			From:     "a@b.com",
+			To:       []string{"c@d.com"},
+			Subject:  "Hi",
+			Body:     "Plain",
+			BodyHTML: `<p><img src="cid:logo@x"></p>`,
+			Inline:   []mailAttachment{{Filename: "logo.png", Data: []byte("PNG"), ContentID: "logo@x"}},
+		}
+		if withAttachment {
+			opts.Attachments = []mailAttachment{{Filename: "a.txt", Data: []byte("A")}}
+		}
+		raw, err := buildRFC822(opts)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		var got []string
+		var walk func(contentType string, body io.Reader, depth int)
+		walk = func(contentType string, body io.Reader, depth int) {
+			mediaType, params, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				t.Fatalf("media type %q: %v", contentType, err)
+			}
+			got = append(got, strings.Repeat(" ", depth)+mediaType)
+			if !strings.HasPrefix(mediaType, "multipart/") {
+				return
+			}
+			mr := multipart.NewReader(body, params["boundary"])
+			for {
+				p, err := mr.NextPart()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err != nil {
+					t.Fatalf("next part: %v", err)
+				}
+				if cid := p.Header.Get("Content-ID"); cid != "" && cid != "<logo@x>" {
+					t.Fatalf("unexpected Content-ID %q", cid)
+				}
+				walk(p.Header.Get("Content-Type"), p, depth+1)
+			}
+		}
+		walk(msg.Header.Get("Content-Type"), msg.Body, 0)
+
+		want := []string{"multipart/alternative", " text/plain", " multipart/related", "  text/html", "  image/png"}
+		if withAttachment {
+			want = []string{"multipart/mixed", " multipart/alternative", "  text/plain", "  multipart/related", "   text/html", "   image/png", " text/plain"}
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("structure:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
+
+func TestBuildRFC822InlineRequiresHTML(t *testing.T) {
+	_, err := buildRFC822(mailOptions{
+		From:    "a@b.com",
+		To:      []string{"c@d.com"},
+		Subject: "Hi",
+		Body:    "Plain",
+		Inline:  []mailAttachment{{Filename: "logo.png", Data: []byte("PNG"), ContentID: "logo@x"}},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestBuildRFC822CalendarInvite(t *testing.T) {
+	raw, err := buildRFC822(mailOptions{
+		From:              "a@b.com",
+		To:                []string{"c@d.com"},
+		Subject:           "Sync",
+		Body:              "Join us",
+		BodyHTML:          "<p>Join us</p>",
+		Calendar:          "BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VEVENT\nSUMMARY:Sync\nEND:VEVENT\nEND:VCALENDAR\n",
+		AdditionalHeaders: map[string]string{"X-B": "2", "X-A": "1"},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s := string(raw)
+	if !strings.Contains(s, "X-A: 1\r\nX-B: 2\r\n") {
+		t.Fatalf("headers not sorted: %q", s)
+	}
+	if !strings.Contains(s, "BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nVERSION:2.0\r\n") {
+		t.Fatalf("METHOD not added: %q", s)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/mixed" {
+		t.Fatalf("top-level %s", mediaType)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("body part: %v", err)
+	}
+	altType, altParams, _ := mime.ParseMediaType(body.Header.Get("Content-Type"))
+	if altType != "multipart/alternative" {
+		t.Fatalf("body %s", altType)
+	}
+	var types []string
+	ar := multipart.NewReader(body, altParams["boundary"])
+	for {
+		p, err := ar.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("alt part: %v", err)
+		}
+		types = append(types, p.Header.Get("Content-Type"))
+	}
+	if len(types) != 3 || types[2] != `text/calendar; charset="utf-8"; method=REQUEST` {
+		t.Fatalf("unexpected alternatives: %q", types)
+	}
+	att, err := mr.NextPart()
+	if err != nil || att.Header.Get("Content-Type") != "application/ics" || att.FileName() != "invite.ics" {
+		t.Fatalf("unexpected invite attachment: %v %v", att, err)
+	}
+}
+
+func TestPrepareCalendarKeepsMethod(t *testing.T) {
+	ics, method, err := prepareCalendar("BEGIN:VCALENDAR\r\nMETHOD:cancel\r\nEND:VCALENDAR")
+	if err != nil || method != "CANCEL" || strings.Count(ics, "METHOD:") != 1 {
+		t.Fatalf("prepareCalendar: %q %q %v", ics, method, err)
+	}
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailReplyCmd struct {
+	MessageID    string   `arg:"" name:"messageId" help:"Message to reply to"`
+	All          bool     `name:"all" help:"Reply to all: the sender plus the original To and Cc (minus yourself)"`
+	Cc           string   `name:"cc" help:"CC recipients (comma-separated; replaces the reply-all Cc)"`
+	Bcc          string   `name:"bcc" help:"BCC recipients (comma-separated)"`
+	Body         string   `name:"body" help:"Reply text (plain text)"`
+	BodyHTML     string   `name:"body-html" help:"Reply text (HTML)"`
+	BodyMarkdown string   `name:"body-markdown" help:"Reply text (Markdown)"`
+	BodyFile     string   `name:"body-file" help:"Read the reply text from a file (.md/.markdown = Markdown, .html/.htm = HTML, otherwise plain text)"`
+	NoQuote      bool     `name:"no-quote" help:"Do not quote the original message"`
+	Attach       []string `name:"attach" help:"Attachment file path (repeatable)"`
+	From         string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Draft        bool     `name:"draft" help:"Save as a draft instead of sending"`
+	GmailMIMEFlags
+}
+
+func (c *GmailReplyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	body, err := resolveMailBody(c.Body, c.BodyHTML, c.BodyMarkdown, c.BodyFile)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(body.Plain) == "" && strings.TrimSpace(body.HTML) == "" {
+		return usage("required: --body, --body-html, --body-markdown or --body-file")
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	fromAddr, sendingEmail, err := resolveFromAddress(ctx, svc, account, c.From)
+	if err != nil {
+		return err
+	}
+
+	orig, err := svc.Users.Messages.Get("me", strings.TrimSpace(c.MessageID)).Format("full").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	info := replyInfoFromMessage(orig)
+
+	var to, cc []string
+	if c.All {
+		to, cc = buildReplyAllRecipients(info, sendingEmail)
+	} else {
+		reply := info.ReplyToAddr
+		if strings.TrimSpace(reply) == "" {
+			reply = info.FromAddr
+		}
+		to = parseEmailAddresses(reply)
+	}
+	if strings.TrimSpace(c.Cc) != "" {
+		cc = splitCSV(c.Cc)
+	}
+	if len(to) == 0 {
+		return usage("original message has no sender address to reply to")
+	}
+
+	if !c.NoQuote {
+		body = quoteForReply(body, orig.Payload)
+	}
+
+	atts := make([]mailAttachment, 0, len(c.Attach))
+	for _, p := range c.Attach {
+		atts = append(atts, mailAttachment{Path: p})
+	}
+	opts := mailOptions{
+		From:        fromAddr,
+		To:          to,
+		Cc:          cc,
+		Bcc:         splitCSV(c.Bcc),
+		Subject:     prefixSubject("Re:", headerValue(orig.Payload, "Subject")),
+		Body:        body.Plain,
+		BodyHTML:    body.HTML,
+		InReplyTo:   info.InReplyTo,
+		References:  info.References,
+		Attachments: atts,
+		Inline:      body.Inline,
+	}
+	if err := c.GmailMIMEFlags.apply(&opts); err != nil {
+		return err
+	}
+	raw, err := buildRFC822(opts)
+	if err != nil {
+		return err
+	}
+	return sendOrDraft(ctx, svc, raw, info.ThreadID, fromAddr, c.Draft)
+}
+
+type GmailForwardCmd struct {
+	MessageID     string   `arg:"" name:"messageId" help:"Message to forward"`
+	To            string   `name:"to" help:"Recipients (comma-separated, required)"`
+	Cc            string   `name:"cc" help:"CC recipients (comma-separated)"`
+	Bcc           string   `name:"bcc" help:"BCC recipients (comma-separated)"`
+	Body          string   `name:"body" help:"Note above the forwarded message (plain text)"`
+	BodyHTML      string   `name:"body-html" help:"Note above the forwarded message (HTML)"`
+	BodyMarkdown  string   `name:"body-markdown" help:"Note above the forwarded message (Markdown)"`
+	BodyFile      string   `name:"body-file" help:"Read the note from a file (.md/.markdown = Markdown, .html/.htm = HTML, otherwise plain text)"`
+	AsAttachment  bool     `name:"as-attachment" help:"Attach the original as a message/rfc822 part instead of including it inline"`
+	NoAttachments bool     `name:"no-attachments" help:"Do not re-attach the original's attachments (inline forward)"`
+	Attach        []string `name:"attach" help:"Additional attachment file path (repeatable)"`
+	From          string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Draft         bool     `name:"draft" help:"Save as a draft instead of sending"`
+	GmailMIMEFlags
+}
+
+func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.To) == "" {
+		return usage("required: --to")
+	}
+	body, err := resolveMailBody(c.Body, c.BodyHTML, c.BodyMarkdown, c.BodyFile)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	fromAddr, _, err := resolveFromAddress(ctx, svc, account, c.From)
+	if err != nil {
+		return err
+	}
+
+	messageID := strings.TrimSpace(c.MessageID)
+	orig, err := svc.Users.Messages.Get("me", messageID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	subject := headerValue(orig.Payload, "Subject")
+
+	atts := make([]mailAttachment, 0, len(c.Attach)+1)
+	if c.AsAttachment {
+		rawMsg, err := fetchRawMessage(ctx, svc, messageID)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSpace(subject)
+		if name == "" {
+			name = "message"
+		}
+		atts = append(atts, mailAttachment{Filename: name + ".eml", MIMEType: "message/rfc822", Data: rawMsg.Raw})
+	} else {
+		body = appendForwarded(body, orig.Payload)
+		if !c.NoAttachments {
+			dir, err := os.MkdirTemp("", "gog-forward-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+			for _, a := range collectAttachments(orig.Payload) {
+				path, _, err := downloadAttachment(ctx, svc, messageID, a, dir)
+				if err != nil {
+					return fmt.Errorf("attachment %s: %w", a.Filename, err)
+				}
+				atts = append(atts, mailAttachment{Path: path, Filename: a.Filename, MIMEType: a.MimeType})
+			}
+		}
+	}
+	for _, p := range c.Attach {
+		atts = append(atts, mailAttachment{Path: p})
+	}
+	if strings.TrimSpace(body.Plain) == "" && strings.TrimSpace(body.HTML) == "" {
+		body.Plain = "Forwarded message attached."
+	}
+
+	opts := mailOptions{
+		From:        fromAddr,
+		To:          splitCSV(c.To),
+		Cc:          splitCSV(c.Cc),
+		Bcc:         splitCSV(c.Bcc),
+		Subject:     prefixSubject("Fwd:", subject),
+		Body:        body.Plain,
+		BodyHTML:    body.HTML,
+		Attachments: atts,
+		Inline:      body.Inline,
+	}
+	if err := c.GmailMIMEFlags.apply(&opts); err != nil {
+		return err
+	}
+	raw, err := buildRFC822(opts)
+	if err != nil {
+		return err
+	}
+	return sendOrDraft(ctx, svc, raw, "", fromAddr, c.Draft)
+}
+
+// sendOrDraft sends raw (in threadID, if set) or saves it as a draft, and
+// prints the result like `gmail send` / `gmail drafts create`.
+func sendOrDraft(ctx context.Context, svc *gmail.Service, raw []byte, threadID, from string, draft bool) error {
+	u := ui.FromContext(ctx)
+	msg := &gmail.Message{Raw: base64.RawURLEncoding.EncodeToString(raw), ThreadId: threadID}
+
+	if draft {
+		d, err := svc.Users.Drafts.Create("me", &gmail.Draft{Message: msg}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+				"draftId":  d.Id,
+				"message":  d.Message,
+				"threadId": threadID,
+			})
+		}
+		u.Out().Printf("draft_id\t%s", d.Id)
+		if d.Message != nil && d.Message.Id != "" {
+			u.Out().Printf("message_id\t%s", d.Message.Id)
+		}
+		if threadID != "" {
+			u.Out().Printf("thread_id\t%s", threadID)
+		}
+		return nil
+	}
+
+	sent, err := svc.Users.Messages.Send("me", msg).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"messageId": sent.Id,
+			"threadId":  sent.ThreadId,
+			"from":      from,
+		})
+	}
+	u.Out().Printf("message_id\t%s", sent.Id)
+	if sent.ThreadId != "" {
+		u.Out().Printf("thread_id\t%s", sent.ThreadId)
+	}
+	return nil
+}
+
+// prefixSubject adds "Re:"/"Fwd:" unless the subject already starts with it.
+func prefixSubject(prefix, subject string) string {
+	subject = strings.TrimSpace(subject)
+	if strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		return subject
+	}
+	if subject == "" {
+		return prefix
+	}
+	return prefix + " " + subject
+}
+
+// originalBodies returns the original's plain text and HTML bodies, deriving
+// the plain text from the HTML when the message has none.
+func originalBodies(p *gmail.MessagePart) (string, string) {
+	plain := findPartBody(p, "text/plain")
+	htmlBody := findPartBody(p, "text/html")
+	if plain == "" && htmlBody != "" {
+		plain = htmlToText(htmlBody)
+	}
+	return strings.TrimRight(strings.ReplaceAll(plain, "\r\n", "\n"), "\n"), htmlBody
+}
+
+// quoteForReply appends the original message, Gmail style: "On DATE, X
+// wrote:" followed by the text quoted with "> " (plain) or a blockquote
+// (HTML). An HTML part is produced when either side has one.
+func quoteForReply(reply mailBody, orig *gmail.MessagePart) mailBody {
+	plain, origHTML := originalBodies(orig)
+	attribution := fmt.Sprintf("On %s, %s wrote:", formatGmailDate(headerValue(orig, "Date")), headerValue(orig, "From"))
+
+	var quoted strings.Builder
+	for _, line := range strings.Split(plain, "\n") {
+		if line == "" {
+			quoted.WriteString(">\n")
+		} else {
+			quoted.WriteString("> " + line + "\n")
+		}
+	}
+	out := reply
+	out.Plain = strings.TrimRight(reply.Plain, "\n") + "\n\n" + attribution + "\n" + quoted.String()
+
+	if reply.HTML != "" || origHTML != "" {
+		if origHTML == "" {
+			origHTML = textToHTML(plain)
+		}
+		replyHTML := reply.HTML
+		if replyHTML == "" {
+			replyHTML = textToHTML(reply.Plain)
+		}
+		out.HTML = replyHTML + "\n<br><div class=\"gmail_quote\"><div class=\"gmail_attr\">" + html.EscapeString(attribution) +
+			"<br></div><blockquote class=\"gmail_quote\" style=\"margin:0 0 0 .8ex;border-left:1px #ccc solid;padding-left:1ex\">\n" +
+			origHTML + "\n</blockquote></div>\n"
+	}
+	return out
+}
+
+// appendForwarded appends the original message below a "Forwarded message"
+// header block, Gmail style.
+func appendForwarded(note mailBody, orig *gmail.MessagePart) mailBody {
+	plain, origHTML := originalBodies(orig)
+	fields := [][2]string{
+		{"From", headerValue(orig, "From")},
+		{"Date", headerValue(orig, "Date")},
+		{"Subject", headerValue(orig, "Subject")},
+		{"To", headerValue(orig, "To")},
+		{"Cc", headerValue(orig, "Cc")},
+	}
+
+	var head, headHTML strings.Builder
+	head.WriteString("---------- Forwarded message ---------\n")
+	headHTML.WriteString("<div class=\"gmail_quote\"><div class=\"gmail_attr\">---------- Forwarded message ---------<br>\n")
+	for _, f := range fields {
+		if strings.TrimSpace(f[1]) == "" {
+			continue
+		}
+		head.WriteString(f[0] + ": " + f[1] + "\n")
+		headHTML.WriteString(f[0] + ": " + html.EscapeString(f[1]) + "<br>\n")
+	}
+	headHTML.WriteString("</div><br>\n")
+
+	out := note
+	out.Plain = strings.TrimLeft(strings.TrimRight(note.Plain, "\n")+"\n\n", "\n") + head.String() + "\n" + plain + "\n"
+	if note.HTML != "" || origHTML != "" {
+		if origHTML == "" {
+			origHTML = textToHTML(plain)
+		}
+		noteHTML := note.HTML
+		if noteHTML == "" && strings.TrimSpace(note.Plain) != "" {
+			noteHTML = textToHTML(note.Plain) + "\n<br>"
+		}
+		out.HTML = noteHTML + headHTML.String() + origHTML + "\n</div>\n"
+	}
+	return out
+}
+
+func textToHTML(s string) string {
+	s = html.EscapeString(strings.TrimRight(s, "\n"))
+	return "<div>" + strings.ReplaceAll(s, "\n", "<br>\n") + "</div>"
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6]|blockquote)>`)
+	blankRunPattern  = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText is a readable plain text rendering of an HTML body for quoting:
+// block ends become line breaks, tags are dropped and entities decoded.
+func htmlToText(s string) string {
+	s = scriptPattern.ReplaceAllString(s, "")
+	s = stylePattern.ReplaceAllString(s, "")
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankRunPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
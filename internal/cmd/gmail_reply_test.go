@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+const replyTestRaw = "From: Ada <ada@example.com>\r\nTo: me@example.com\r\nSubject: Plans\r\n\r\nSee attached.\r\n"
+
+// fakeReplyServer serves one original message (m1) with an attachment and
+// records what is sent or saved as a draft.
+type fakeReplyServer struct {
+	mu     sync.Mutex
+	sent   []gmail.Message
+	drafts []gmail.Draft
+}
+
+func newFakeReplyServer(t *testing.T) *fakeReplyServer {
+	t.Helper()
+	f := &fakeReplyServer{}
+	stubGmailService(t, http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakeReplyServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/users/me/messages/m1/attachments/att1"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": enc("PDFDATA"), "size": 7})
+	case strings.HasSuffix(path, "/users/me/messages/m1") && r.URL.Query().Get("format") == "raw":
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "raw": enc(replyTestRaw)})
+	case strings.HasSuffix(path, "/users/me/messages/m1"):
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":       "m1",
+			"threadId": "t1",
+			"payload": map[string]any{
+				"mimeType": "multipart/mixed",
+				"headers": []map[string]any{
+					{"name": "From", "value": "Ada <ada@example.com>"},
+					{"name": "To", "value": "me@example.com, bob@example.com"},
+					{"name": "Cc", "value": "carl@example.com"},
+					{"name": "Subject", "value": "Plans"},
+					{"name": "Date", "value": "Mon, 02 Jan 2006 15:04:05 +0000"},
+					{"name": "Message-ID", "value": "<orig@example.com>"},
+				},
+				"parts": []map[string]any{
+					{"mimeType": "text/plain", "body": map[string]any{"data": enc("See attached.\n\nAda")}},
+					{"mimeType": "application/pdf", "filename": "plan.pdf", "body": map[string]any{"attachmentId": "att1", "size": 7}},
+				},
+			},
+		})
+	case strings.HasSuffix(path, "/users/me/messages/send"):
+		var m gmail.Message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		f.sent = append(f.sent, m)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "sent1", "threadId": m.ThreadId})
+	case strings.HasSuffix(path, "/users/me/drafts"):
+		var d gmail.Draft
+		_ = json.NewDecoder(r.Body).Decode(&d)
+		f.drafts = append(f.drafts, d)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "d1", "message": map[string]any{"id": "dm1"}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func decodeSentRaw(t *testing.T, m gmail.Message) string {
+	t.Helper()
+	raw, err := base64.RawURLEncoding.DecodeString(m.Raw)
+	if err != nil {
+		t.Fatalf("decode raw: %v", err)
+	}
+	return string(raw)
+}
+
+func TestGmailReply_QuotesAndThreads(t *testing.T) {
+	f := newFakeReplyServer(t)
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailReplyCmd{}, []string{"m1", "--all", "--body", "Sounds good."}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err != nil {
+			t.Fatalf("reply: %v", err)
+		}
+	})
+	if len(f.sent) != 1 || f.sent[0].ThreadId != "t1" {
+		t.Fatalf("unexpected sends: %+v", f.sent)
+	}
+	raw := decodeSentRaw(t, f.sent[0])
+	for _, want := range []string{
+		"To: ada@example.com, bob@example.com\r\n",
+		"Cc: carl@example.com\r\n",
+		"Subject: Re: Plans\r\n",
+		"In-Reply-To: <orig@example.com>\r\n",
+		"Sounds good.\r\n\r\nOn 2006-01-02 15:04, Ada <ada@example.com> wrote:\r\n> See attached.\r\n>\r\n> Ada\r\n",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("missing %q in:\n%s", want, raw)
+		}
+	}
+}
+
+func TestGmailReply_DraftHTML(t *testing.T) {
+	f := newFakeReplyServer(t)
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailReplyCmd{}, []string{"m1", "--draft", "--body-html", "<p>Yes</p>"}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err != nil {
+			t.Fatalf("reply: %v", err)
+		}
+	})
+	if len(f.sent) != 0 || len(f.drafts) != 1 || f.drafts[0].Message.ThreadId != "t1" {
+		t.Fatalf("unexpected calls: sent=%d drafts=%+v", len(f.sent), f.drafts)
+	}
+	raw := decodeSentRaw(t, *f.drafts[0].Message)
+	for _, want := range []string{"To: ada@example.com\r\n", "<p>Yes</p>", `<blockquote class="gmail_quote"`, "<div>See attached.<br>"} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("missing %q in:\n%s", want, raw)
+		}
+	}
+}
+
+func TestGmailForward_Inline(t *testing.T) {
+	f := newFakeReplyServer(t)
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailForwardCmd{}, []string{"m1", "--to", "dan@example.com", "--body", "FYI"}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err != nil {
+			t.Fatalf("forward: %v", err)
+		}
+	})
+	if len(f.sent) != 1 || f.sent[0].ThreadId != "" {
+		t.Fatalf("unexpected sends: %+v", f.sent)
+	}
+	raw := decodeSentRaw(t, f.sent[0])
+	for _, want := range []string{
+		"Subject: Fwd: Plans\r\n",
+		"FYI\r\n\r\n---------- Forwarded message ---------\r\nFrom: Ada <ada@example.com>\r\n",
+		"Subject: Plans\r\n",
+		`filename="plan.pdf"`,
+		base64.StdEncoding.EncodeToString([]byte("PDFDATA")),
+	} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("missing %q in:\n%s", want, raw)
+		}
+	}
+}
+
+func TestGmailForward_AsAttachmentDraft(t *testing.T) {
+	f := newFakeReplyServer(t)
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailForwardCmd{}, []string{"m1", "--to", "dan@example.com", "--as-attachment", "--draft"}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err != nil {
+			t.Fatalf("forward: %v", err)
+		}
+	})
+	if len(f.drafts) != 1 {
+		t.Fatalf("expected a draft, got %d", len(f.drafts))
+	}
+	raw := decodeSentRaw(t, *f.drafts[0].Message)
+	for _, want := range []string{
+		"Content-Type: message/rfc822\r\nContent-Transfer-Encoding: 7bit\r\n",
+		`filename="Plans.eml"`,
+		replyTestRaw,
+	} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("missing %q in:\n%s", want, raw)
+		}
+	}
+	if strings.Contains(raw, "plan.pdf") {
+		t.Fatalf("attachments should travel inside the forwarded message:\n%s", raw)
+	}
+}
+
+func TestGmailForward_RequiresTo(t *testing.T) {
+	_ = newFakeReplyServer(t)
+	err := runKong(t, &GmailForwardCmd{}, []string{"m1"}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "--to") {
+		t.Fatalf("expected --to error, got %v", err)
+	}
+}
+
+func TestPrefixSubject(t *testing.T) {
+	for in, want := range map[string]string{"Plans": "Re: Plans", "RE: Plans": "RE: Plans", "": "Re:"} {
+		if got := prefixSubject("Re:", in); got != want {
+			t.Fatalf("prefixSubject(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -21,12 +21,21 @@ type GmailSendCmd struct {
 	Subject          string   `name:"subject" help:"Subject (required)"`
 	Body             string   `name:"body" help:"Body (plain text; required unless --body-html is set)"`
 	BodyHTML         string   `name:"body-html" help:"Body (HTML; optional)"`
+	BodyMarkdown     string   `name:"body-markdown" help:"Body (Markdown; rendered to HTML plus a plain text alternative, local images inlined)"`
+	BodyFile         string   `name:"body-file" help:"Read the body from a file (.md/.markdown = Markdown, .html/.htm = HTML, otherwise plain text)"`
 	ReplyToMessageID string   `name:"reply-to-message-id" aliases:"in-reply-to" help:"Reply to Gmail message ID (sets In-Reply-To/References and thread)"`
 	ThreadID         string   `name:"thread-id" help:"Reply within a Gmail thread (uses latest message for headers)"`
 	ReplyAll         bool     `name:"reply-all" help:"Auto-populate recipients from original message (requires --reply-to-message-id or --thread-id)"`
 	ReplyTo          string   `name:"reply-to" help:"Reply-To header address"`
 	Attach           []string `name:"attach" help:"Attachment file path (repeatable)"`
 	From             string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Template         string   `name:"template" help:"Body template file (Go text/template; .html/.htm files use html/template for the HTML body)"`
+	Data             string   `name:"data" help:"Mail merge rows (.csv with a header row, or .json array of objects); sends one message per row"`
+	Rate             int      `name:"rate" help:"Mail merge: maximum messages per minute (0 = unlimited)" default:"30"`
+	StartRow         int      `name:"start-row" help:"Mail merge: first data row to send (1-based); resume a merge that stopped at this row" default:"1"`
+	DryRun           bool     `name:"dry-run" help:"Write the rendered messages as .eml files instead of sending"`
+	PreviewDir       string   `name:"preview-dir" help:"Directory for --dry-run output" default:"gog-preview"`
+	GmailMIMEFlags
 }
 
 func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -48,6 +57,17 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return usage("--reply-all requires --reply-to-message-id or --thread-id")
 	}
 
+	if c.StartRow != 1 && c.Data == "" {
+		return usage("--start-row requires --data")
+	}
+
+	if c.Template != "" || c.Data != "" || c.DryRun {
+		if replyToMessageID != "" || threadID != "" || c.ReplyAll {
+			return usage("--template, --data and --dry-run cannot be combined with reply options")
+		}
+		return c.runMerge(ctx, flags, account)
+	}
+
 	// --to is required unless --reply-all is used
 	if strings.TrimSpace(c.To) == "" && !c.ReplyAll {
 		return usage("required: --to (or use --reply-all with --reply-to-message-id or --thread-id)")
@@ -55,8 +75,12 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if strings.TrimSpace(c.Subject) == "" {
 		return usage("required: --subject")
 	}
-	if strings.TrimSpace(c.Body) == "" && strings.TrimSpace(c.BodyHTML) == "" {
-		return usage("required: --body or --body-html")
+	body, err := resolveMailBody(c.Body, c.BodyHTML, c.BodyMarkdown, c.BodyFile)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(body.Plain) == "" && strings.TrimSpace(body.HTML) == "" {
+		return usage("required: --body, --body-html, --body-markdown or --body-file")
 	}
 
 	svc, err := newGmailService(ctx, account)
@@ -64,25 +88,9 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	// Determine the From address
-	fromAddr := account
-	sendingEmail := account // The email we're sending from (without display name)
-	if strings.TrimSpace(c.From) != "" {
-		// Validate that this is a configured send-as alias
-		var sa *gmail.SendAs
-		sa, err = svc.Users.Settings.SendAs.Get("me", c.From).Context(ctx).Do()
-		if err != nil {
-			return fmt.Errorf("invalid --from address %q: %w", c.From, err)
-		}
-		if sa.VerificationStatus != "accepted" {
-			return fmt.Errorf("--from address %q is not verified (status: %s)", c.From, sa.VerificationStatus)
-		}
-		sendingEmail = c.From
-		fromAddr = c.From
-		// Include display name if set
-		if sa.DisplayName != "" {
-			fromAddr = sa.DisplayName + " <" + c.From + ">"
-		}
+	fromAddr, sendingEmail, err := resolveFromAddress(ctx, svc, account, c.From)
+	if err != nil {
+		return err
 	}
 
 	// Fetch reply info (includes recipient headers for reply-all)
@@ -116,19 +124,24 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		atts = append(atts, mailAttachment{Path: p})
 	}
 
-	raw, err := buildRFC822(mailOptions{
+	opts := mailOptions{
 		From:        fromAddr,
 		To:          toRecipients,
 		Cc:          ccRecipients,
 		Bcc:         splitCSV(c.Bcc),
 		ReplyTo:     c.ReplyTo,
 		Subject:     c.Subject,
-		Body:        c.Body,
-		BodyHTML:    c.BodyHTML,
+		Body:        body.Plain,
+		BodyHTML:    body.HTML,
 		InReplyTo:   replyInfo.InReplyTo,
 		References:  replyInfo.References,
 		Attachments: atts,
-	})
+		Inline:      body.Inline,
+	}
+	if err := c.GmailMIMEFlags.apply(&opts); err != nil {
+		return err
+	}
+	raw, err := buildRFC822(opts)
 	if err != nil {
 		return err
 	}
@@ -145,7 +158,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"messageId": sent.Id,
 			"threadId":  sent.ThreadId,
 			"from":      fromAddr,
@@ -158,6 +171,27 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
+// resolveFromAddress returns the From header and bare sending address:
+// the account itself, or a verified send-as alias (with its display name).
+func resolveFromAddress(ctx context.Context, svc *gmail.Service, account, from string) (string, string, error) {
+	if strings.TrimSpace(from) == "" {
+		return account, account, nil
+	}
+	// Validate that this is a configured send-as alias
+	sa, err := svc.Users.Settings.SendAs.Get("me", from).Context(ctx).Do()
+	if err != nil {
+		return "", "", fmt.Errorf("invalid --from address %q: %w", from, err)
+	}
+	if sa.VerificationStatus != "accepted" {
+		return "", "", fmt.Errorf("--from address %q is not verified (status: %s)", from, sa.VerificationStatus)
+	}
+	// Include display name if set
+	if sa.DisplayName != "" {
+		return sa.DisplayName + " <" + from + ">", from, nil
+	}
+	return from, from, nil
+}
+
 // buildReplyAllRecipients constructs To and Cc lists for a reply-all.
 // Per RFC 5322: if Reply-To header is present, use it instead of From.
 // Reply-To (or From if no Reply-To) -> To
@@ -0,0 +1,432 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// mergeTemplate is satisfied by both text/template and html/template.
+type mergeTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// mergeTemplates are the parsed per-row templates; markdown (rendered to
+// HTML + plaintext after execution) replaces body and html.
+type mergeTemplates struct {
+	subject, body, html, markdown mergeTemplate
+	markdownDir                   string // base directory for local Markdown images
+}
+
+// mergeMessage is one rendered mail merge message.
+type mergeMessage struct {
+	Row int    `json:"row"`
+	To  string `json:"to"`
+	raw []byte
+}
+
+// runMerge renders --subject/--body/--body-html and --template once per
+// --data row (once with no data) and sends or, with --dry-run, writes the
+// results. Every row is rendered before anything is sent, so a template or
+// data error never leaves a merge half done.
+func (c *GmailSendCmd) runMerge(ctx context.Context, flags *RootFlags, account string) error {
+	u := ui.FromContext(ctx)
+	if c.Rate < 0 {
+		return usage("--rate must be 0 or more")
+	}
+	if c.StartRow < 1 {
+		return usage("--start-row must be 1 or more")
+	}
+
+	tmpls, err := c.parseMergeTemplates()
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]any{{}}
+	if c.Data != "" {
+		rows, err = loadMergeRows(c.Data)
+		if err != nil {
+			return newUsageError(err)
+		}
+		if len(rows) == 0 {
+			return usagef("%s has no rows", c.Data)
+		}
+		if c.StartRow > len(rows) {
+			return usagef("--start-row %d is past the last row (%d)", c.StartRow, len(rows))
+		}
+	}
+
+	var svc *gmail.Service
+	fromAddr := account
+	if strings.TrimSpace(c.From) != "" {
+		fromAddr = c.From
+	}
+	if !c.DryRun {
+		svc, err = newGmailService(ctx, account)
+		if err != nil {
+			return err
+		}
+		fromAddr, _, err = resolveFromAddress(ctx, svc, account, c.From)
+		if err != nil {
+			return err
+		}
+	}
+
+	msgs := make([]mergeMessage, 0, len(rows))
+	for i, row := range rows {
+		msg, err := c.renderMergeRow(i+1, row, fromAddr, tmpls)
+		if err != nil {
+			return newUsageError(err)
+		}
+		if msg.Row >= c.StartRow {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if c.DryRun {
+		return writeMergePreview(ctx, c.PreviewDir, msgs)
+	}
+
+	if len(msgs) > batchConfirmThreshold {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("send %d messages", len(msgs))); err != nil {
+			return err
+		}
+	}
+
+	var interval time.Duration
+	if c.Rate > 0 {
+		interval = time.Minute / time.Duration(c.Rate)
+	}
+	type sentMessage struct {
+		mergeMessage
+		MessageID string `json:"messageId"`
+		ThreadID  string `json:"threadId,omitempty"`
+	}
+	sent := make([]sentMessage, 0, len(msgs))
+
+	// Ctrl-C stops the merge between messages; the sent list and the resume
+	// hint are still written, so a rerun with --start-row never mails the
+	// same rows twice.
+	sendCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	var sendErr error
+	resumeRow, inFlight := 0, false
+	for i, m := range msgs {
+		if i > 0 && interval > 0 {
+			if err := sleepContext(sendCtx, interval); err != nil {
+				sendErr, resumeRow = err, m.Row
+				break
+			}
+		}
+		resp, err := svc.Users.Messages.Send("me", &gmail.Message{Raw: base64.RawURLEncoding.EncodeToString(m.raw)}).Context(sendCtx).Do()
+		if err != nil {
+			sendErr, resumeRow, inFlight = fmt.Errorf("row %d (%s): %w", m.Row, m.To, err), m.Row, true
+			break
+		}
+		sent = append(sent, sentMessage{mergeMessage: m, MessageID: resp.Id, ThreadID: resp.ThreadId})
+		if len(msgs) > 1 {
+			u.Err().Printf("Sent %d/%d", len(sent), len(msgs))
+		}
+	}
+	interrupted := sendErr != nil && sendCtx.Err() != nil
+	if sendErr != nil && !interrupted {
+		sendErr = fmt.Errorf("%w (%d of %d messages sent; resume with --start-row %d)", sendErr, len(sent), len(msgs), resumeRow)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"from":  fromAddr,
+			"count": len(sent),
+			"sent":  sent,
+		}); err != nil {
+			return err
+		}
+	} else {
+		for _, s := range sent {
+			u.Out().Printf("%d\t%s\t%s", s.Row, sanitizeTab(s.To), s.MessageID)
+		}
+	}
+	if interrupted {
+		u.Err().Printf("Interrupted after %d of %d messages; resume with --start-row %d", len(sent), len(msgs), resumeRow)
+		if inFlight {
+			// A send cut off mid-request may still have reached Gmail.
+			u.Err().Printf("Row %d was being sent; check Sent Mail before resuming", resumeRow)
+		}
+		return errInterrupted
+	}
+	return sendErr
+}
+
+// parseMergeTemplates parses --subject, --body, --body-html,
+// --body-markdown and --template. A .html/.htm template (optionally
+// suffixed .tmpl) is the HTML body and uses html/template, a .md/.markdown
+// template is Markdown, and anything else is the plain text body.
+func (c *GmailSendCmd) parseMergeTemplates() (mergeTemplates, error) {
+	var t mergeTemplates
+	if strings.TrimSpace(c.Subject) == "" {
+		return t, usage("required: --subject")
+	}
+	if c.BodyFile != "" {
+		return t, usage("use --template instead of --body-file with --data or --dry-run")
+	}
+	subject, err := template.New("subject").Option("missingkey=error").Parse(c.Subject)
+	if err != nil {
+		return t, usagef("invalid --subject template: %v", err)
+	}
+	t.subject = subject
+
+	bodySrc, htmlSrc, mdSrc := c.Body, c.BodyHTML, c.BodyMarkdown
+	t.markdownDir = "."
+	if c.Template != "" {
+		data, err := os.ReadFile(c.Template) //nolint:gosec // user-provided path
+		if err != nil {
+			return t, err
+		}
+		name := strings.ToLower(c.Template)
+		for _, suffix := range []string{".tmpl", ".tpl", ".gotmpl"} {
+			name = strings.TrimSuffix(name, suffix)
+		}
+		var dst *string
+		switch mailBodyKind(name) {
+		case mailBodyHTML:
+			dst = &htmlSrc
+		case mailBodyMarkdown:
+			dst = &mdSrc
+			t.markdownDir = filepath.Dir(c.Template)
+		default:
+			dst = &bodySrc
+		}
+		if strings.TrimSpace(*dst) != "" {
+			return t, usage("--template conflicts with --body, --body-html or --body-markdown")
+		}
+		*dst = string(data)
+	}
+	if strings.TrimSpace(mdSrc) != "" && (strings.TrimSpace(bodySrc) != "" || strings.TrimSpace(htmlSrc) != "") {
+		return t, usage("a Markdown body replaces --body and --body-html")
+	}
+	if strings.TrimSpace(bodySrc) == "" && strings.TrimSpace(htmlSrc) == "" && strings.TrimSpace(mdSrc) == "" {
+		return t, usage("required: --template, --body, --body-html or --body-markdown")
+	}
+
+	if strings.TrimSpace(bodySrc) != "" {
+		if t.body, err = template.New("body").Option("missingkey=error").Parse(bodySrc); err != nil {
+			return t, usagef("invalid body template: %v", err)
+		}
+	}
+	if strings.TrimSpace(htmlSrc) != "" {
+		if t.html, err = htmltemplate.New("html").Option("missingkey=error").Parse(htmlSrc); err != nil {
+			return t, usagef("invalid HTML template: %v", err)
+		}
+	}
+	if strings.TrimSpace(mdSrc) != "" {
+		if t.markdown, err = template.New("markdown").Option("missingkey=error").Parse(mdSrc); err != nil {
+			return t, usagef("invalid Markdown template: %v", err)
+		}
+	}
+	return t, nil
+}
+
+func (c *GmailSendCmd) renderMergeRow(n int, row map[string]any, from string, t mergeTemplates) (mergeMessage, error) {
+	render := func(t mergeTemplate) (string, error) {
+		if t == nil {
+			return "", nil
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, row); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	to := mergeRowList(row, "to", "email")
+	if len(to) == 0 {
+		to = splitCSV(c.To)
+	}
+	if len(to) == 0 {
+		return mergeMessage{}, fmt.Errorf("row %d: no recipient (add a \"to\" column or pass --to)", n)
+	}
+	cc := mergeRowList(row, "cc")
+	if len(cc) == 0 {
+		cc = splitCSV(c.Cc)
+	}
+	bcc := mergeRowList(row, "bcc")
+	if len(bcc) == 0 {
+		bcc = splitCSV(c.Bcc)
+	}
+
+	opts := mailOptions{From: from, To: to, Cc: cc, Bcc: bcc, ReplyTo: c.ReplyTo}
+	var err error
+	if opts.Subject, err = render(t.subject); err != nil {
+		return mergeMessage{}, fmt.Errorf("row %d: subject: %w", n, err)
+	}
+	opts.Subject = strings.TrimSpace(opts.Subject)
+	if opts.Body, err = render(t.body); err != nil {
+		return mergeMessage{}, fmt.Errorf("row %d: body: %w", n, err)
+	}
+	if opts.BodyHTML, err = render(t.html); err != nil {
+		return mergeMessage{}, fmt.Errorf("row %d: HTML body: %w", n, err)
+	}
+	if t.markdown != nil {
+		src, err := render(t.markdown)
+		if err != nil {
+			return mergeMessage{}, fmt.Errorf("row %d: Markdown body: %w", n, err)
+		}
+		md, err := renderMarkdown([]byte(src), t.markdownDir)
+		if err != nil {
+			return mergeMessage{}, fmt.Errorf("row %d: %w", n, err)
+		}
+		opts.Body, opts.BodyHTML, opts.Inline = md.Plain, md.HTML, md.Inline
+	}
+	for _, p := range append(append([]string(nil), c.Attach...), mergeRowList(row, "attachments", "attach")...) {
+		opts.Attachments = append(opts.Attachments, mailAttachment{Path: p})
+	}
+
+	if err := c.GmailMIMEFlags.apply(&opts); err != nil {
+		return mergeMessage{}, err
+	}
+	raw, err := buildRFC822(opts)
+	if err != nil {
+		return mergeMessage{}, fmt.Errorf("row %d: %w", n, err)
+	}
+	return mergeMessage{Row: n, To: strings.Join(to, ", "), raw: raw}, nil
+}
+
+// mergeRowList reads the first present column of keys (case-insensitive) as
+// a list: a JSON array, or a string split on commas and semicolons.
+func mergeRowList(row map[string]any, keys ...string) []string {
+	for _, key := range keys {
+		for k, v := range row {
+			if !strings.EqualFold(strings.TrimSpace(k), key) {
+				continue
+			}
+			var out []string
+			switch val := v.(type) {
+			case string:
+				for _, part := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ';' }) {
+					if part = strings.TrimSpace(part); part != "" {
+						out = append(out, part)
+					}
+				}
+			case []any:
+				for _, item := range val {
+					if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+						out = append(out, strings.TrimSpace(s))
+					}
+				}
+			}
+			if len(out) > 0 {
+				return out
+			}
+		}
+	}
+	return nil
+}
+
+// loadMergeRows reads a CSV file (first row = column names) or a JSON array
+// of objects.
+func loadMergeRows(path string) ([]map[string]any, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var rows []map[string]any
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("parse %s: expected a JSON array of objects: %w", path, err)
+		}
+		return rows, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	header := records[0]
+	rows := make([]map[string]any, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if len(rec) > len(header) {
+			return nil, fmt.Errorf("%s line %d: %d fields, header has %d", path, i+2, len(rec), len(header))
+		}
+		row := make(map[string]any, len(header))
+		for j, name := range header {
+			val := ""
+			if j < len(rec) {
+				val = rec[j]
+			}
+			row[strings.TrimSpace(name)] = val
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// writeMergePreview writes each message as NNN-<recipient>.eml in dir.
+func writeMergePreview(ctx context.Context, dir string, msgs []mergeMessage) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	type previewFile struct {
+		mergeMessage
+		Path string `json:"path"`
+	}
+	files := make([]previewFile, 0, len(msgs))
+	for _, m := range msgs {
+		path := filepath.Join(dir, fmt.Sprintf("%03d-%s.eml", m.Row, sanitizeAccountForPath(strings.SplitN(m.To, ",", 2)[0])))
+		if err := os.WriteFile(path, m.raw, 0o600); err != nil {
+			return err
+		}
+		files = append(files, previewFile{mergeMessage: m, Path: path})
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dryRun": true,
+			"dir":    dir,
+			"count":  len(files),
+			"files":  files,
+		})
+	}
+	u := ui.FromContext(ctx)
+	for _, f := range files {
+		u.Out().Printf("%d\t%s\t%s", f.Row, sanitizeTab(f.To), f.Path)
+	}
+	u.Err().Printf("Dry run: wrote %d messages to %s (nothing sent)", len(files), dir)
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
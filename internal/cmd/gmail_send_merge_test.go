@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func writeMergeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadMergeRows(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := writeMergeFile(t, dir, "rows.csv", "\xef\xbb\xbfto, name\nada@example.com,Ada\n\n\"bob@example.com; carl@example.com\",\"Bob, Jr.\"\n")
+	rows, err := loadMergeRows(csvPath)
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(rows) != 2 || rows[1]["name"] != "Bob, Jr." {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	if got := mergeRowList(rows[1], "to"); len(got) != 2 || got[1] != "carl@example.com" {
+		t.Fatalf("unexpected to list: %v", got)
+	}
+
+	jsonPath := writeMergeFile(t, dir, "rows.json", `[{"To": ["x@example.com"], "n": 1}]`)
+	rows, err = loadMergeRows(jsonPath)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if got := mergeRowList(rows[0], "to"); len(got) != 1 || got[0] != "x@example.com" {
+		t.Fatalf("unexpected json to: %v", got)
+	}
+
+	if _, err := loadMergeRows(writeMergeFile(t, dir, "bad.csv", "to\na,b\n")); err == nil {
+		t.Fatalf("expected error for extra fields")
+	}
+}
+
+func TestGmailSendMerge_DryRun(t *testing.T) {
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+	newGmailService = func(context.Context, string) (*gmail.Service, error) {
+		return nil, errors.New("dry run must not call the API")
+	}
+
+	dir := t.TempDir()
+	tmpl := writeMergeFile(t, dir, "notice.txt.tmpl", "Hi {{.name}},\nyour balance is {{.balance}}.\n")
+	htmlTmpl := writeMergeFile(t, dir, "notice.html.tmpl", "<p>Hi {{.name}}</p>")
+	attach := writeMergeFile(t, dir, "invoice-ada.pdf", "%PDF")
+	data := writeMergeFile(t, dir, "rows.csv", "to,name,balance,attachments\nada@example.com,Ada,10,"+attach+"\nbob@example.com,<Bob>,20,\n")
+	out := filepath.Join(dir, "preview")
+
+	stdout := captureStdout(t, func() {
+		err := runKong(t, &GmailSendCmd{}, []string{
+			"--subject", "Statement for {{.name}}", "--template", tmpl, "--data", data,
+			"--dry-run", "--preview-dir", out,
+		}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+		if err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+	})
+	var res struct {
+		Count int `json:"count"`
+		Files []struct {
+			Row  int    `json:"row"`
+			To   string `json:"to"`
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("json: %v\n%s", err, stdout)
+	}
+	if res.Count != 2 || res.Files[1].To != "bob@example.com" || filepath.Base(res.Files[0].Path) != "001-ada_example_com.eml" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	first, err := os.ReadFile(res.Files[0].Path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, want := range []string{"To: ada@example.com\r\n", "Subject: Statement for Ada\r\n", "your balance is 10.", `filename="invoice-ada.pdf"`} {
+		if !strings.Contains(string(first), want) {
+			t.Fatalf("missing %q in:\n%s", want, first)
+		}
+	}
+
+	// HTML templates escape row values.
+	_ = captureStdout(t, func() {
+		err := runKong(t, &GmailSendCmd{}, []string{
+			"--subject", "Hi", "--template", htmlTmpl, "--data", data, "--dry-run", "--preview-dir", out,
+		}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+		if err != nil {
+			t.Fatalf("html dry run: %v", err)
+		}
+	})
+	second, err := os.ReadFile(filepath.Join(out, "002-bob_example_com.eml"))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(second), "<p>Hi &lt;Bob&gt;</p>") || !strings.Contains(string(second), "text/html") {
+		t.Fatalf("unexpected html message:\n%s", second)
+	}
+}
+
+func TestGmailSendMerge_Send(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/users/me/messages/send") {
+			http.NotFound(w, r)
+			return
+		}
+		var msg gmail.Message
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		raw, _ := base64.RawURLEncoding.DecodeString(msg.Raw)
+		mu.Lock()
+		sent = append(sent, string(raw))
+		n := len(sent)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": strings.Repeat("m", n), "threadId": "t"})
+	}))
+
+	dir := t.TempDir()
+	data := writeMergeFile(t, dir, "rows.json", `[{"to": "ada@example.com", "name": "Ada"}, {"email": "bob@example.com", "name": "Bob"}]`)
+
+	stdout := captureStdout(t, func() {
+		err := runKong(t, &GmailSendCmd{}, []string{
+			"--subject", "Hello {{.name}}", "--body", "Dear {{.name}}", "--data", data, "--rate", "6000",
+		}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	})
+	if len(sent) != 2 || !strings.Contains(sent[1], "To: bob@example.com\r\n") || !strings.Contains(sent[1], "Dear Bob") {
+		t.Fatalf("unexpected sends: %q", sent)
+	}
+	if !strings.Contains(stdout, `"count": 2`) || !strings.Contains(stdout, `"messageId": "mm"`) {
+		t.Fatalf("unexpected output: %s", stdout)
+	}
+
+	// A template error on any row stops the merge before anything is sent.
+	sent = nil
+	err := runKong(t, &GmailSendCmd{}, []string{
+		"--subject", "Hello {{.nmae}}", "--body", "x", "--data", data,
+	}, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "row 1") || len(sent) != 0 {
+		t.Fatalf("expected template error before sending, got %v (sent %d)", err, len(sent))
+	}
+}
+
+func TestGmailSendMerge_FailureReportsSentAndResumes(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	failTo := "bob@example.com"
+	stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg gmail.Message
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		raw, _ := base64.RawURLEncoding.DecodeString(msg.Raw)
+		mu.Lock()
+		defer mu.Unlock()
+		if failTo != "" && strings.Contains(string(raw), "To: "+failTo+"\r\n") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "Invalid To header"}})
+			return
+		}
+		sent = append(sent, string(raw))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "m" + strings.Repeat("x", len(sent))})
+	}))
+
+	data := writeMergeFile(t, t.TempDir(), "rows.csv", "to,name\nada@example.com,Ada\nbob@example.com,Bob\ncy@example.com,Cy\n")
+	args := []string{"--subject", "Hi {{.name}}", "--body", "x", "--data", data, "--rate", "0"}
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runKong(t, &GmailSendCmd{}, args, testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"})
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "row 2") || !strings.Contains(runErr.Error(), "--start-row 2") {
+		t.Fatalf("expected row 2 failure with resume hint, got %v", runErr)
+	}
+	if !strings.Contains(stdout, `"count": 1`) || !strings.Contains(stdout, `"to": "ada@example.com"`) {
+		t.Fatalf("expected the sent rows on failure, got %s", stdout)
+	}
+
+	failTo = ""
+	sent = nil
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &GmailSendCmd{}, append(args, "--start-row", "2"), testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+	})
+	if len(sent) != 2 || !strings.Contains(sent[0], "To: bob@example.com\r\n") || !strings.Contains(sent[1], "To: cy@example.com\r\n") {
+		t.Fatalf("resume should send rows 2 and 3 only, got %q", sent)
+	}
+
+	if err := runKong(t, &GmailSendCmd{}, append(args, "--start-row", "4"), testJSONContext(t, io.Discard), &RootFlags{Account: "me@example.com"}); err == nil {
+		t.Fatalf("expected --start-row past the last row to fail")
+	}
+}
+
+func TestGmailSendMerge_InterruptReportsSentAndResumes(t *testing.T) {
+	// Ctrl-C as soon as the first message is reported sent.
+	var stderr bytes.Buffer
+	var cancel context.CancelFunc
+	ctx, cancel := context.WithCancel(testJSONContext(t, writerFunc(func(p []byte) (int, error) {
+		if strings.Contains(string(p), "Sent 1/3") {
+			cancel()
+		}
+		return stderr.Write(p)
+	})))
+	defer cancel()
+
+	var sent int
+	stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1"})
+	}))
+
+	data := writeMergeFile(t, t.TempDir(), "rows.csv", "to,name\nada@example.com,Ada\nbob@example.com,Bob\ncy@example.com,Cy\n")
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runKong(t, &GmailSendCmd{}, []string{
+			"--subject", "Hi {{.name}}", "--body", "x", "--data", data, "--rate", "600",
+		}, ctx, &RootFlags{Account: "me@example.com"})
+	})
+	if ExitCode(runErr) != 130 {
+		t.Fatalf("expected interrupted exit, got %v", runErr)
+	}
+	if sent != 1 {
+		t.Fatalf("expected one message sent before the interrupt, got %d", sent)
+	}
+	if !strings.Contains(stdout, `"count": 1`) || !strings.Contains(stdout, `"to": "ada@example.com"`) {
+		t.Fatalf("expected the sent rows on interrupt, got %s", stdout)
+	}
+	if !strings.Contains(stderr.String(), "resume with --start-row 2") {
+		t.Fatalf("expected resume hint, got %q", stderr.String())
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
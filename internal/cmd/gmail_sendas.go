@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/alecthomas/kong"
 	"google.golang.org/api/gmail/v1"
@@ -46,7 +45,7 @@ func (c *GmailSendAsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"sendAs": resp.SendAs})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"sendAs": resp.SendAs})
 	}
 
 	if len(resp.SendAs) == 0 {
@@ -54,8 +53,8 @@ func (c *GmailSendAsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "EMAIL\tDISPLAY NAME\tDEFAULT\tVERIFIED\tTREAT AS ALIAS")
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "EMAIL\tDISPLAY NAME\tDEFAULT\tVERIFIED\tTREAT AS ALIAS")
 	for _, sa := range resp.SendAs {
 		isDefault := ""
 		if sa.IsDefault {
@@ -69,10 +68,10 @@ func (c *GmailSendAsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		if sa.TreatAsAlias {
 			treatAsAlias = sendAsYes
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			sa.SendAsEmail, sa.DisplayName, isDefault, verified, treatAsAlias)
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
 
@@ -102,7 +101,7 @@ func (c *GmailSendAsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"sendAs": sa})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"sendAs": sa})
 	}
 
 	u.Out().Printf("send_as_email\t%s", sa.SendAsEmail)
@@ -154,7 +153,7 @@ func (c *GmailSendAsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"sendAs": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"sendAs": created})
 	}
 
 	u.Out().Printf("send_as_email\t%s", created.SendAsEmail)
@@ -189,7 +188,7 @@ func (c *GmailSendAsVerifyCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"email":   sendAsEmail,
 			"message": "Verification email sent",
 		})
@@ -225,7 +224,7 @@ func (c *GmailSendAsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"email":   sendAsEmail,
 			"deleted": true,
 		})
@@ -289,7 +288,7 @@ func (c *GmailSendAsUpdateCmd) Run(ctx context.Context, kctx *kong.Context, flag
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"sendAs": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"sendAs": updated})
 	}
 
 	u.Out().Printf("Updated send-as alias: %s", updated.SendAsEmail)
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -25,11 +27,14 @@ var (
 )
 
 type GmailWatchCmd struct {
-	Start  GmailWatchStartCmd  `cmd:"" name:"start" help:"Start Gmail watch for Pub/Sub"`
-	Status GmailWatchStatusCmd `cmd:"" name:"status" help:"Show stored watch state"`
-	Renew  GmailWatchRenewCmd  `cmd:"" name:"renew" help:"Renew Gmail watch using stored config"`
-	Stop   GmailWatchStopCmd   `cmd:"" name:"stop" help:"Stop Gmail watch and clear stored state"`
-	Serve  GmailWatchServeCmd  `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Start      GmailWatchStartCmd      `cmd:"" name:"start" help:"Start Gmail watch for Pub/Sub"`
+	Status     GmailWatchStatusCmd     `cmd:"" name:"status" help:"Show stored watch state"`
+	Renew      GmailWatchRenewCmd      `cmd:"" name:"renew" help:"Renew Gmail watch using stored config"`
+	Stop       GmailWatchStopCmd       `cmd:"" name:"stop" help:"Stop Gmail watch and clear stored state"`
+	Serve      GmailWatchServeCmd      `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Pull       GmailWatchPullCmd       `cmd:"" name:"pull" help:"Consume a Pub/Sub pull subscription (no public endpoint needed)"`
+	Poll       GmailWatchPollCmd       `cmd:"" name:"poll" help:"Poll mailbox history (no Pub/Sub needed)"`
+	Deliveries GmailWatchDeliveriesCmd `cmd:"" name:"deliveries" help:"Inspect and retry the hook delivery queue of watch serve"`
 }
 
 type GmailWatchStartCmd struct {
@@ -38,6 +43,9 @@ type GmailWatchStartCmd struct {
 	TTL         string   `name:"ttl" help:"Renew after duration (seconds or Go duration)"`
 	HookURL     string   `name:"hook-url" help:"Webhook URL to forward messages"`
 	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret  string   `name:"hook-secret" help:"HMAC-SHA256 key for the X-Gog-Signature header on hook requests"`
+	HookExec    string   `name:"hook-exec" help:"Command to run per payload, with the payload JSON on stdin (instead of --hook-url)"`
+	Rules       string   `name:"rules" help:"Routing rules file (YAML or JSON) sending matching messages to other hooks or dropping them"`
 	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
 }
@@ -54,14 +62,31 @@ func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if err != nil {
 		return err
 	}
+	if c.HookURL != "" && c.HookExec != "" {
+		return usage("--hook-url and --hook-exec are mutually exclusive")
+	}
+	if c.HookExec != "" && (c.HookToken != "" || c.HookSecret != "") {
+		return usage("--hook-token and --hook-secret only apply to --hook-url")
+	}
 	maxChanged := flagProvided(kctx, "max-bytes")
-	hook, err := hookFromFlags(c.HookURL, c.HookToken, c.IncludeBody, c.MaxBytes, maxChanged, false)
+	hook, err := hookFromFlags(cmp.Or(c.HookURL, c.HookExec), c.HookToken, c.IncludeBody, c.MaxBytes, maxChanged, false)
 	if err != nil {
-		if errors.Is(err, errNoHookConfigured) {
-			hook = nil
-		} else {
+		if !errors.Is(err, errNoHookConfigured) {
 			return err
 		}
+		if c.HookSecret != "" {
+			return usage("--hook-url required when using --hook-secret")
+		}
+		hook = nil
+	} else {
+		hook.Secret = c.HookSecret
+		if c.HookExec != "" {
+			hook.URL, hook.Exec = "", c.HookExec
+		}
+	}
+	rules, err := resolveWatchRules(ctx, account, c.Rules, nil)
+	if err != nil {
+		return err
 	}
 
 	svc, err := newGmailService(ctx, account)
@@ -81,6 +106,7 @@ func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if err != nil {
 		return err
 	}
+	state.Rules = rules
 
 	store, err := newGmailWatchStore(account)
 	if err != nil {
@@ -148,6 +174,7 @@ func (c *GmailWatchRenewCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if ttl == 0 {
 		updated.RenewAfterMs = state.RenewAfterMs
 	}
+	updated.Rules = state.Rules
 
 	if err := store.Update(func(s *gmailWatchState) error {
 		*s = updated
@@ -176,15 +203,28 @@ func (c *GmailWatchStopCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if err != nil {
 		return err
 	}
-	if stopErr := svc.Users.Stop("me").Do(); stopErr != nil {
-		return stopErr
+	store, storeErr := newGmailWatchStore(account)
+	stop := func() error {
+		if stopErr := svc.Users.Stop("me").Do(); stopErr != nil {
+			return stopErr
+		}
+		if storeErr == nil {
+			_ = os.Remove(store.path)
+		}
+		return nil
 	}
-	store, err := newGmailWatchStore(account)
-	if err == nil && store.path != "" {
-		_ = os.Remove(store.path)
+	if storeErr == nil {
+		// Hold the state lock so a running `watch serve` cannot renew the
+		// watch between users.stop and removing its state.
+		err = withFileLock(store.path+".lock", stop)
+	} else {
+		err = stop()
+	}
+	if err != nil {
+		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"stopped": true})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"stopped": true})
 	}
 	u.Out().Printf("stopped\ttrue")
 	return nil
@@ -200,6 +240,11 @@ type GmailWatchServeCmd struct {
 	SharedToken  string `name:"token" help:"Shared token for x-gog-token or ?token="`
 	HookURL      string `name:"hook-url" help:"Webhook URL to forward messages"`
 	HookToken    string `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret   string `name:"hook-secret" help:"HMAC-SHA256 key for the X-Gog-Signature header on hook requests"`
+	HookExec     string `name:"hook-exec" help:"Command to run per payload, with the payload JSON on stdin (instead of --hook-url)"`
+	HookAttempts int    `name:"hook-max-attempts" help:"Delivery attempts before a payload moves to the dead-letter queue" default:"8"`
+	Rules        string `name:"rules" help:"Routing rules file (YAML or JSON); default: rules saved in watch state"`
+	AutoRenew    bool   `name:"auto-renew" help:"Renew the Gmail watch before it expires (users.watch); --no-auto-renew to disable" default:"true" negatable:""`
 	IncludeBody  bool   `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes     int    `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
 	SaveHook     bool   `name:"save-hook" help:"Persist hook settings to watch state"`
@@ -207,10 +252,17 @@ type GmailWatchServeCmd struct {
 
 func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
+	accounts, err := fanoutAccounts(flags)
 	if err != nil {
 		return err
 	}
+	if accounts == nil {
+		account, accountErr := requireAccount(flags)
+		if accountErr != nil {
+			return accountErr
+		}
+		accounts = []string{account}
+	}
 	if !strings.HasPrefix(c.Path, "/") {
 		return usage("--path must start with '/'")
 	}
@@ -226,23 +278,165 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if c.OIDCAudience != "" && !c.VerifyOIDC {
 		return usage("--oidc-audience requires --verify-oidc")
 	}
+	if c.HookAttempts < 1 {
+		return usage("--hook-max-attempts must be >= 1")
+	}
 
-	store, err := loadGmailWatchStore(account)
-	if err != nil {
-		return err
+	validator := (*idtoken.Validator)(nil)
+	if c.VerifyOIDC {
+		validator, err = newOIDCValidator(ctx)
+		if err != nil {
+			return err
+		}
 	}
+
+	servers := make([]*gmailWatchServer, 0, len(accounts))
+	for _, account := range accounts {
+		store, found, openErr := openGmailWatchStore(account)
+		if openErr != nil {
+			return openErr
+		}
+		if !found {
+			if flags.AllAccounts {
+				u.Err().Printf("watch: skipping %s (no watch state)", account)
+				continue
+			}
+			if len(accounts) > 1 {
+				return fmt.Errorf("%s: watch state not found; run gmail watch start", account)
+			}
+			return errors.New("watch state not found; run gmail watch start")
+		}
+		server, serverErr := c.accountServer(ctx, kctx, account, store, validator)
+		if serverErr != nil {
+			return serverErr
+		}
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return usage("no account has watch state (run: gog gmail watch start)")
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	names := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if server.outbox != nil {
+			go server.runOutbox(runCtx)
+		}
+		if c.AutoRenew {
+			go server.runRenewal(runCtx)
+		}
+		names = append(names, server.cfg.Account)
+	}
+	handler := servers[0]
+	if len(servers) > 1 {
+		handler = newGmailWatchMux(servers)
+	}
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
+	if len(servers) > 1 {
+		u.Err().Printf("watch: serving %s", strings.Join(names, ", "))
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
+
+// accountServer builds the push handler for one account from its watch
+// state and the hook flags.
+func (c *GmailWatchServeCmd) accountServer(ctx context.Context, kctx *kong.Context, account string, store *gmailWatchStore, validator *idtoken.Validator) (*gmailWatchServer, error) {
+	u := ui.FromContext(ctx)
 	state := store.Get()
+	hook, includeBody, maxBytes, err := resolveWatchHook(kctx, state, c.HookURL, c.HookToken, c.HookSecret, c.HookExec, c.IncludeBody, c.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := resolveWatchRules(ctx, account, c.Rules, state.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if c.SaveHook {
+		if err := saveWatchHook(store, hook, c.Rules != "", rules); err != nil {
+			return nil, err
+		}
+	}
 
-	hookURL := c.HookURL
-	hookToken := c.HookToken
-	includeBody := c.IncludeBody
-	maxBytes := c.MaxBytes
+	cfg := newWatchServeConfig(account, hook, includeBody, maxBytes)
+	cfg.Bind = c.Bind
+	cfg.Port = c.Port
+	cfg.Path = c.Path
+	cfg.VerifyOIDC = c.VerifyOIDC
+	cfg.OIDCEmail = c.OIDCEmail
+	cfg.OIDCAudience = c.OIDCAudience
+	cfg.SharedToken = c.SharedToken
+	cfg.HookMaxAttempts = c.HookAttempts
+	cfg.Rules = rules
 
-	if hookURL == "" && state.Hook != nil {
+	server := &gmailWatchServer{
+		cfg:        cfg,
+		store:      store,
+		validator:  validator,
+		newService: newGmailService,
+		hookClient: &http.Client{Timeout: cfg.HookTimeout},
+		logf:       u.Err().Printf,
+		warnf:      u.Err().Printf,
+	}
+	if cfg.hasHookTargets() {
+		server.outbox, err = openGmailHookOutbox(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return server, nil
+}
+
+// newGmailWatchMux returns the handler for a multi-account serve: it checks
+// path and auth once and passes each push to the server of the account it
+// names.
+func newGmailWatchMux(servers []*gmailWatchServer) *gmailWatchServer {
+	first := servers[0]
+	mux := &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Bind:         first.cfg.Bind,
+			Port:         first.cfg.Port,
+			Path:         first.cfg.Path,
+			VerifyOIDC:   first.cfg.VerifyOIDC,
+			OIDCEmail:    first.cfg.OIDCEmail,
+			OIDCAudience: first.cfg.OIDCAudience,
+			SharedToken:  first.cfg.SharedToken,
+		},
+		validator: first.validator,
+		accounts:  make(map[string]*gmailWatchServer, len(servers)),
+		logf:      first.logf,
+		warnf:     first.warnf,
+	}
+	for _, s := range servers {
+		mux.accounts[strings.ToLower(s.cfg.Account)] = s
+	}
+	return mux
+}
+
+// resolveWatchHook merges the hook flags with the hook saved in the watch
+// state (flags win) and returns the hook (nil when none is configured) plus
+// the effective body options.
+func resolveWatchHook(kctx *kong.Context, state gmailWatchState, hookURL, hookToken, hookSecret, hookExec string, includeBody bool, maxBytes int) (*gmailWatchHook, bool, int, error) {
+	if hookURL != "" && hookExec != "" {
+		return nil, false, 0, usage("--hook-url and --hook-exec are mutually exclusive")
+	}
+	if hookURL == "" && hookExec == "" && state.Hook != nil {
 		hookURL = state.Hook.URL
+		hookExec = state.Hook.Exec
 		if !flagProvided(kctx, "hook-token") {
 			hookToken = state.Hook.Token
 		}
+		if !flagProvided(kctx, "hook-secret") {
+			hookSecret = state.Hook.Secret
+		}
 		if !flagProvided(kctx, "include-body") {
 			includeBody = state.Hook.IncludeBody
 		}
@@ -251,42 +445,51 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		}
 	}
 
+	if hookExec != "" && (hookToken != "" || hookSecret != "") {
+		return nil, false, 0, usage("--hook-token and --hook-secret only apply to --hook-url")
+	}
+
 	maxChanged := flagProvided(kctx, "max-bytes")
-	hook, err := hookFromFlags(hookURL, hookToken, includeBody, maxBytes, maxChanged, true)
+	hook, err := hookFromFlags(cmp.Or(hookURL, hookExec), hookToken, includeBody, maxBytes, maxChanged, true)
 	if err != nil {
 		if errors.Is(err, errNoHookConfigured) {
-			hook = nil
-		} else {
-			return err
+			if hookSecret != "" {
+				return nil, false, 0, usage("--hook-url required when using --hook-secret")
+			}
+			return nil, includeBody, maxBytes, nil
 		}
+		return nil, false, 0, err
 	}
-	if c.SaveHook && hook != nil {
-		if updateErr := store.Update(func(s *gmailWatchState) error {
-			s.Hook = hook
-			s.UpdatedAtMs = time.Now().UnixMilli()
-			return nil
-		}); updateErr != nil {
-			return updateErr
-		}
+	hook.Secret = hookSecret
+	if hookExec != "" {
+		hook.URL, hook.Exec = "", hookExec
 	}
+	return hook, includeBody, maxBytes, nil
+}
 
-	validator := (*idtoken.Validator)(nil)
-	if c.VerifyOIDC {
-		validator, err = newOIDCValidator(ctx)
-		if err != nil {
-			return err
-		}
+// saveWatchHook persists the effective hook (when set) and, when a rules
+// file was given, the rules for --save-hook.
+func saveWatchHook(store *gmailWatchStore, hook *gmailWatchHook, saveRules bool, rules []gmailWatchRule) error {
+	if hook == nil && !saveRules {
+		return nil
 	}
+	return store.Update(func(s *gmailWatchState) error {
+		if hook != nil {
+			s.Hook = hook
+		}
+		if saveRules {
+			s.Rules = rules
+		}
+		s.UpdatedAtMs = time.Now().UnixMilli()
+		return nil
+	})
+}
 
+// newWatchServeConfig is the push/pull handler config shared by `watch
+// serve` and `watch pull`.
+func newWatchServeConfig(account string, hook *gmailWatchHook, includeBody bool, maxBytes int) gmailWatchServeConfig {
 	cfg := gmailWatchServeConfig{
 		Account:      account,
-		Bind:         c.Bind,
-		Port:         c.Port,
-		Path:         c.Path,
-		VerifyOIDC:   c.VerifyOIDC,
-		OIDCEmail:    c.OIDCEmail,
-		OIDCAudience: c.OIDCAudience,
-		SharedToken:  c.SharedToken,
 		HookTimeout:  defaultHookRequestTimeoutSec * time.Second,
 		HistoryMax:   defaultHistoryMaxResults,
 		ResyncMax:    defaultHistoryResyncMax,
@@ -296,40 +499,21 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	}
 	if hook != nil {
 		cfg.HookURL = hook.URL
+		cfg.HookExec = hook.Exec
 		cfg.HookToken = hook.Token
+		cfg.HookSecret = hook.Secret
 		cfg.IncludeBody = hook.IncludeBody
 		cfg.MaxBodyBytes = hook.MaxBytes
 	}
-
 	if cfg.MaxBodyBytes <= 0 {
 		cfg.MaxBodyBytes = defaultHookMaxBytes
 	}
-
-	hookClient := &http.Client{Timeout: cfg.HookTimeout}
-	server := &gmailWatchServer{
-		cfg:        cfg,
-		store:      store,
-		validator:  validator,
-		newService: newGmailService,
-		hookClient: hookClient,
-		logf:       u.Err().Printf,
-		warnf:      u.Err().Printf,
-	}
-
-	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
-	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
-
-	httpServer := &http.Server{
-		Addr:              addr,
-		Handler:           server,
-		ReadHeaderTimeout: 5 * time.Second,
-	}
-	return listenAndServe(httpServer)
+	return cfg
 }
 
 func writeWatchState(ctx context.Context, state gmailWatchState) error {
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"watch": state})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"watch": state})
 	}
 	u := ui.FromContext(ctx)
 	u.Out().Printf("account\t%s", state.Account)
@@ -351,7 +535,11 @@ func writeWatchState(ctx context.Context, state gmailWatchState) error {
 		u.Out().Printf("updated_at\t%s", formatUnixMillis(state.UpdatedAtMs))
 	}
 	if state.Hook != nil {
-		u.Out().Printf("hook_url\t%s", state.Hook.URL)
+		if state.Hook.Exec != "" {
+			u.Out().Printf("hook_exec\t%s", state.Hook.Exec)
+		} else {
+			u.Out().Printf("hook_url\t%s", state.Hook.URL)
+		}
 		if state.Hook.IncludeBody {
 			u.Out().Printf("hook_include_body\ttrue")
 		}
@@ -361,6 +549,12 @@ func writeWatchState(ctx context.Context, state gmailWatchState) error {
 		if state.Hook.Token != "" {
 			u.Out().Printf("hook_token\t%s", state.Hook.Token)
 		}
+		if state.Hook.Secret != "" {
+			u.Out().Printf("hook_signed\ttrue")
+		}
+	}
+	for _, r := range state.Rules {
+		u.Out().Printf("rule\t%s\t%s", r.Name, describeWatchRule(r))
 	}
 	if state.LastDeliveryStatus != "" {
 		u.Out().Printf("last_delivery_status\t%s", state.LastDeliveryStatus)
@@ -24,6 +24,7 @@ func TestGmailWatchRenewAndStop_JSON(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
@@ -100,6 +101,7 @@ func TestGmailWatchStatusAndStop_Text(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
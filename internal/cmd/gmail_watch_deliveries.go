@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailWatchDeliveriesCmd struct {
+	List  GmailWatchDeliveriesListCmd  `cmd:"" name:"list" help:"List queued and dead-lettered hook deliveries"`
+	Retry GmailWatchDeliveriesRetryCmd `cmd:"" name:"retry" help:"Deliver queued or dead-lettered payloads now"`
+	Purge GmailWatchDeliveriesPurgeCmd `cmd:"" name:"purge" help:"Delete queued or dead-lettered payloads"`
+}
+
+// hookDeliveryView is a delivery plus the queue it is in.
+type hookDeliveryView struct {
+	gmailHookDelivery
+	State string `json:"state"` // pending|dead
+}
+
+// listHookDeliveries returns the pending and/or dead-lettered deliveries,
+// oldest first within each queue.
+func listHookDeliveries(o *gmailHookOutbox, pending, dead bool) ([]hookDeliveryView, error) {
+	var out []hookDeliveryView
+	for _, q := range []struct {
+		name string
+		on   bool
+	}{{hookQueuePending, pending}, {hookQueueDead, dead}} {
+		if !q.on {
+			continue
+		}
+		list, err := o.list(q.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list {
+			out = append(out, hookDeliveryView{gmailHookDelivery: d, State: q.name})
+		}
+	}
+	return out, nil
+}
+
+// selectHookDeliveries narrows all to ids (all of them when ids is empty).
+func selectHookDeliveries(all []hookDeliveryView, ids []string) ([]hookDeliveryView, error) {
+	if len(ids) == 0 {
+		return all, nil
+	}
+	byID := make(map[string]hookDeliveryView, len(all))
+	for _, d := range all {
+		byID[d.ID] = d
+	}
+	out := make([]hookDeliveryView, 0, len(ids))
+	for _, id := range ids {
+		if !hookDeliveryIDPattern.MatchString(id) {
+			return nil, usagef("invalid delivery id %q", id)
+		}
+		d, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("delivery %s not found", id)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// newDeliveriesServer is a watch server that only delivers from the outbox,
+// using the hook token and secret saved in the watch state unless given.
+func newDeliveriesServer(ctx context.Context, kctx *kong.Context, account, token, secret string) (*gmailWatchServer, error) {
+	store, err := loadGmailWatchStore(account)
+	if err != nil {
+		return nil, err
+	}
+	outbox, err := openGmailHookOutbox(account)
+	if err != nil {
+		return nil, err
+	}
+	if hook := store.Get().Hook; hook != nil {
+		if !flagProvided(kctx, "hook-token") {
+			token = hook.Token
+		}
+		if !flagProvided(kctx, "hook-secret") {
+			secret = hook.Secret
+		}
+	}
+	u := ui.FromContext(ctx)
+	return &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Account:     account,
+			HookToken:   token,
+			HookSecret:  secret,
+			Rules:       store.Get().Rules,
+			HookTimeout: defaultHookRequestTimeoutSec * time.Second,
+		},
+		store:      store,
+		outbox:     outbox,
+		hookClient: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+		logf:       u.Err().Printf,
+		warnf:      u.Err().Printf,
+	}, nil
+}
+
+type GmailWatchDeliveriesListCmd struct {
+	Dead    bool `name:"dead" help:"Only dead-lettered deliveries"`
+	Pending bool `name:"pending" help:"Only deliveries waiting for a retry"`
+}
+
+func (c *GmailWatchDeliveriesListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	outbox, err := openGmailHookOutbox(account)
+	if err != nil {
+		return err
+	}
+	both := !c.Dead && !c.Pending
+	deliveries, err := listHookDeliveries(outbox, both || c.Pending, both || c.Dead)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if deliveries == nil {
+			deliveries = []hookDeliveryView{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"deliveries": deliveries})
+	}
+	if len(deliveries) == 0 {
+		u.Err().Println("No deliveries")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tSTATE\tATTEMPTS\tCREATED\tNEXT_ATTEMPT\tLAST_ERROR")
+	for _, d := range deliveries {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", d.ID, d.State, d.Attempts,
+			formatUnixMillis(d.CreatedAtMs), formatUnixMillis(d.NextAttemptAtMs), sanitizeTab(d.LastError))
+	}
+	return nil
+}
+
+type GmailWatchDeliveriesRetryCmd struct {
+	IDs        []string `arg:"" name:"id" optional:"" help:"Delivery IDs (default: all pending and dead-lettered)"`
+	HookToken  string   `name:"hook-token" help:"Webhook bearer token (default: from watch state)"`
+	HookSecret string   `name:"hook-secret" help:"HMAC key for X-Gog-Signature (default: from watch state)"`
+}
+
+func (c *GmailWatchDeliveriesRetryCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	server, err := newDeliveriesServer(ctx, kctx, account, c.HookToken, c.HookSecret)
+	if err != nil {
+		return err
+	}
+	all, err := listHookDeliveries(server.outbox, true, true)
+	if err != nil {
+		return err
+	}
+	selected, err := selectHookDeliveries(all, c.IDs)
+	if err != nil {
+		return err
+	}
+
+	// Dead-lettered entries get a fresh set of attempts.
+	for _, d := range selected {
+		if d.State != hookQueueDead {
+			continue
+		}
+		revived := d.gmailHookDelivery
+		revived.Attempts = 0
+		revived.NextAttemptAtMs = time.Now().UnixMilli()
+		if err := server.outbox.locked(func() error {
+			if _, err := server.outbox.load(hookQueueDead, revived.ID); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil // purged meanwhile
+				}
+				return err
+			}
+			return server.outbox.move(hookQueueDead, hookQueuePending, revived)
+		}); err != nil {
+			return err
+		}
+	}
+
+	delivered, failed := 0, 0
+	for _, d := range selected {
+		if err := server.deliverQueued(ctx, d.ID); err != nil {
+			u.Err().Printf("%v", err)
+			failed++
+			continue
+		}
+		delivered++
+	}
+	if len(selected) == 0 {
+		server.updateQueueHealth(nil)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"delivered": delivered, "failed": failed}); err != nil {
+			return err
+		}
+	} else {
+		u.Out().Printf("delivered\t%d", delivered)
+		u.Out().Printf("failed\t%d", failed)
+	}
+	if failed > 0 {
+		return errors.New("some deliveries failed; they stay queued for retry")
+	}
+	return nil
+}
+
+type GmailWatchDeliveriesPurgeCmd struct {
+	IDs  []string `arg:"" name:"id" optional:"" help:"Delivery IDs (default: all)"`
+	Dead bool     `name:"dead" help:"Only dead-lettered deliveries"`
+}
+
+func (c *GmailWatchDeliveriesPurgeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	server, err := newDeliveriesServer(ctx, kctx, account, "", "")
+	if err != nil {
+		return err
+	}
+	all, err := listHookDeliveries(server.outbox, !c.Dead, true)
+	if err != nil {
+		return err
+	}
+	selected, err := selectHookDeliveries(all, c.IDs)
+	if err != nil {
+		return err
+	}
+	if len(selected) > 0 {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("purge %d hook deliveries", len(selected))); err != nil {
+			return err
+		}
+	}
+	if err := server.outbox.locked(func() error {
+		for _, d := range selected {
+			if err := server.outbox.remove(d.State, d.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	server.updateQueueHealth(nil)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"purged": len(selected)})
+	}
+	u.Out().Printf("purged\t%d", len(selected))
+	return nil
+}
@@ -131,6 +131,7 @@ func TestIsLoopbackHost(t *testing.T) {
 func TestGmailWatchStore_StateHelpers(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("User+X@Example.COM")
 	if err != nil {
@@ -161,6 +162,37 @@ func TestGmailWatchStore_StateHelpers(t *testing.T) {
 	}
 }
 
+func TestGmailWatchStore_UpdateKeepsOtherProcessChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	serve := seedPullWatchState(t)
+	cli, err := loadGmailWatchStore("a@b.com") // a stale copy, as in another process
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := serve.Update(func(s *gmailWatchState) error {
+		s.HistoryID = "200"
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := cli.Update(func(s *gmailWatchState) error {
+		s.LastDeliveryStatus = "ok"
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	reloaded, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := reloaded.Get(); st.HistoryID != "200" || st.LastDeliveryStatus != "ok" {
+		t.Fatalf("stale copy overwrote state: %+v", st)
+	}
+}
+
 func TestGmailWatchStore_SaveMissingPath(t *testing.T) {
 	store := &gmailWatchStore{}
 	if err := store.Save(); err == nil {
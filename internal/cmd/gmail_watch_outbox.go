@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+const (
+	defaultHookMaxAttempts = 8
+	hookRetryBaseDelay     = 30 * time.Second
+	hookRetryMaxDelay      = time.Hour
+	hookOutboxTick         = 5 * time.Second
+	hookExecTimeout        = time.Minute
+
+	hookQueuePending = "pending"
+	hookQueueDead    = "dead"
+
+	gmailWatchStatusRetrying   = "retrying"
+	gmailWatchStatusDeadLetter = "dead_letter"
+)
+
+var hookDeliveryIDPattern = regexp.MustCompile(`^[0-9]+-[0-9a-f]+$`)
+
+// gmailHookDelivery is one queued hook request. Token and secret are not
+// stored: they come from the route's current config when it is sent.
+type gmailHookDelivery struct {
+	ID              string          `json:"id"`
+	Route           string          `json:"route,omitempty"` // rule name; empty for the default hook
+	URL             string          `json:"url,omitempty"`
+	Exec            string          `json:"exec,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+	Attempts        int             `json:"attempts"`
+	CreatedAtMs     int64           `json:"createdAtMs"`
+	NextAttemptAtMs int64           `json:"nextAttemptAtMs,omitempty"`
+	LastAttemptAtMs int64           `json:"lastAttemptAtMs,omitempty"`
+	LastError       string          `json:"lastError,omitempty"`
+}
+
+// gmailHookOutbox is an account's durable hook queue: pending/<id>.json
+// entries are retried with exponential backoff and moved to dead/ once they
+// run out of attempts. IDs start with the creation time, so they sort
+// oldest first.
+type gmailHookOutbox struct {
+	dir string
+	mu  sync.Mutex // one delivery attempt at a time; see locked
+}
+
+// locked runs fn holding the outbox lock: in-process and across gog
+// processes, so `watch serve` and `watch deliveries retry|purge` never
+// handle the same entry at once.
+func (o *gmailHookOutbox) locked(fn func() error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return withFileLock(filepath.Join(o.dir, ".lock"), fn)
+}
+
+func openGmailHookOutbox(account string) (*gmailHookOutbox, error) {
+	dir, err := config.EnsureGmailWatchDir()
+	if err != nil {
+		return nil, err
+	}
+	o := &gmailHookOutbox{dir: filepath.Join(dir, "outbox", sanitizeAccountForPath(account))}
+	for _, queue := range []string{hookQueuePending, hookQueueDead} {
+		if err := os.MkdirAll(filepath.Join(o.dir, queue), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *gmailHookOutbox) path(queue, id string) string {
+	return filepath.Join(o.dir, queue, id+".json")
+}
+
+func (o *gmailHookOutbox) enqueue(t gmailWatchTarget, payload []byte) (gmailHookDelivery, error) {
+	var rnd [4]byte
+	if _, err := rand.Read(rnd[:]); err != nil {
+		return gmailHookDelivery{}, err
+	}
+	now := time.Now()
+	d := gmailHookDelivery{
+		ID:              fmt.Sprintf("%d-%s", now.UnixNano(), hex.EncodeToString(rnd[:])),
+		Route:           t.Route,
+		URL:             t.URL,
+		Exec:            t.Exec,
+		Payload:         payload,
+		CreatedAtMs:     now.UnixMilli(),
+		NextAttemptAtMs: now.UnixMilli(),
+	}
+	return d, o.write(hookQueuePending, d)
+}
+
+func (o *gmailHookOutbox) write(queue string, d gmailHookDelivery) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := o.path(queue, d.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (o *gmailHookOutbox) load(queue, id string) (gmailHookDelivery, error) {
+	data, err := os.ReadFile(o.path(queue, id))
+	if err != nil {
+		return gmailHookDelivery{}, err
+	}
+	var d gmailHookDelivery
+	if err := json.Unmarshal(data, &d); err != nil {
+		return gmailHookDelivery{}, fmt.Errorf("read delivery %s: %w", id, err)
+	}
+	return d, nil
+}
+
+func (o *gmailHookOutbox) list(queue string) ([]gmailHookDelivery, error) {
+	entries, err := os.ReadDir(filepath.Join(o.dir, queue))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]gmailHookDelivery, 0, len(entries))
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		d, err := o.load(queue, id)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // delivered or purged meanwhile
+			}
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// move writes d to queue to and then drops it from queue from.
+func (o *gmailHookOutbox) move(from, to string, d gmailHookDelivery) error {
+	if err := o.write(to, d); err != nil {
+		return err
+	}
+	return o.remove(from, d.ID)
+}
+
+func (o *gmailHookOutbox) remove(queue, id string) error {
+	err := os.Remove(o.path(queue, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (o *gmailHookOutbox) counts() (int, int, error) {
+	pending, err := o.list(hookQueuePending)
+	if err != nil {
+		return 0, 0, err
+	}
+	dead, err := o.list(hookQueueDead)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(pending), len(dead), nil
+}
+
+// hookRetryDelay is the wait after the given number of failed attempts:
+// 30s, 1m, 2m, ... capped at an hour.
+func hookRetryDelay(attempts int) time.Duration {
+	d := hookRetryBaseDelay
+	for i := 1; i < attempts && d < hookRetryMaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, hookRetryMaxDelay)
+}
+
+// signHookPayload returns the X-Gog-Signature value "t=<unix>,sha256=<hex>",
+// the HMAC-SHA256 of "<unix>.<body>" keyed with secret. Receivers recompute
+// it and reject stale timestamps.
+func signHookPayload(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + unix + ",sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverTo makes one delivery attempt to an HTTP hook or command.
+// deliveryID, when set, goes out as X-Gog-Delivery (GOG_DELIVERY_ID for
+// commands) so receivers can drop retried duplicates.
+func (s *gmailWatchServer) deliverTo(ctx context.Context, t gmailWatchTarget, deliveryID string, data []byte) error {
+	if t.Exec != "" {
+		return runHookExec(ctx, t.Exec, s.cfg.Account, deliveryID, data)
+	}
+	return s.postHook(ctx, t, deliveryID, data)
+}
+
+func (s *gmailWatchServer) postHook(ctx context.Context, t gmailWatchTarget, deliveryID string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.Token)
+	}
+	if t.Secret != "" {
+		req.Header.Set("X-Gog-Signature", signHookPayload(t.Secret, time.Now(), data))
+	}
+	if deliveryID != "" {
+		req.Header.Set("X-Gog-Delivery", deliveryID)
+	}
+	resp, err := s.hookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &hookStatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
+// hookStatusError is a non-2xx hook response.
+type hookStatusError struct {
+	Code int
+}
+
+func (e *hookStatusError) Error() string { return fmt.Sprintf("hook status %d", e.Code) }
+
+// runHookExec runs command through the shell with the payload JSON on
+// stdin. A non-zero exit is a failed delivery; its stderr ends up in the
+// error.
+func runHookExec(ctx context.Context, command, account, deliveryID string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, hookExecTimeout)
+	defer cancel()
+
+	name, args := "sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", command}
+	}
+	cmd := exec.CommandContext(ctx, name, args...) //nolint:gosec // user-configured hook command
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "GOG_ACCOUNT="+account, "GOG_DELIVERY_ID="+deliveryID)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			msg, _ = truncateUTF8Bytes(msg, 500)
+			return fmt.Errorf("hook exec: %w: %s", err, msg)
+		}
+		return fmt.Errorf("hook exec: %w", err)
+	}
+	return nil
+}
+
+// queueRoutes stores each routed payload in the outbox and makes the first
+// delivery attempts. Only a failure to queue is returned; failed attempts
+// are retried by runOutbox.
+func (s *gmailWatchServer) queueRoutes(ctx context.Context, routes []gmailWatchRoute) error {
+	for _, r := range routes {
+		data, err := json.Marshal(r.Payload)
+		if err != nil {
+			return err
+		}
+		d, err := s.outbox.enqueue(r.Target, data)
+		if err != nil {
+			return err
+		}
+		if err := s.deliverQueued(ctx, d.ID); err != nil {
+			s.warnf("watch: hook failed (queued for retry): %v", err)
+		}
+	}
+	return nil
+}
+
+// deliveryTarget is where a queued delivery goes: its stored URL or command
+// with the current credentials of its route.
+func (s *gmailWatchServer) deliveryTarget(d gmailHookDelivery) gmailWatchTarget {
+	t := gmailWatchTarget{Route: d.Route, URL: d.URL, Exec: d.Exec}
+	if current, ok := s.cfg.routeTarget(d.Route); ok {
+		t.Token, t.Secret = current.Token, current.Secret
+	}
+	return t
+}
+
+// deliverQueued makes one attempt for a pending delivery, then removes,
+// reschedules or dead-letters it. An entry that is gone (delivered or
+// purged meanwhile) is not an error.
+func (s *gmailWatchServer) deliverQueued(ctx context.Context, id string) error {
+	return s.outbox.locked(func() error {
+		return s.deliverQueuedLocked(ctx, id)
+	})
+}
+
+func (s *gmailWatchServer) deliverQueuedLocked(ctx context.Context, id string) error {
+	o := s.outbox
+	d, err := o.load(hookQueuePending, id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	now := time.Now()
+	d.Attempts++
+	d.LastAttemptAtMs = now.UnixMilli()
+	hookErr := s.deliverTo(ctx, s.deliveryTarget(d), d.ID, d.Payload)
+	switch {
+	case hookErr == nil:
+		err = o.remove(hookQueuePending, d.ID)
+	case d.Attempts >= s.maxHookAttempts():
+		d.LastError = hookErr.Error()
+		d.NextAttemptAtMs = 0
+		s.warnf("watch: delivery %s dead-lettered after %d attempts", d.ID, d.Attempts)
+		err = o.move(hookQueuePending, hookQueueDead, d)
+	default:
+		d.LastError = hookErr.Error()
+		d.NextAttemptAtMs = now.Add(hookRetryDelay(d.Attempts)).UnixMilli()
+		err = o.write(hookQueuePending, d)
+	}
+	s.updateQueueHealth(hookErr)
+	if hookErr != nil {
+		return fmt.Errorf("delivery %s: %w", d.ID, hookErr)
+	}
+	return err
+}
+
+// deliverDue attempts the pending deliveries whose retry time has come (all
+// of them with force).
+func (s *gmailWatchServer) deliverDue(ctx context.Context, force bool) (int, int, error) {
+	pending, err := s.outbox.list(hookQueuePending)
+	if err != nil {
+		return 0, 0, err
+	}
+	now := time.Now().UnixMilli()
+	delivered, failed := 0, 0
+	for _, d := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if !force && d.NextAttemptAtMs > now {
+			continue
+		}
+		if err := s.deliverQueued(ctx, d.ID); err != nil {
+			s.warnf("watch: %v", err)
+			failed++
+			continue
+		}
+		delivered++
+	}
+	return delivered, failed, nil
+}
+
+// runOutbox retries due deliveries until ctx ends.
+func (s *gmailWatchServer) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(hookOutboxTick)
+	defer ticker.Stop()
+	for {
+		if _, _, err := s.deliverDue(ctx, false); err != nil {
+			s.warnf("watch: outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *gmailWatchServer) maxHookAttempts() int {
+	if s.cfg.HookMaxAttempts > 0 {
+		return s.cfg.HookMaxAttempts
+	}
+	return defaultHookMaxAttempts
+}
+
+// updateQueueHealth sets LastDeliveryStatus from the outbox: dead_letter
+// while anything is dead-lettered, retrying while deliveries are pending,
+// ok otherwise.
+func (s *gmailWatchServer) updateQueueHealth(lastErr error) {
+	pending, dead, err := s.outbox.counts()
+	if err != nil {
+		s.warnf("watch: outbox: %v", err)
+		return
+	}
+	status, note := "ok", ""
+	switch {
+	case dead > 0:
+		status = gmailWatchStatusDeadLetter
+		note = fmt.Sprintf("%d dead-lettered, %d pending; see gog gmail watch deliveries list", dead, pending)
+	case pending > 0:
+		status = gmailWatchStatusRetrying
+		note = fmt.Sprintf("%d pending", pending)
+	}
+	if lastErr != nil && note != "" {
+		note += "; last error: " + lastErr.Error()
+	}
+	if err := s.store.Update(func(state *gmailWatchState) error {
+		state.LastDeliveryStatus = status
+		state.LastDeliveryAtMs = time.Now().UnixMilli()
+		state.LastDeliveryStatusNote = note
+		return nil
+	}); err != nil {
+		s.warnf("watch: failed to update state: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSignHookPayload(t *testing.T) {
+	body := []byte(`{"source":"gmail"}`)
+	got := signHookPayload("s3cret", time.Unix(1700000000, 0), body)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestHookRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 20: time.Hour} {
+		if got := hookRetryDelay(attempts); got != want {
+			t.Fatalf("hookRetryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+// flakyHook fails until ok is set and records the request headers.
+type flakyHook struct {
+	mu      sync.Mutex
+	ok      bool
+	calls   int
+	headers []http.Header
+	bodies  [][]byte
+}
+
+func (h *flakyHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	h.calls++
+	h.headers = append(h.headers, r.Header.Clone())
+	h.bodies = append(h.bodies, body)
+	if !h.ok {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
+
+func newOutboxTestServer(t *testing.T, hookURL string, maxAttempts int) *gmailWatchServer {
+	t.Helper()
+	store := seedPullWatchState(t)
+	newPullTestGmail(t)
+	outbox, err := openGmailHookOutbox("a@b.com")
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	return &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Account:         "a@b.com",
+			Path:            "/gmail-pubsub",
+			HookURL:         hookURL,
+			HookSecret:      "s3cret",
+			HookMaxAttempts: maxAttempts,
+			HistoryMax:      10,
+			ResyncMax:       10,
+		},
+		store:      store,
+		outbox:     outbox,
+		newService: newGmailService,
+		hookClient: http.DefaultClient,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+}
+
+func postTestPush(t *testing.T, s *gmailWatchServer, email string) int {
+	t.Helper()
+	push := pubsubPushEnvelope{}
+	push.Message.Data = base64.StdEncoding.EncodeToString([]byte(`{"emailAddress":"` + email + `","historyId":"200"}`))
+	body, _ := json.Marshal(push)
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/gmail-pubsub", bytes.NewReader(body)))
+	return rr.Code
+}
+
+func TestGmailHookOutbox_LockedExcludesOtherHandles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	first, err := openGmailHookOutbox("a@b.com")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	second, err := openGmailHookOutbox("a@b.com") // separate handle, like another process
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- first.locked(func() error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = second.locked(func() error { return nil })
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("second handle took the outbox lock while it was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("locked: %v", err)
+	}
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lock not released")
+	}
+}
+
+func TestGmailWatchServer_OutboxRetriesFailedHook(t *testing.T) {
+	hook := &flakyHook{}
+	hookSrv := httptest.NewServer(hook)
+	t.Cleanup(hookSrv.Close)
+	s := newOutboxTestServer(t, hookSrv.URL, 5)
+
+	if code := postTestPush(t, s, "a@b.com"); code != http.StatusOK {
+		t.Fatalf("status: %d", code)
+	}
+	pending, err := s.outbox.list(hookQueuePending)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending delivery, got %v (%v)", pending, err)
+	}
+	if d := pending[0]; d.Attempts != 1 || d.LastError != "hook status 502" || d.NextAttemptAtMs <= time.Now().UnixMilli() {
+		t.Fatalf("unexpected delivery: %+v", d)
+	}
+	if st := s.store.Get(); st.LastDeliveryStatus != gmailWatchStatusRetrying || st.HistoryID != "200" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	// Not due yet: nothing happens without force.
+	if delivered, failed, _ := s.deliverDue(context.Background(), false); delivered+failed != 0 {
+		t.Fatalf("expected no attempt, got %d/%d", delivered, failed)
+	}
+
+	hook.mu.Lock()
+	hook.ok = true
+	hook.mu.Unlock()
+	if delivered, failed, err := s.deliverDue(context.Background(), true); err != nil || delivered != 1 || failed != 0 {
+		t.Fatalf("deliverDue: %d/%d %v", delivered, failed, err)
+	}
+	if p, d, _ := s.outbox.counts(); p+d != 0 {
+		t.Fatalf("queue not empty: %d pending, %d dead", p, d)
+	}
+	if st := s.store.Get(); st.LastDeliveryStatus != "ok" || st.LastDeliveryStatusNote != "" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	// Both attempts carried the same delivery ID and a valid signature.
+	if hook.calls != 2 || hook.headers[0].Get("X-Gog-Delivery") != pending[0].ID || hook.headers[1].Get("X-Gog-Delivery") != pending[0].ID {
+		t.Fatalf("unexpected hook calls: %d %v", hook.calls, hook.headers)
+	}
+	sig := hook.headers[1].Get("X-Gog-Signature")
+	ts := strings.TrimPrefix(strings.Split(sig, ",")[0], "t=")
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(ts + "." + string(hook.bodies[1])))
+	if !strings.HasSuffix(sig, ",sha256="+hex.EncodeToString(mac.Sum(nil))) {
+		t.Fatalf("bad signature %q", sig)
+	}
+}
+
+func TestGmailWatchDeliveries_DeadLetterRetryPurge(t *testing.T) {
+	hook := &flakyHook{}
+	hookSrv := httptest.NewServer(hook)
+	t.Cleanup(hookSrv.Close)
+	s := newOutboxTestServer(t, hookSrv.URL, 2)
+	if err := s.store.Update(func(st *gmailWatchState) error {
+		st.Hook = &gmailWatchHook{URL: hookSrv.URL, Secret: "s3cret"}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed hook: %v", err)
+	}
+
+	_ = postTestPush(t, s, "a@b.com")
+	if _, failed, _ := s.deliverDue(context.Background(), true); failed != 1 {
+		t.Fatalf("expected a failed attempt")
+	}
+	if p, d, _ := s.outbox.counts(); p != 0 || d != 1 {
+		t.Fatalf("expected dead letter, got %d pending, %d dead", p, d)
+	}
+	if st := s.store.Get(); st.LastDeliveryStatus != gmailWatchStatusDeadLetter {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	ctx := testJSONContext(t, io.Discard)
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailWatchDeliveriesListCmd{}, []string{"--dead"}, ctx, flags); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	var listed struct {
+		Deliveries []hookDeliveryView `json:"deliveries"`
+	}
+	if err := json.Unmarshal([]byte(out), &listed); err != nil || len(listed.Deliveries) != 1 || listed.Deliveries[0].State != hookQueueDead {
+		t.Fatalf("unexpected list: %v %s", err, out)
+	}
+	id := listed.Deliveries[0].ID
+
+	hook.mu.Lock()
+	hook.ok = true
+	hook.mu.Unlock()
+	out = captureStdout(t, func() {
+		if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, []string{id}, ctx, flags); err != nil {
+			t.Fatalf("retry: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"delivered": 1`) {
+		t.Fatalf("unexpected retry output: %s", out)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := store.Get(); st.LastDeliveryStatus != "ok" {
+		t.Fatalf("unexpected state after retry: %+v", st)
+	}
+	if sig := hook.headers[len(hook.headers)-1].Get("X-Gog-Signature"); sig == "" {
+		t.Fatalf("retry should sign with the saved secret")
+	}
+
+	// Purge drops whatever is left.
+	if _, err := s.outbox.enqueue(gmailWatchTarget{URL: hookSrv.URL}, []byte(`{}`)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	out = captureStdout(t, func() {
+		if err := runKong(t, &GmailWatchDeliveriesPurgeCmd{}, nil, ctx, flags); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"purged": 1`) {
+		t.Fatalf("unexpected purge output: %s", out)
+	}
+	if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, []string{"../etc"}, ctx, flags); err == nil {
+		t.Fatalf("expected invalid id error")
+	}
+}
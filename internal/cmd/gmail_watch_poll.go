@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailWatchPollCmd struct {
+	Interval    string   `name:"interval" help:"Time between history checks (seconds or Go duration)" default:"60s"`
+	Labels      []string `name:"label" help:"Only report messages with one of these labels (IDs or names; repeatable, comma-separated). Default: the labels from watch start, else all"`
+	Once        bool     `name:"once" help:"Check once and exit (for cron)"`
+	HookURL     string   `name:"hook-url" help:"Webhook URL to forward messages (default: NDJSON on stdout)"`
+	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret  string   `name:"hook-secret" help:"HMAC-SHA256 key for the X-Gog-Signature header on hook requests"`
+	HookExec    string   `name:"hook-exec" help:"Command to run per payload, with the payload JSON on stdin (instead of --hook-url)"`
+	Rules       string   `name:"rules" help:"Routing rules file (YAML or JSON); default: rules saved in watch state"`
+	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
+	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
+	SaveHook    bool     `name:"save-hook" help:"Persist hook settings to watch state"`
+}
+
+func (c *GmailWatchPollCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	interval, err := parseDurationSeconds(c.Interval)
+	if err != nil {
+		return usagef("invalid --interval %q: %v", c.Interval, err)
+	}
+	if interval < time.Second {
+		return usage("--interval must be at least 1s")
+	}
+
+	// Unlike serve/pull, polling works without `watch start`: the state file
+	// is created on the first run.
+	store, _, err := openGmailWatchStore(account)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+	hook, includeBody, maxBytes, err := resolveWatchHook(kctx, state, c.HookURL, c.HookToken, c.HookSecret, c.HookExec, c.IncludeBody, c.MaxBytes)
+	if err != nil {
+		return err
+	}
+	rules, err := resolveWatchRules(ctx, account, c.Rules, state.Rules)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	labels := state.Labels
+	if len(c.Labels) > 0 {
+		labels, err = resolveLabelIDsWithService(svc, c.Labels)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Start from the mailbox's current history ID: the first poll reports
+	// mail that arrives from now on.
+	if state.HistoryID == "" {
+		profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		historyID := formatHistoryID(profile.HistoryId)
+		if err := store.Update(func(s *gmailWatchState) error {
+			s.Account = account
+			s.HistoryID = historyID
+			s.UpdatedAtMs = time.Now().UnixMilli()
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if c.SaveHook {
+		if err := saveWatchHook(store, hook, c.Rules != "", rules); err != nil {
+			return err
+		}
+	}
+
+	cfg := newWatchServeConfig(account, hook, includeBody, maxBytes)
+	cfg.Labels = labels
+	cfg.Rules = rules
+	server := &gmailWatchServer{
+		cfg:        cfg,
+		store:      store,
+		newService: newGmailService,
+		hookClient: &http.Client{Timeout: cfg.HookTimeout},
+		logf:       u.Err().Printf,
+		warnf:      u.Err().Printf,
+	}
+
+	if c.Once {
+		return server.processNotification(ctx, gmailPushPayload{EmailAddress: account}, os.Stdout)
+	}
+
+	pollCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	u.Err().Printf("watch: polling every %s", interval)
+	return pollGmailHistory(pollCtx, server, interval, os.Stdout)
+}
+
+// pollGmailHistory checks history every interval until ctx ends. Failures
+// are logged and retried on the next tick; a failed delivery leaves the
+// history ID in place so the same messages are retried.
+func pollGmailHistory(ctx context.Context, server *gmailWatchServer, interval time.Duration, out io.Writer) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := server.processNotification(ctx, gmailPushPayload{EmailAddress: server.cfg.Account}, out); err != nil && ctx.Err() == nil {
+			server.warnf("watch: poll failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
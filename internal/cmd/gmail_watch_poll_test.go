@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// fakePollGmail serves profile, labels, history and messages. history maps a
+// startHistoryId to the message IDs added since; other start IDs are stale.
+// With historyPage set, history is split into pages of that many messages.
+type fakePollGmail struct {
+	mu           sync.Mutex
+	historyPage  int
+	profileID    string
+	latestID     string
+	history      map[string][]string
+	labels       map[string][]string // message ID -> label IDs
+	from         map[string]string   // message ID -> From header
+	attachments  map[string]bool     // message ID -> has a file part
+	historyCalls []string            // raw queries
+}
+
+func newFakePollGmail(t *testing.T, f *fakePollGmail) *fakePollGmail {
+	t.Helper()
+	stubGmailService(t, http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakePollGmail) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/users/me/profile"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": f.profileID})
+	case strings.HasSuffix(path, "/users/me/labels"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{{"id": "INBOX", "name": "INBOX"}, {"id": "Label_7", "name": "Newsletters"}}})
+	case strings.HasSuffix(path, "/users/me/history"):
+		f.historyCalls = append(f.historyCalls, r.URL.RawQuery)
+		ids, ok := f.history[r.URL.Query().Get("startHistoryId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 404, "message": "Requested history not found"}})
+			return
+		}
+		resp := map[string]any{"historyId": f.latestID}
+		if f.historyPage > 0 {
+			start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+			ids = ids[start:]
+			if len(ids) > f.historyPage {
+				ids = ids[:f.historyPage]
+				resp["nextPageToken"] = strconv.Itoa(start + f.historyPage)
+			}
+		}
+		var added []map[string]any
+		for _, id := range ids {
+			added = append(added, map[string]any{"message": map[string]any{"id": id}})
+		}
+		if len(added) > 0 {
+			resp["history"] = []map[string]any{{"messagesAdded": added}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case strings.HasSuffix(path, "/users/me/messages"):
+		var msgs []map[string]any
+		for id := range f.labels {
+			msgs = append(msgs, map[string]any{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"messages": msgs})
+	case strings.Contains(path, "/users/me/messages/"):
+		id := path[strings.LastIndex(path, "/")+1:]
+		payload := map[string]any{"headers": []map[string]any{
+			{"name": "Subject", "value": "msg " + id},
+			{"name": "From", "value": f.from[id]},
+		}}
+		if f.attachments[id] {
+			payload["parts"] = []map[string]any{{"filename": "a.pdf", "mimeType": "application/pdf", "body": map[string]any{"attachmentId": "att1", "size": 10}}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": id, "threadId": "t-" + id, "labelIds": f.labels[id], "payload": payload,
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func runGmailWatchPoll(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runKong(t, &GmailWatchPollCmd{}, args, ui.WithUI(context.Background(), u), &RootFlags{Account: "a@b.com"})
+	})
+	return out, runErr
+}
+
+func TestGmailWatchPoll_OnceWithoutWatchStart(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	f := newFakePollGmail(t, &fakePollGmail{
+		profileID: "100",
+		latestID:  "200",
+		history:   map[string][]string{"100": {"m1", "m2"}, "200": nil},
+		labels:    map[string][]string{"m1": {"INBOX"}, "m2": {"SPAM"}},
+	})
+
+	out, err := runGmailWatchPoll(t, "--once", "--label", "INBOX")
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	var got gmailHookPayload
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &got); err != nil {
+		t.Fatalf("ndjson: %v\n%s", err, out)
+	}
+	if got.HistoryID != "200" || len(got.Messages) != 1 || got.Messages[0].ID != "m1" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	if !strings.Contains(f.historyCalls[0], "labelId=INBOX") {
+		t.Fatalf("expected label filter, got %q", f.historyCalls[0])
+	}
+
+	// Nothing new: no output, state unchanged.
+	out, err = runGmailWatchPoll(t, "--once", "--label", "INBOX")
+	if err != nil || strings.TrimSpace(out) != "" {
+		t.Fatalf("second poll: err=%v out=%q", err, out)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := store.Get(); st.HistoryID != "200" || st.Account != "a@b.com" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
+
+func TestGmailWatchPoll_StaleHistoryResyncsToHook(t *testing.T) {
+	store := seedPullWatchState(t) // historyId 100, not served below
+	newFakePollGmail(t, &fakePollGmail{
+		profileID: "300",
+		latestID:  "300",
+		history:   map[string][]string{},
+		labels:    map[string][]string{"m9": {"INBOX"}},
+	})
+	var delivered gmailHookPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&delivered)
+	}))
+	t.Cleanup(hook.Close)
+
+	if _, err := runGmailWatchPoll(t, "--once", "--hook-url", hook.URL); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if delivered.HistoryID != "300" || len(delivered.Messages) != 1 || delivered.Messages[0].ID != "m9" {
+		t.Fatalf("unexpected delivery: %+v", delivered)
+	}
+	store, err := loadGmailWatchStore(store.Get().Account)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := store.Get(); st.HistoryID != "300" {
+		t.Fatalf("expected resynced history, got %+v", st)
+	}
+}
+
+func TestGmailWatchPoll_HookFailureKeepsHistory(t *testing.T) {
+	seedPullWatchState(t)
+	newFakePollGmail(t, &fakePollGmail{
+		latestID: "200",
+		history:  map[string][]string{"100": {"m1"}},
+		labels:   map[string][]string{"m1": {"INBOX"}},
+	})
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(hook.Close)
+
+	if _, err := runGmailWatchPoll(t, "--once", "--hook-url", hook.URL); err == nil {
+		t.Fatalf("expected hook error")
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := store.Get(); st.HistoryID != "100" || st.LastDeliveryStatus != gmailWatchStatusHTTPError {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
+
+func TestGmailWatchPoll_FollowsHistoryPages(t *testing.T) {
+	seedPullWatchState(t)
+	f := newFakePollGmail(t, &fakePollGmail{
+		historyPage: 2,
+		latestID:    "200",
+		history:     map[string][]string{"100": {"m1", "m2", "m3"}},
+		labels:      map[string][]string{"m1": {"INBOX"}, "m2": {"INBOX"}, "m3": {"INBOX"}},
+	})
+
+	out, err := runGmailWatchPoll(t, "--once")
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	var got gmailHookPayload
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &got); err != nil {
+		t.Fatalf("ndjson: %v\n%s", err, out)
+	}
+	if len(got.Messages) != 3 || got.Messages[2].ID != "m3" || got.HistoryID != "200" {
+		t.Fatalf("expected all pages delivered, got %+v", got)
+	}
+	if len(f.historyCalls) != 2 || !strings.Contains(f.historyCalls[1], "pageToken=2") {
+		t.Fatalf("expected a second history page request, got %q", f.historyCalls)
+	}
+}
+
+func TestPollGmailHistory_TicksUntilCancelled(t *testing.T) {
+	store := seedPullWatchState(t)
+	f := newFakePollGmail(t, &fakePollGmail{latestID: "100", history: map[string][]string{"100": nil}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HistoryMax: 10, ResyncMax: 10},
+		store:      store,
+		newService: newGmailService,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+	done := make(chan error, 1)
+	go func() { done <- pollGmailHistory(ctx, server, 5*time.Millisecond, io.Discard) }()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		f.mu.Lock()
+		n := len(f.historyCalls)
+		f.mu.Unlock()
+		if n >= 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("only %d polls", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("poll loop: %v", err)
+	}
+}
+
+func TestGmailWatchPoll_InvalidInterval(t *testing.T) {
+	for _, v := range []string{"soon", "0", "500ms"} {
+		if _, err := runGmailWatchPoll(t, "--interval", v); err == nil {
+			t.Fatalf("expected error for --interval %s", v)
+		}
+	}
+}
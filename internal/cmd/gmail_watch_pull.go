@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var newPubSubService = googleapi.NewPubSub
+
+const (
+	// gmailWatchPullWait bounds one long-poll so it ends before the API
+	// client's HTTP timeout; an empty wait just starts the next poll. With
+	// a short --http-timeout the wait shrinks to leave gmailWatchPullSlack.
+	gmailWatchPullWait       = 20 * time.Second
+	gmailWatchPullSlack      = 5 * time.Second
+	gmailWatchPullMinWait    = time.Second
+	gmailWatchPullMaxBackoff = time.Minute
+)
+
+type GmailWatchPullCmd struct {
+	Subscription string `name:"subscription" help:"Pub/Sub subscription (projects/.../subscriptions/...)"`
+	MaxMessages  int64  `name:"max-messages" help:"Messages per pull request" default:"10"`
+	HookURL      string `name:"hook-url" help:"Webhook URL to forward messages"`
+	HookToken    string `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret   string `name:"hook-secret" help:"HMAC-SHA256 key for the X-Gog-Signature header on hook requests"`
+	HookExec     string `name:"hook-exec" help:"Command to run per payload, with the payload JSON on stdin (instead of --hook-url)"`
+	Rules        string `name:"rules" help:"Routing rules file (YAML or JSON); default: rules saved in watch state"`
+	IncludeBody  bool   `name:"include-body" help:"Include text/plain body in hook payload"`
+	MaxBytes     int    `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
+	SaveHook     bool   `name:"save-hook" help:"Persist hook settings to watch state"`
+}
+
+func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	subscription := strings.TrimSpace(c.Subscription)
+	if subscription == "" {
+		return usage("--subscription is required")
+	}
+	if !strings.HasPrefix(subscription, "projects/") || !strings.Contains(subscription, "/subscriptions/") {
+		return usage("--subscription must be projects/<project>/subscriptions/<name>")
+	}
+	if c.MaxMessages <= 0 {
+		return usage("--max-messages must be > 0")
+	}
+	wait, err := gmailWatchPullWaitFor(googleapi.RetryConfigFromContext(ctx).Timeout)
+	if err != nil {
+		return err
+	}
+
+	store, err := loadGmailWatchStore(account)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+	hook, includeBody, maxBytes, err := resolveWatchHook(kctx, state, c.HookURL, c.HookToken, c.HookSecret, c.HookExec, c.IncludeBody, c.MaxBytes)
+	if err != nil {
+		return err
+	}
+	rules, err := resolveWatchRules(ctx, account, c.Rules, state.Rules)
+	if err != nil {
+		return err
+	}
+	if c.SaveHook {
+		if err := saveWatchHook(store, hook, c.Rules != "", rules); err != nil {
+			return err
+		}
+	}
+
+	psvc, err := newPubSubService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	cfg := newWatchServeConfig(account, hook, includeBody, maxBytes)
+	cfg.Rules = rules
+	puller := &gmailWatchPuller{
+		server: &gmailWatchServer{
+			cfg:        cfg,
+			store:      store,
+			newService: newGmailService,
+			hookClient: &http.Client{Timeout: cfg.HookTimeout},
+			logf:       u.Err().Printf,
+			warnf:      u.Err().Printf,
+		},
+		pubsub:       psvc,
+		subscription: subscription,
+		maxMessages:  c.MaxMessages,
+		wait:         wait,
+		out:          os.Stdout,
+	}
+
+	pullCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	u.Err().Printf("watch: pulling %s", subscription)
+	return puller.run(pullCtx)
+}
+
+// gmailWatchPuller feeds Pub/Sub pull messages through the same handling as
+// the push endpoint. A message is acknowledged once its notification is
+// handled (hook delivered, or nothing to deliver); failures are nacked so
+// Pub/Sub redelivers them.
+type gmailWatchPuller struct {
+	server       *gmailWatchServer
+	pubsub       *pubsub.Service
+	subscription string
+	maxMessages  int64
+	wait         time.Duration // long-poll bound; 0 means gmailWatchPullWait
+	out          io.Writer     // payloads as NDJSON when no hook is configured
+}
+
+// gmailWatchPullWaitFor returns the long-poll bound for an API client with
+// the given HTTP timeout, so an empty poll ends on its own deadline rather
+// than failing at the HTTP client.
+func gmailWatchPullWaitFor(timeout time.Duration) (time.Duration, error) {
+	wait := min(gmailWatchPullWait, timeout-gmailWatchPullSlack)
+	if wait < gmailWatchPullMinWait {
+		return 0, usagef("watch pull needs --http-timeout of at least %s (got %s)", gmailWatchPullMinWait+gmailWatchPullSlack, timeout)
+	}
+	return wait, nil
+}
+
+func (p *gmailWatchPuller) run(ctx context.Context) error {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		received, err := p.pull(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			p.server.warnf("watch: pull failed: %v (retrying in %s)", err, backoff)
+			if sleepContext(ctx, backoff) != nil {
+				break
+			}
+			backoff = min(backoff*2, gmailWatchPullMaxBackoff)
+			continue
+		}
+		backoff = time.Second
+
+		var ackIDs, nackIDs []string
+		for _, m := range received {
+			if m == nil || m.Message == nil {
+				continue
+			}
+			if p.handle(ctx, m.Message) {
+				ackIDs = append(ackIDs, m.AckId)
+			} else {
+				nackIDs = append(nackIDs, m.AckId)
+			}
+		}
+		if err := p.settle(ctx, ackIDs, nackIDs); err != nil && ctx.Err() == nil {
+			p.server.warnf("watch: acknowledge failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// pull long-polls the subscription; a poll that ends without messages
+// returns none.
+func (p *gmailWatchPuller) pull(ctx context.Context) ([]*pubsub.ReceivedMessage, error) {
+	wait := p.wait
+	if wait <= 0 {
+		wait = gmailWatchPullWait
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	resp, err := p.pubsub.Projects.Subscriptions.Pull(p.subscription, &pubsub.PullRequest{MaxMessages: p.maxMessages}).
+		Context(waitCtx).
+		Do()
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return resp.ReceivedMessages, nil
+}
+
+// handle processes one notification and reports whether it can be acked.
+func (p *gmailWatchPuller) handle(ctx context.Context, msg *pubsub.PubsubMessage) bool {
+	s := p.server
+	envelope := &pubsubPushEnvelope{Subscription: p.subscription}
+	envelope.Message.Data = msg.Data
+	envelope.Message.MessageID = msg.MessageId
+	envelope.Message.PublishTime = msg.PublishTime
+	envelope.Message.Attributes = msg.Attributes
+
+	payload, err := decodeGmailPushPayload(envelope)
+	if err != nil {
+		// Redelivery cannot fix a malformed message.
+		s.warnf("watch: invalid pull data: %v", err)
+		return true
+	}
+	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account) {
+		s.warnf("watch: ignoring notification for %s", payload.EmailAddress)
+		return true
+	}
+
+	if err := s.processNotification(ctx, payload, p.out); err != nil {
+		s.warnf("watch: notification failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// settle acks handled messages and nacks (ack deadline 0) the rest for
+// immediate redelivery.
+func (p *gmailWatchPuller) settle(ctx context.Context, ackIDs, nackIDs []string) error {
+	// Settle even if ctx was just cancelled, so handled messages are not
+	// redelivered after an interrupt.
+	ctx = context.WithoutCancel(ctx)
+	if len(ackIDs) > 0 {
+		if _, err := p.pubsub.Projects.Subscriptions.Acknowledge(p.subscription, &pubsub.AcknowledgeRequest{AckIds: ackIDs}).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+	if len(nackIDs) > 0 {
+		req := &pubsub.ModifyAckDeadlineRequest{AckIds: nackIDs, AckDeadlineSeconds: 0, ForceSendFields: []string{"AckDeadlineSeconds"}}
+		if _, err := p.pubsub.Projects.Subscriptions.ModifyAckDeadline(p.subscription, req).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
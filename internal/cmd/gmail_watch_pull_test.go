@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// fakePubSub is a pull subscription: pull hands out the queued messages,
+// nacked messages go back on the queue, and an empty pull cancels the
+// consumer's context to end the test.
+type fakePubSub struct {
+	mu      sync.Mutex
+	queue   []map[string]any
+	leased  map[string]map[string]any
+	acked   []string
+	nacked  []string
+	pulls   int
+	stopped context.CancelFunc
+}
+
+func newFakePubSub(t *testing.T, cancel context.CancelFunc, payloads ...string) *fakePubSub {
+	t.Helper()
+	f := &fakePubSub{leased: map[string]map[string]any{}, stopped: cancel}
+	for i, p := range payloads {
+		f.queue = append(f.queue, map[string]any{
+			"messageId": string(rune('a' + i)),
+			"data":      base64.StdEncoding.EncodeToString([]byte(p)),
+		})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+
+	svc, err := pubsub.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("pubsub.NewService: %v", err)
+	}
+	orig := newPubSubService
+	t.Cleanup(func() { newPubSubService = orig })
+	newPubSubService = func(context.Context, string) (*pubsub.Service, error) { return svc, nil }
+	return f
+}
+
+func (f *fakePubSub) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !strings.HasPrefix(r.URL.Path, "/v1/projects/p/subscriptions/s:") {
+		http.NotFound(w, r)
+		return
+	}
+	var req struct {
+		AckIDs             []string `json:"ackIds"`
+		AckDeadlineSeconds *int     `json:"ackDeadlineSeconds"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, ":pull"):
+		f.pulls++
+		if len(f.queue) == 0 {
+			f.stopped()
+			_, _ = io.WriteString(w, `{}`)
+			return
+		}
+		received := make([]map[string]any, 0, len(f.queue))
+		for _, m := range f.queue {
+			ackID := "ack-" + m["messageId"].(string) + "-" + string(rune('0'+f.pulls))
+			f.leased[ackID] = m
+			received = append(received, map[string]any{"ackId": ackID, "message": m})
+		}
+		f.queue = nil
+		_ = json.NewEncoder(w).Encode(map[string]any{"receivedMessages": received})
+	case strings.HasSuffix(r.URL.Path, ":acknowledge"):
+		f.acked = append(f.acked, req.AckIDs...)
+		_, _ = io.WriteString(w, `{}`)
+	case strings.HasSuffix(r.URL.Path, ":modifyAckDeadline"):
+		if req.AckDeadlineSeconds == nil || *req.AckDeadlineSeconds != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, id := range req.AckIDs {
+			f.nacked = append(f.nacked, id)
+			f.queue = append(f.queue, f.leased[id])
+		}
+		_, _ = io.WriteString(w, `{}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newPullTestGmail(t *testing.T) {
+	t.Helper()
+	stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/users/me/history"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "200",
+				"history":   []map[string]any{{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}}}},
+			})
+		case strings.Contains(r.URL.Path, "/users/me/messages/m1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "m1", "threadId": "t1",
+				"payload": map[string]any{"headers": []map[string]any{{"name": "Subject", "value": "Hello"}}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func seedPullWatchState(t *testing.T) *gmailWatchStore {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		*s = gmailWatchState{Account: "a@b.com", Topic: "projects/p/topics/t", HistoryID: "100"}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return store
+}
+
+func TestGmailWatchPull_AcksAfterHookDelivery(t *testing.T) {
+	seedPullWatchState(t)
+	newPullTestGmail(t)
+
+	var hookCalls atomic.Int32
+	var delivered gmailHookPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hookCalls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&delivered)
+	}))
+	t.Cleanup(hook.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakePubSub(t, cancel,
+		`{"emailAddress":"a@b.com","historyId":200}`,
+		`{"emailAddress":"other@b.com","historyId":5}`,
+		`not json`,
+	)
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	err = runKong(t, &GmailWatchPullCmd{}, []string{"--subscription", "projects/p/subscriptions/s", "--hook-url", hook.URL}, ui.WithUI(ctx, u), &RootFlags{Account: "a@b.com"})
+	if err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+
+	// First delivery fails: the Gmail notification is nacked and redelivered,
+	// the others are acked straight away.
+	if strings.Join(f.nacked, ",") != "ack-a-1" {
+		t.Fatalf("nacked: %v", f.nacked)
+	}
+	if strings.Join(f.acked, ",") != "ack-b-1,ack-c-1,ack-a-2" {
+		t.Fatalf("acked: %v", f.acked)
+	}
+	if hookCalls.Load() != 2 || len(delivered.Messages) != 1 || delivered.Messages[0].Subject != "Hello" {
+		t.Fatalf("unexpected delivery (%d calls): %+v", hookCalls.Load(), delivered)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if st := store.Get(); st.HistoryID != "200" || st.LastDeliveryStatus != "ok" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
+
+func TestGmailWatchPull_NoHookWritesNDJSON(t *testing.T) {
+	seedPullWatchState(t)
+	newPullTestGmail(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakePubSub(t, cancel, `{"emailAddress":"a@b.com","historyId":"200"}`)
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	out := captureStdout(t, func() {
+		if err := runKong(t, &GmailWatchPullCmd{}, []string{"--subscription", "projects/p/subscriptions/s"}, ui.WithUI(ctx, u), &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("pull: %v", err)
+		}
+	})
+	var got gmailHookPayload
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &got); err != nil {
+		t.Fatalf("ndjson: %v\n%s", err, out)
+	}
+	if got.HistoryID != "200" || len(got.Messages) != 1 || len(f.acked) != 1 {
+		t.Fatalf("unexpected: %+v acked=%v", got, f.acked)
+	}
+}
+
+func TestGmailWatchPull_Validation(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--subscription", "projects/p/topics/t"},
+		{"--subscription", "projects/p/subscriptions/s", "--max-messages", "0"},
+	} {
+		if err := runKong(t, &GmailWatchPullCmd{}, args, context.Background(), &RootFlags{Account: "a@b.com"}); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestGmailWatchPullWaitFor(t *testing.T) {
+	cases := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{googleapi.DefaultHTTPTimeout, gmailWatchPullWait},
+		{10 * time.Second, 5 * time.Second},
+		{6 * time.Second, time.Second},
+	}
+	for _, tc := range cases {
+		got, err := gmailWatchPullWaitFor(tc.timeout)
+		if err != nil || got != tc.want {
+			t.Fatalf("%s: got %s, %v; want %s", tc.timeout, got, err, tc.want)
+		}
+	}
+
+	cfg := googleapi.DefaultRetryConfig()
+	cfg.Timeout = 5 * time.Second
+	ctx := googleapi.WithRetryConfig(context.Background(), cfg)
+	err := runKong(t, &GmailWatchPullCmd{}, []string{"--subscription", "projects/p/subscriptions/s"}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || ExitCode(err) != 2 || !strings.Contains(err.Error(), "--http-timeout of at least 6s") {
+		t.Fatalf("expected --http-timeout usage error, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// gmailWatchRenewMargin is how long before expiry an auto-renewal runs
+	// when no renewAfterMs is stored.
+	gmailWatchRenewMargin = 24 * time.Hour
+	gmailWatchRenewRetry  = 5 * time.Minute
+)
+
+// watchRenewAt returns when a watch is due for renewal: renewAfterMs when
+// set, else a day before it expires. Zero means it cannot be renewed (no
+// topic, e.g. poll-only state) or its expiry is unknown.
+func watchRenewAt(state gmailWatchState) time.Time {
+	if state.Topic == "" {
+		return time.Time{}
+	}
+	if state.RenewAfterMs > 0 {
+		return time.UnixMilli(state.RenewAfterMs)
+	}
+	if state.ExpirationMs > 0 {
+		return time.UnixMilli(state.ExpirationMs).Add(-gmailWatchRenewMargin)
+	}
+	return time.Time{}
+}
+
+// runRenewal keeps the account's Gmail watch alive until ctx ends, so
+// `watch serve` needs no `watch renew` cron. Failed renewals are retried
+// every gmailWatchRenewRetry; renewal stops for good once `watch stop` has
+// removed the state file.
+func (s *gmailWatchServer) runRenewal(ctx context.Context) {
+	for {
+		at := watchRenewAt(s.store.Get())
+		if at.IsZero() {
+			return
+		}
+		if wait := time.Until(at); wait > 0 {
+			if sleepContext(ctx, wait) != nil {
+				return
+			}
+		}
+		if err := s.renewWatch(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, errGmailWatchStopped) {
+				s.warnf("watch: %s was stopped; auto-renew disabled", s.cfg.Account)
+				return
+			}
+			s.warnf("watch: renew %s failed (retrying in %s): %v", s.cfg.Account, gmailWatchRenewRetry, err)
+			if sleepContext(ctx, gmailWatchRenewRetry) != nil {
+				return
+			}
+		}
+	}
+}
+
+// renewWatch calls users.watch with the stored topic and labels and
+// schedules the next renewal a day before the new expiry. Unlike `watch
+// renew` it keeps the stored history ID, so changes not yet delivered are
+// not skipped. The state is re-read from disk under the file lock first, so
+// a watch stopped by another process is not re-created.
+func (s *gmailWatchServer) renewWatch(ctx context.Context) error {
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		return err
+	}
+
+	var expiration int64
+	err = s.store.UpdateExisting(func(st *gmailWatchState) error {
+		if st.Topic == "" {
+			return errGmailWatchStopped
+		}
+		resp, err := requestGmailWatch(ctx, svc, st.Topic, st.Labels)
+		if err != nil {
+			return err
+		}
+		if resp == nil || resp.Expiration == 0 {
+			return errors.New("watch response missing expiration")
+		}
+
+		now := time.Now()
+		expires := time.UnixMilli(resp.Expiration)
+		next := expires.Add(-gmailWatchRenewMargin)
+		if !next.After(now) {
+			next = now.Add(max(expires.Sub(now)/2, gmailWatchRenewRetry))
+		}
+		st.ExpirationMs = resp.Expiration
+		st.ProviderExpirationMs = resp.Expiration
+		st.RenewAfterMs = next.UnixMilli()
+		st.UpdatedAtMs = now.UnixMilli()
+		if st.HistoryID == "" {
+			st.HistoryID = formatHistoryID(resp.HistoryId)
+		}
+		expiration = resp.Expiration
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.logf("watch: renewed %s until %s", s.cfg.Account, formatUnixMillis(expiration))
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func TestWatchRenewAt(t *testing.T) {
+	exp := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Millisecond)
+	renew := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	cases := []struct {
+		name  string
+		state gmailWatchState
+		want  time.Time
+	}{
+		{"no topic", gmailWatchState{ExpirationMs: exp.UnixMilli()}, time.Time{}},
+		{"no expiry", gmailWatchState{Topic: "t"}, time.Time{}},
+		{"renew after", gmailWatchState{Topic: "t", ExpirationMs: exp.UnixMilli(), RenewAfterMs: renew.UnixMilli()}, renew},
+		{"before expiry", gmailWatchState{Topic: "t", ExpirationMs: exp.UnixMilli()}, exp.Add(-gmailWatchRenewMargin)},
+	}
+	for _, tc := range cases {
+		if got := watchRenewAt(tc.state); !got.Equal(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGmailWatchServer_RunRenewal(t *testing.T) {
+	store := seedPullWatchState(t)
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Labels = []string{"INBOX"}
+		s.RenewAfterMs = time.Now().Add(-time.Second).UnixMilli()
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	exp := time.Now().Add(7 * 24 * time.Hour).UnixMilli()
+	requests := make(chan gmail.WatchRequest, 4)
+	svc := stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/users/me/watch") {
+			http.NotFound(w, r)
+			return
+		}
+		var req gmail.WatchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests <- req
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"historyId": "999", "expiration": strconv.FormatInt(exp, 10)})
+	}))
+
+	s := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com"},
+		store:      store,
+		newService: func(context.Context, string) (*gmail.Service, error) { return svc, nil },
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runRenewal(ctx)
+	}()
+
+	select {
+	case req := <-requests:
+		if req.TopicName != "projects/p/topics/t" || len(req.LabelIds) != 1 || req.LabelIds[0] != "INBOX" {
+			t.Fatalf("unexpected watch request: %+v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("renewal not attempted")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Get().ExpirationMs != exp && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	st := store.Get()
+	if st.ExpirationMs != exp || st.RenewAfterMs != exp-gmailWatchRenewMargin.Milliseconds() || st.HistoryID != "100" {
+		t.Fatalf("unexpected state after renewal: %+v", st)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected a single renewal, got %d more", len(requests))
+	}
+}
+
+func TestGmailWatchServer_RunRenewalStopsAfterWatchStop(t *testing.T) {
+	store := seedPullWatchState(t)
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.RenewAfterMs = time.Now().Add(-time.Second).UnixMilli()
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	// `watch stop` in another process removes the file; this store still
+	// holds the state in memory.
+	if err := os.Remove(store.path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	var calls int
+	svc := stubGmailService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.NotFound(w, r)
+	}))
+
+	var warned string
+	s := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com"},
+		store:      store,
+		newService: func(context.Context, string) (*gmail.Service, error) { return svc, nil },
+		logf:       func(string, ...any) {},
+		warnf:      func(format string, args ...any) { warned = fmt.Sprintf(format, args...) },
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runRenewal(context.Background())
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("renewal loop kept running after watch stop")
+	}
+
+	if calls != 0 {
+		t.Fatalf("expected no users.watch call, got %d", calls)
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Fatalf("state file was written back: %v", err)
+	}
+	if !strings.Contains(warned, "stopped") {
+		t.Fatalf("expected a stop warning, got %q", warned)
+	}
+}
+
+func TestGmailWatchServeCmd_MultiAccount(t *testing.T) {
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+
+	seedPullWatchState(t)
+	other, err := newGmailWatchStore("c@d.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := other.Update(func(s *gmailWatchState) error {
+		*s = gmailWatchState{Account: "c@d.com", Topic: "projects/p/topics/t", HistoryID: "100"}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	newPullTestGmail(t)
+
+	var handler *gmailWatchServer
+	listenAndServe = func(srv *http.Server) error {
+		handler, _ = srv.Handler.(*gmailWatchServer)
+		return nil
+	}
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	flags := &RootFlags{Accounts: "a@b.com,c@d.com"}
+	if err := runKong(t, &GmailWatchServeCmd{}, []string{"--no-auto-renew"}, ui.WithUI(context.Background(), u), flags); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if handler == nil || len(handler.accounts) != 2 || handler.accounts["c@d.com"].cfg.Account != "c@d.com" {
+		t.Fatalf("unexpected handler: %+v", handler)
+	}
+
+	if code := postTestPush(t, handler, "C@D.com"); code != http.StatusOK {
+		t.Fatalf("status: %d", code)
+	}
+	a, _ := loadGmailWatchStore("a@b.com")
+	c, _ := loadGmailWatchStore("c@d.com")
+	if a.Get().HistoryID != "100" || c.Get().HistoryID != "200" {
+		t.Fatalf("push not routed to c@d.com: a=%s c=%s", a.Get().HistoryID, c.Get().HistoryID)
+	}
+	if code := postTestPush(t, handler, "x@y.com"); code != http.StatusAccepted {
+		t.Fatalf("expected unknown account to be ignored, got %d", code)
+	}
+
+	if err := runKong(t, &GmailWatchServeCmd{}, nil, ui.WithUI(context.Background(), u), &RootFlags{Accounts: "a@b.com,e@f.com"}); err == nil || !strings.Contains(err.Error(), "e@f.com") {
+		t.Fatalf("expected missing state error, got %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gmailWatchRule routes the messages it matches to its own hook, or drops
+// them. Rules are checked in order and the first match wins; unmatched
+// messages go to the default hook (--hook-url/--hook-exec).
+type gmailWatchRule struct {
+	Name  string              `json:"name" yaml:"name,omitempty"`
+	Match gmailWatchRuleMatch `json:"match" yaml:"match"`
+	Hook  *gmailWatchTarget   `json:"hook,omitempty" yaml:"hook,omitempty"`
+	Drop  bool                `json:"drop,omitempty" yaml:"drop,omitempty"`
+}
+
+// gmailWatchRuleMatch conditions must all hold. From and Subject are
+// case-insensitive substrings; Label is a label ID (names are resolved
+// when the rules are loaded).
+type gmailWatchRuleMatch struct {
+	From          string `json:"from,omitempty" yaml:"from,omitempty"`
+	Subject       string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Label         string `json:"label,omitempty" yaml:"label,omitempty"`
+	HasAttachment bool   `json:"hasAttachment,omitempty" yaml:"hasAttachment,omitempty"`
+}
+
+// gmailWatchTarget is one place payloads are delivered to: an HTTP hook or
+// a command that reads the payload on stdin.
+type gmailWatchTarget struct {
+	Route  string `json:"-" yaml:"-"` // rule name; empty for the default hook
+	URL    string `json:"url,omitempty" yaml:"url,omitempty"`
+	Token  string `json:"token,omitempty" yaml:"token,omitempty"`
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Exec   string `json:"exec,omitempty" yaml:"exec,omitempty"`
+}
+
+func (t gmailWatchTarget) empty() bool {
+	return t.URL == "" && t.Exec == ""
+}
+
+// gmailWatchRoute is the part of a payload bound for one target.
+type gmailWatchRoute struct {
+	Target  gmailWatchTarget
+	Payload *gmailHookPayload
+}
+
+// parseWatchRules decodes a rules file ({"rules": [...]}), JSON when format
+// is "json" and YAML otherwise. Unnamed rules are called rule-<n>.
+func parseWatchRules(data []byte, format string) ([]gmailWatchRule, error) {
+	var file struct {
+		Rules []gmailWatchRule `json:"rules" yaml:"rules"`
+	}
+	if format == "json" {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("parse rules JSON: %w", err)
+		}
+	} else {
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("parse rules YAML: %w", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("rules[%d]: duplicate name %q", i, r.Name)
+		}
+		seen[r.Name] = true
+		r.Match.Label = strings.TrimSpace(r.Match.Label)
+		if r.Match == (gmailWatchRuleMatch{}) {
+			return nil, fmt.Errorf("rules[%d]: no match conditions", i)
+		}
+		switch {
+		case r.Drop && r.Hook != nil:
+			return nil, fmt.Errorf("rules[%d]: drop and hook are mutually exclusive", i)
+		case !r.Drop && (r.Hook == nil || r.Hook.empty()):
+			return nil, fmt.Errorf("rules[%d]: set hook.url, hook.exec or drop", i)
+		case r.Hook != nil && r.Hook.URL != "" && r.Hook.Exec != "":
+			return nil, fmt.Errorf("rules[%d]: hook.url and hook.exec are mutually exclusive", i)
+		case r.Hook != nil && r.Hook.Exec != "" && (r.Hook.Token != "" || r.Hook.Secret != ""):
+			return nil, fmt.Errorf("rules[%d]: hook.token and hook.secret only apply to hook.url", i)
+		}
+	}
+	return file.Rules, nil
+}
+
+// resolveWatchRules loads the --rules file, resolving label names to IDs,
+// or returns the rules saved in the watch state when path is empty.
+func resolveWatchRules(ctx context.Context, account, path string, saved []gmailWatchRule) ([]gmailWatchRule, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return saved, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+	rules, err := parseWatchRules(data, format)
+	if err != nil {
+		return nil, newUsageError(err)
+	}
+
+	var labels []string
+	for _, r := range rules {
+		if r.Match.Label != "" {
+			labels = append(labels, r.Match.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return rules, nil
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := resolveLabelIDsWithService(svc, labels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].Match.Label != "" {
+			rules[i].Match.Label, ids = ids[0], ids[1:]
+		}
+	}
+	return rules, nil
+}
+
+func (m gmailWatchRuleMatch) matches(msg gmailHookMessage) bool {
+	if m.From != "" && !containsFold(msg.From, m.From) {
+		return false
+	}
+	if m.Subject != "" && !containsFold(msg.Subject, m.Subject) {
+		return false
+	}
+	if m.Label != "" && !hasAnyLabel(msg.Labels, []string{m.Label}) {
+		return false
+	}
+	if m.HasAttachment && !msg.hasAttachment {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// watchRulesNeedParts reports whether matching needs the full message
+// (MIME parts), not just its metadata.
+func watchRulesNeedParts(rules []gmailWatchRule) bool {
+	for _, r := range rules {
+		if r.Match.HasAttachment {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHookTargets reports whether any payload can go to a hook.
+func (c gmailWatchServeConfig) hasHookTargets() bool {
+	if !c.defaultTarget().empty() {
+		return true
+	}
+	for _, r := range c.Rules {
+		if r.Hook != nil && !r.Hook.empty() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c gmailWatchServeConfig) defaultTarget() gmailWatchTarget {
+	return gmailWatchTarget{URL: c.HookURL, Token: c.HookToken, Secret: c.HookSecret, Exec: c.HookExec}
+}
+
+// routeTarget returns the target for a rule name ("" for the default
+// hook); ok is false when no such rule has a hook anymore.
+func (c gmailWatchServeConfig) routeTarget(route string) (gmailWatchTarget, bool) {
+	if route == "" {
+		return c.defaultTarget(), true
+	}
+	for _, r := range c.Rules {
+		if r.Name == route && r.Hook != nil {
+			t := *r.Hook
+			t.Route = r.Name
+			return t, true
+		}
+	}
+	return gmailWatchTarget{}, false
+}
+
+// routePayload splits a payload by rule, keeping message order. Messages
+// no rule claims go to the default hook; when there is none they are
+// returned as rest (nil when empty). Without rules the whole payload,
+// empty or not, takes the default route.
+func (s *gmailWatchServer) routePayload(p *gmailHookPayload) ([]gmailWatchRoute, *gmailHookPayload) {
+	def := s.cfg.defaultTarget()
+	if len(s.cfg.Rules) == 0 {
+		if def.empty() {
+			return nil, p
+		}
+		return []gmailWatchRoute{{Target: def, Payload: p}}, nil
+	}
+
+	var routes []gmailWatchRoute
+	index := map[string]int{}
+	var rest *gmailHookPayload
+	add := func(t gmailWatchTarget, msg gmailHookMessage) {
+		i, ok := index[t.Route]
+		if !ok {
+			i = len(routes)
+			index[t.Route] = i
+			routes = append(routes, gmailWatchRoute{Target: t, Payload: &gmailHookPayload{Source: p.Source, Account: p.Account, HistoryID: p.HistoryID}})
+		}
+		routes[i].Payload.Messages = append(routes[i].Payload.Messages, msg)
+	}
+
+next:
+	for _, msg := range p.Messages {
+		for _, r := range s.cfg.Rules {
+			if !r.Match.matches(msg) {
+				continue
+			}
+			if !r.Drop {
+				t := *r.Hook
+				t.Route = r.Name
+				add(t, msg)
+			}
+			continue next
+		}
+		if !def.empty() {
+			add(def, msg)
+			continue
+		}
+		if rest == nil {
+			rest = &gmailHookPayload{Source: p.Source, Account: p.Account, HistoryID: p.HistoryID}
+		}
+		rest.Messages = append(rest.Messages, msg)
+	}
+	return routes, rest
+}
+
+// describeWatchRule is a one-line summary, e.g.
+// "from:@acme.com has:attachment => exec ./ticket.sh".
+func describeWatchRule(r gmailWatchRule) string {
+	var conds []string
+	m := r.Match
+	if m.From != "" {
+		conds = append(conds, "from:"+m.From)
+	}
+	if m.Subject != "" {
+		conds = append(conds, "subject:"+m.Subject)
+	}
+	if m.Label != "" {
+		conds = append(conds, "label:"+m.Label)
+	}
+	if m.HasAttachment {
+		conds = append(conds, "has:attachment")
+	}
+	action := "drop"
+	switch {
+	case r.Hook == nil:
+	case r.Hook.Exec != "":
+		action = "exec " + r.Hook.Exec
+	default:
+		action = r.Hook.URL
+	}
+	return strings.Join(conds, " ") + " => " + action
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseWatchRules(t *testing.T) {
+	rules, err := parseWatchRules([]byte(`
+rules:
+  - name: tickets
+    match: {from: "@acme.com", hasAttachment: true}
+    hook: {url: "http://127.0.0.1:9/tickets", token: tok}
+  - match: {label: " Newsletters "}
+    drop: true
+`), "yaml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Hook.Token != "tok" || rules[1].Name != "rule-2" || rules[1].Match.Label != "Newsletters" || !rules[1].Drop {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+	if got := describeWatchRule(rules[0]); got != "from:@acme.com has:attachment => http://127.0.0.1:9/tickets" {
+		t.Fatalf("describe: %q", got)
+	}
+
+	for name, src := range map[string]string{
+		"no match":      `{"rules":[{"hook":{"exec":"true"}}]}`,
+		"no action":     `{"rules":[{"match":{"from":"a"}}]}`,
+		"drop and hook": `{"rules":[{"match":{"from":"a"},"drop":true,"hook":{"exec":"true"}}]}`,
+		"url and exec":  `{"rules":[{"match":{"from":"a"},"hook":{"url":"http://x","exec":"true"}}]}`,
+		"exec token":    `{"rules":[{"match":{"from":"a"},"hook":{"exec":"true","token":"t"}}]}`,
+		"duplicate":     `{"rules":[{"name":"a","match":{"from":"a"},"drop":true},{"name":"a","match":{"from":"b"},"drop":true}]}`,
+	} {
+		if _, err := parseWatchRules([]byte(src), "json"); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := parseWatchRules([]byte("rules:\n  - match: {sender: a}\n    drop: true\n"), "yaml"); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
+
+func TestRunHookExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOOK_DIR", dir)
+	err := runHookExec(context.Background(), `cat > "$HOOK_DIR/payload.json"; printf %s "$GOG_ACCOUNT $GOG_DELIVERY_ID" > "$HOOK_DIR/env"`, "a@b.com", "d1", []byte(`{"source":"gmail"}`))
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "payload.json")); string(b) != `{"source":"gmail"}` {
+		t.Fatalf("stdin: %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "env")); string(b) != "a@b.com d1" {
+		t.Fatalf("env: %q", b)
+	}
+
+	err = runHookExec(context.Background(), "echo boom >&2; exit 3", "a@b.com", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected exit error with stderr, got %v", err)
+	}
+}
+
+func TestGmailWatchPoll_RulesRouteAndDrop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	seedPullWatchState(t)
+	newFakePollGmail(t, &fakePollGmail{
+		latestID: "200",
+		history:  map[string][]string{"100": {"m1", "m2", "m3", "m4"}},
+		labels:   map[string][]string{"m1": {"INBOX"}, "m2": {"INBOX", "Label_7"}, "m3": {"INBOX"}, "m4": {"INBOX"}},
+		from: map[string]string{
+			"m1": "Alice <alice@ACME.com>",
+			"m2": "news@shop.example",
+			"m3": "bob@example.com",
+			"m4": "carol@acme.com",
+		},
+		attachments: map[string]bool{"m1": true},
+	})
+
+	var mu sync.Mutex
+	var tickets []gmailHookPayload
+	var auth string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var p gmailHookPayload
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		tickets = append(tickets, p)
+		auth = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(hook.Close)
+
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rulesPath, []byte(`rules:
+  - name: tickets
+    match: {from: "@acme.com", hasAttachment: true}
+    hook: {url: "`+hook.URL+`", token: tok}
+  - name: newsletters
+    match: {label: Newsletters}
+    drop: true
+`), 0o600); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	t.Setenv("HOOK_OUT", filepath.Join(dir, "default.json"))
+
+	if _, err := runGmailWatchPoll(t, "--once", "--rules", rulesPath, "--hook-exec", `cat > "$HOOK_OUT"`, "--save-hook"); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if len(tickets) != 1 || len(tickets[0].Messages) != 1 || tickets[0].Messages[0].ID != "m1" || auth != "Bearer tok" {
+		t.Fatalf("unexpected ticket deliveries: %+v (auth %q)", tickets, auth)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "default.json"))
+	if err != nil {
+		t.Fatalf("exec hook output: %v", err)
+	}
+	var def gmailHookPayload
+	if err := json.Unmarshal(data, &def); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(def.Messages) != 2 || def.Messages[0].ID != "m3" || def.Messages[1].ID != "m4" || def.HistoryID != "200" {
+		t.Fatalf("unexpected default delivery: %+v", def)
+	}
+
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	st := store.Get()
+	if st.Hook == nil || st.Hook.Exec != `cat > "$HOOK_OUT"` || st.Hook.URL != "" {
+		t.Fatalf("unexpected saved hook: %+v", st.Hook)
+	}
+	if len(st.Rules) != 2 || st.Rules[1].Match.Label != "Label_7" {
+		t.Fatalf("expected saved rules with resolved label, got %+v", st.Rules)
+	}
+}
+
+func TestGmailWatchServer_OutboxRoutesExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	s := newOutboxTestServer(t, "", 3)
+	dir := t.TempDir()
+	t.Setenv("HOOK_DIR", dir)
+	s.cfg.HookSecret = ""
+	s.cfg.Rules = []gmailWatchRule{{
+		Name:  "hello",
+		Match: gmailWatchRuleMatch{Subject: "hello"},
+		Hook:  &gmailWatchTarget{Exec: `cat > "$HOOK_DIR/$GOG_DELIVERY_ID.json"`},
+	}}
+
+	if code := postTestPush(t, s, "a@b.com"); code != http.StatusOK {
+		t.Fatalf("status: %d", code)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one exec delivery, got %v (%v)", entries, err)
+	}
+	if p, d, _ := s.outbox.counts(); p+d != 0 {
+		t.Fatalf("queue not empty: %d pending, %d dead", p, d)
+	}
+}
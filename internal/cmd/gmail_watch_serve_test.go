@@ -18,6 +18,7 @@ func TestGmailWatchServeCmd_UsesStoredHook(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -74,6 +75,7 @@ func TestGmailWatchServeCmd_DefaultMaxBytes(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -124,6 +126,7 @@ func TestGmailWatchServeCmd_SaveHookAndOIDC(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
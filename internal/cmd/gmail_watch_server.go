@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"crypto/subtle"
 	"encoding/base64"
@@ -10,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,6 +31,8 @@ type gmailWatchServer struct {
 	validator  *idtoken.Validator
 	newService func(context.Context, string) (*gmail.Service, error)
 	hookClient *http.Client
+	outbox     *gmailHookOutbox             // durable hook queue (serve); nil delivers once
+	accounts   map[string]*gmailWatchServer // multi-account serve: lowercased account -> server
 	logf       func(string, ...any)
 	warnf      func(string, ...any)
 }
@@ -62,12 +64,37 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account) {
+	target := s.accountServer(payload.EmailAddress)
+	if target == nil {
 		s.warnf("watch: ignoring push for %s", payload.EmailAddress)
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	target.handleNotification(w, r, payload)
+}
 
+// accountServer returns the server for the account a push names: s itself
+// in single-account mode, else the matching entry of s.accounts. Pushes
+// without an address go to the only account; nil means not ours.
+func (s *gmailWatchServer) accountServer(email string) *gmailWatchServer {
+	if s.accounts == nil {
+		if email != "" && !strings.EqualFold(email, s.cfg.Account) {
+			return nil
+		}
+		return s
+	}
+	if email == "" && len(s.accounts) == 1 {
+		for _, server := range s.accounts {
+			return server
+		}
+	}
+	return s.accounts[strings.ToLower(email)]
+}
+
+// handleNotification syncs history for an authorized push and hands the
+// result to the hooks.
+func (s *gmailWatchServer) handleNotification(w http.ResponseWriter, r *http.Request, payload gmailPushPayload) {
+	prevHistoryID := s.store.Get().HistoryID
 	result, err := s.handlePush(r.Context(), payload)
 	if err != nil {
 		if errors.Is(err, errNoNewMessages) {
@@ -83,20 +110,37 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s.cfg.HookURL == "" {
-		if s.cfg.AllowNoHook {
-			_ = json.NewEncoder(w).Encode(result)
+	routes, rest := s.routePayload(result)
+	if len(routes) == 0 {
+		if rest != nil && s.cfg.AllowNoHook {
+			_ = json.NewEncoder(w).Encode(rest)
 			return
 		}
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
-	if err := s.sendHook(r.Context(), result); err != nil {
-		s.warnf("watch: hook failed: %v", err)
+	if s.outbox == nil {
+		for _, route := range routes {
+			if err := s.sendHookTo(r.Context(), route.Target, route.Payload); err != nil {
+				s.warnf("watch: hook failed: %v", err)
+			}
+		}
 		w.WriteHeader(http.StatusOK)
 		return
 	}
+	if err := s.queueRoutes(r.Context(), routes); err != nil {
+		// Not queued: rewind history and let Pub/Sub redeliver.
+		s.warnf("watch: queue hook delivery failed: %v", err)
+		if updateErr := s.store.Update(func(state *gmailWatchState) error {
+			state.HistoryID = prevHistoryID
+			return nil
+		}); updateErr != nil {
+			s.warnf("watch: failed to restore state: %v", updateErr)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -160,10 +204,7 @@ func (s *gmailWatchServer) handlePush(ctx context.Context, payload gmailPushPayl
 		return nil, err
 	}
 
-	historyCall := svc.Users.History.List("me").StartHistoryId(startID).MaxResults(s.cfg.HistoryMax)
-	historyCall.HistoryTypes("messageAdded")
-
-	historyResp, err := historyCall.Do()
+	messageIDs, latestID, err := s.listHistory(ctx, svc, startID)
 	if err != nil {
 		if isStaleHistoryError(err) {
 			return s.resyncHistory(ctx, svc, payload.HistoryID)
@@ -171,15 +212,14 @@ func (s *gmailWatchServer) handlePush(ctx context.Context, payload gmailPushPayl
 		return nil, err
 	}
 
-	messageIDs := collectHistoryMessageIDs(historyResp)
 	msgs, err := s.fetchMessages(ctx, svc, messageIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	nextHistoryID := payload.HistoryID
-	if historyResp != nil && historyResp.HistoryId != 0 {
-		nextHistoryID = formatHistoryID(historyResp.HistoryId)
+	if latestID != 0 {
+		nextHistoryID = formatHistoryID(latestID)
 	}
 	if err := store.Update(func(state *gmailWatchState) error {
 		state.HistoryID = nextHistoryID
@@ -197,8 +237,58 @@ func (s *gmailWatchServer) handlePush(ctx context.Context, payload gmailPushPayl
 	}, nil
 }
 
+// listHistory returns the messages added since startID across all history
+// pages, plus the mailbox history ID to resume from. Stopping at the first
+// page would store a history ID past records never read.
+func (s *gmailWatchServer) listHistory(ctx context.Context, svc *gmail.Service, startID uint64) ([]string, uint64, error) {
+	var ids []string
+	var latestID uint64
+	seen := make(map[string]struct{})
+	pageToken := ""
+	for {
+		call := svc.Users.History.List("me").StartHistoryId(startID).MaxResults(s.cfg.HistoryMax).Context(ctx)
+		call.HistoryTypes("messageAdded")
+		if len(s.cfg.Labels) == 1 {
+			call.LabelId(s.cfg.Labels[0])
+		}
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, id := range collectHistoryMessageIDs(resp) {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+		if resp.HistoryId != 0 {
+			latestID = resp.HistoryId
+		}
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			return ids, latestID, nil
+		}
+	}
+}
+
 func (s *gmailWatchServer) resyncHistory(ctx context.Context, svc *gmail.Service, historyID string) (*gmailHookPayload, error) {
-	list, err := svc.Users.Messages.List("me").MaxResults(s.cfg.ResyncMax).Do()
+	if historyID == "" {
+		// Polling has no notification history ID: restart from the mailbox's.
+		profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		historyID = formatHistoryID(profile.HistoryId)
+	}
+	listCall := svc.Users.Messages.List("me").MaxResults(s.cfg.ResyncMax)
+	if len(s.cfg.Labels) == 1 {
+		listCall.LabelIds(s.cfg.Labels[0])
+	}
+	list, err := listCall.Do()
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +322,8 @@ func (s *gmailWatchServer) resyncHistory(ctx context.Context, svc *gmail.Service
 func (s *gmailWatchServer) fetchMessages(ctx context.Context, svc *gmail.Service, ids []string) ([]gmailHookMessage, error) {
 	messages := make([]gmailHookMessage, 0, len(ids))
 	format := gmailWatchFormatMetadata
-	if s.cfg.IncludeBody {
+	fullMessage := s.cfg.IncludeBody || watchRulesNeedParts(s.cfg.Rules)
+	if fullMessage {
 		format = "full"
 	}
 	for _, id := range ids {
@@ -247,7 +338,7 @@ func (s *gmailWatchServer) fetchMessages(ctx context.Context, svc *gmail.Service
 		if err != nil {
 			return nil, err
 		}
-		if msg == nil {
+		if msg == nil || !hasAnyLabel(msg.LabelIds, s.cfg.Labels) {
 			continue
 		}
 		item := gmailHookMessage{
@@ -260,6 +351,9 @@ func (s *gmailWatchServer) fetchMessages(ctx context.Context, svc *gmail.Service
 			Snippet:  msg.Snippet,
 			Labels:   msg.LabelIds,
 		}
+		if fullMessage {
+			item.hasAttachment = len(collectAttachments(msg.Payload)) > 0
+		}
 		if s.cfg.IncludeBody {
 			body := bestBodyText(msg.Payload)
 			item.Body, item.BodyTruncated = truncateUTF8Bytes(body, s.cfg.MaxBodyBytes)
@@ -269,38 +363,84 @@ func (s *gmailWatchServer) fetchMessages(ctx context.Context, svc *gmail.Service
 	return messages, nil
 }
 
-func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPayload) error {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
+// hasAnyLabel reports whether labels contains one of want (or want is empty).
+func hasAnyLabel(labels, want []string) bool {
+	if len(want) == 0 {
+		return true
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.HookURL, bytes.NewReader(data))
+	for _, l := range labels {
+		if slices.Contains(want, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// processNotification runs the history sync for one notification and
+// delivers the result: to the hooks picked by the routing rules, or as an
+// NDJSON line to out for messages without a hook. Payloads without messages
+// are not delivered. On a delivery error the stored history ID is restored
+// so a retry sees the same messages (routes that succeeded get them again);
+// a nil error means the notification is done with.
+func (s *gmailWatchServer) processNotification(ctx context.Context, payload gmailPushPayload, out io.Writer) error {
+	prevHistoryID := s.store.Get().HistoryID
+	result, err := s.handlePush(ctx, payload)
 	if err != nil {
+		if errors.Is(err, errNoNewMessages) {
+			return nil
+		}
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if s.cfg.HookToken != "" {
-		req.Header.Set("Authorization", "Bearer "+s.cfg.HookToken)
+	if result == nil || len(result.Messages) == 0 {
+		return nil
 	}
-	resp, err := s.hookClient.Do(req)
-	if err != nil {
-		_ = s.store.Update(func(state *gmailWatchState) error {
-			state.LastDeliveryStatus = "error"
-			state.LastDeliveryAtMs = time.Now().UnixMilli()
-			state.LastDeliveryStatusNote = err.Error()
+
+	routes, rest := s.routePayload(result)
+	if rest != nil {
+		if err := json.NewEncoder(out).Encode(rest); err != nil {
+			return err
+		}
+	}
+	var hookErr error
+	for _, route := range routes {
+		if err := s.sendHookTo(ctx, route.Target, route.Payload); err != nil {
+			hookErr = err
+		}
+	}
+	if hookErr != nil {
+		if updateErr := s.store.Update(func(state *gmailWatchState) error {
+			state.HistoryID = prevHistoryID
 			return nil
-		})
+		}); updateErr != nil {
+			s.warnf("watch: failed to restore state: %v", updateErr)
+		}
+		return fmt.Errorf("hook: %w", hookErr)
+	}
+	return nil
+}
+
+func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPayload) error {
+	return s.sendHookTo(ctx, s.cfg.defaultTarget(), payload)
+}
+
+func (s *gmailWatchServer) sendHookTo(ctx context.Context, t gmailWatchTarget, payload *gmailHookPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if err := s.deliverTo(ctx, t, "", data); err != nil {
+		status := "error"
+		var statusErr *hookStatusError
+		if errors.As(err, &statusErr) {
+			status = gmailWatchStatusHTTPError
+		}
 		_ = s.store.Update(func(state *gmailWatchState) error {
-			state.LastDeliveryStatus = gmailWatchStatusHTTPError
+			state.LastDeliveryStatus = status
 			state.LastDeliveryAtMs = time.Now().UnixMilli()
-			state.LastDeliveryStatusNote = fmt.Sprintf("status %d", resp.StatusCode)
+			state.LastDeliveryStatusNote = err.Error()
 			return nil
 		})
-		return fmt.Errorf("hook status %d", resp.StatusCode)
+		return err
 	}
 	_ = s.store.Update(func(state *gmailWatchState) error {
 		state.LastDeliveryStatus = "ok"
@@ -23,6 +23,7 @@ import (
 func TestGmailWatchServer_ServeHTTP_AllowNoHook(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -179,6 +180,7 @@ func TestGmailWatchServer_OIDCAudience(t *testing.T) {
 func TestGmailWatchServer_ResyncHistory_OnStaleError(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -264,6 +266,7 @@ func TestGmailWatchServer_ResyncHistory_OnStaleError(t *testing.T) {
 func TestGmailWatchServer_SendHook_UpdatesState(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -313,6 +316,7 @@ func TestGmailWatchServer_SendHook_UpdatesState(t *testing.T) {
 func TestGmailWatchServer_ServeHTTP_HookError(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("a@b.com")
 	if err != nil {
@@ -63,21 +63,34 @@ func newGmailWatchStore(account string) (*gmailWatchStore, error) {
 }
 
 func loadGmailWatchStore(account string) (*gmailWatchStore, error) {
-	store, err := newGmailWatchStore(account)
+	store, found, err := openGmailWatchStore(account)
 	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return nil, errors.New("watch state not found; run gmail watch start")
+	}
+	return store, nil
+}
+
+// openGmailWatchStore loads the account's watch state, or returns an empty
+// store (found = false) when there is none yet.
+func openGmailWatchStore(account string) (*gmailWatchStore, bool, error) {
+	store, err := newGmailWatchStore(account)
+	if err != nil {
+		return nil, false, err
+	}
 	data, err := os.ReadFile(store.path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, errors.New("watch state not found; run gmail watch start")
+			return store, false, nil
 		}
-		return nil, err
+		return nil, false, err
 	}
 	if err := json.Unmarshal(data, &store.state); err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return store, nil
+	return store, true, nil
 }
 
 func (s *gmailWatchStore) Get() gmailWatchState {
@@ -86,13 +99,77 @@ func (s *gmailWatchStore) Get() gmailWatchState {
 	return s.state
 }
 
+// Update applies fn to the state on disk and saves it. Other gog processes
+// (e.g. `watch deliveries retry` next to a running `watch serve`) update the
+// same file, so it is re-read under a file lock instead of writing back a
+// stale in-memory copy.
 func (s *gmailWatchStore) Update(fn func(*gmailWatchState) error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if err := fn(&s.state); err != nil {
+	return s.updateLocked(fn)
+}
+
+func (s *gmailWatchStore) updateLocked(fn func(*gmailWatchState) error) error {
+	if s.path == "" {
+		return errors.New("missing watch state path")
+	}
+	return withFileLock(s.path+".lock", func() error {
+		if err := s.reload(); err != nil {
+			return err
+		}
+		if err := fn(&s.state); err != nil {
+			return err
+		}
+		return s.Save()
+	})
+}
+
+// reload replaces the in-memory state with the file's. A missing file
+// (not saved yet, or removed by watch stop) keeps the in-memory state.
+// errGmailWatchStopped means the state file is gone: `watch stop` ran
+// while this process still held the state in memory.
+var errGmailWatchStopped = errors.New("gmail watch stopped (state file removed)")
+
+// UpdateExisting is Update for changes that must not outlive `watch stop`:
+// it returns errGmailWatchStopped instead of writing a removed state file
+// back. fn runs under the file lock, so it is serialized with `watch stop`.
+func (s *gmailWatchStore) UpdateExisting(fn func(*gmailWatchState) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return errors.New("missing watch state path")
+	}
+	return withFileLock(s.path+".lock", func() error {
+		if _, err := os.Stat(s.path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return errGmailWatchStopped
+			}
+			return err
+		}
+		if err := s.reload(); err != nil {
+			return err
+		}
+		if err := fn(&s.state); err != nil {
+			return err
+		}
+		return s.Save()
+	})
+}
+
+func (s *gmailWatchStore) reload() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
 		return err
 	}
-	return s.Save()
+	var state gmailWatchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	s.state = state
+	return nil
 }
 
 func (s *gmailWatchStore) Save() error {
@@ -103,7 +180,11 @@ func (s *gmailWatchStore) Save() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, append(payload, '\n'), 0o600)
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
 }
 
 func (s *gmailWatchStore) StartHistoryID(pushHistory string) (uint64, error) {
@@ -113,9 +194,13 @@ func (s *gmailWatchStore) StartHistoryID(pushHistory string) (uint64, error) {
 		if pushHistory == "" {
 			return 0, nil
 		}
-		s.state.HistoryID = pushHistory
-		s.state.UpdatedAtMs = time.Now().UnixMilli()
-		_ = s.Save()
+		_ = s.updateLocked(func(state *gmailWatchState) error {
+			if state.HistoryID == "" {
+				state.HistoryID = pushHistory
+				state.UpdatedAtMs = time.Now().UnixMilli()
+			}
+			return nil
+		})
 		return 0, nil
 	}
 	return parseHistoryID(s.state.HistoryID)
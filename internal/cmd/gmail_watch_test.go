@@ -25,6 +25,7 @@ func TestGmailWatchStartCmd_JSON(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	var watchReq struct {
 		TopicName string   `json:"topicName"`
@@ -128,6 +129,7 @@ func TestGmailWatchServerServeHTTP_TruncateBody(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	store, err := newGmailWatchStore("me@example.com")
 	if err != nil {
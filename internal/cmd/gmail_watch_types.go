@@ -17,46 +17,54 @@ const (
 )
 
 type gmailWatchHook struct {
-	URL         string `json:"url"`
+	URL         string `json:"url,omitempty"`
+	Exec        string `json:"exec,omitempty"` // command run with the payload on stdin (instead of URL)
 	Token       string `json:"token,omitempty"`
+	Secret      string `json:"secret,omitempty"` // HMAC key for X-Gog-Signature
 	IncludeBody bool   `json:"includeBody,omitempty"`
 	MaxBytes    int    `json:"maxBytes,omitempty"`
 }
 
 type gmailWatchState struct {
-	Account                string          `json:"account"`
-	Topic                  string          `json:"topic"`
-	Labels                 []string        `json:"labels,omitempty"`
-	HistoryID              string          `json:"historyId"`
-	ExpirationMs           int64           `json:"expirationMs,omitempty"`
-	ProviderExpirationMs   int64           `json:"providerExpirationMs,omitempty"`
-	RenewAfterMs           int64           `json:"renewAfterMs,omitempty"`
-	UpdatedAtMs            int64           `json:"updatedAtMs,omitempty"`
-	Hook                   *gmailWatchHook `json:"hook,omitempty"`
-	LastDeliveryStatus     string          `json:"lastDeliveryStatus,omitempty"`
-	LastDeliveryAtMs       int64           `json:"lastDeliveryAtMs,omitempty"`
-	LastDeliveryStatusNote string          `json:"lastDeliveryStatusNote,omitempty"`
+	Account                string           `json:"account"`
+	Topic                  string           `json:"topic"`
+	Labels                 []string         `json:"labels,omitempty"`
+	HistoryID              string           `json:"historyId"`
+	ExpirationMs           int64            `json:"expirationMs,omitempty"`
+	ProviderExpirationMs   int64            `json:"providerExpirationMs,omitempty"`
+	RenewAfterMs           int64            `json:"renewAfterMs,omitempty"`
+	UpdatedAtMs            int64            `json:"updatedAtMs,omitempty"`
+	Hook                   *gmailWatchHook  `json:"hook,omitempty"`
+	Rules                  []gmailWatchRule `json:"rules,omitempty"`
+	LastDeliveryStatus     string           `json:"lastDeliveryStatus,omitempty"`
+	LastDeliveryAtMs       int64            `json:"lastDeliveryAtMs,omitempty"`
+	LastDeliveryStatusNote string           `json:"lastDeliveryStatusNote,omitempty"`
 }
 
 type gmailWatchServeConfig struct {
-	Account       string
-	Bind          string
-	Port          int
-	Path          string
-	VerifyOIDC    bool
-	OIDCEmail     string
-	OIDCAudience  string
-	SharedToken   string
-	HookURL       string
-	HookToken     string
-	IncludeBody   bool
-	MaxBodyBytes  int
-	HistoryMax    int64
-	ResyncMax     int64
-	HookTimeout   time.Duration
-	PersistHook   bool
-	AllowNoHook   bool
-	VerboseOutput bool
+	Account         string
+	Bind            string
+	Port            int
+	Path            string
+	VerifyOIDC      bool
+	OIDCEmail       string
+	OIDCAudience    string
+	SharedToken     string
+	HookURL         string
+	HookToken       string
+	HookSecret      string
+	HookExec        string
+	HookMaxAttempts int
+	Rules           []gmailWatchRule
+	IncludeBody     bool
+	MaxBodyBytes    int
+	HistoryMax      int64
+	Labels          []string // label IDs to keep (watch poll); empty keeps all
+	ResyncMax       int64
+	HookTimeout     time.Duration
+	PersistHook     bool
+	AllowNoHook     bool
+	VerboseOutput   bool
 }
 
 type pubsubPushEnvelope struct {
@@ -113,6 +121,8 @@ type gmailHookMessage struct {
 	Body          string   `json:"body,omitempty"`
 	BodyTruncated bool     `json:"bodyTruncated,omitempty"`
 	Labels        []string `json:"labels,omitempty"`
+
+	hasAttachment bool // known only when the full message was fetched
 }
 
 type gmailHookPayload struct {
@@ -11,11 +11,41 @@ import (
 )
 
 func tableWriter(ctx context.Context) (io.Writer, func()) {
-	if outfmt.IsPlain(ctx) {
-		return os.Stdout, func() {}
+	var w io.Writer = os.Stdout
+	flush := func() {}
+	switch format := outfmt.TableFormat(ctx); format {
+	case outfmt.FormatTSV:
+	case outfmt.FormatCSV, outfmt.FormatMarkdown:
+		rw := outfmt.NewRowWriter(os.Stdout, format)
+		w, flush = rw, func() { _ = rw.Flush() }
+	default:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		w, flush = tw, func() { _ = tw.Flush() }
+	}
+
+	// --fields narrows tables/TSV to the matching columns.
+	fields := outfmt.ProjectionFromContext(ctx).Fields
+	if len(fields) == 0 {
+		return w, flush
+	}
+	cw := outfmt.NewColumnWriter(w, fields)
+	return cw, func() {
+		_ = cw.Flush()
+		flush()
+	}
+}
+
+// tableNotes is where text around a table (headings, summaries) goes:
+// stdout for table/TSV output, stderr for CSV/Markdown so stdout only holds
+// table rows.
+func tableNotes(ctx context.Context) *ui.Printer {
+	u := ui.FromContext(ctx)
+	switch outfmt.TableFormat(ctx) {
+	case outfmt.FormatCSV, outfmt.FormatMarkdown:
+		return u.Err()
+	default:
+		return u.Out()
 	}
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	return tw, func() { _ = tw.Flush() }
 }
 
 func printNextPageHint(u *ui.UI, nextPageToken string) {
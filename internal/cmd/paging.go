@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// PagingFlags are the shared auto-pagination flags for list commands.
+// Embed next to the command's own --max/--page flags.
+type PagingFlags struct {
+	All      bool  `name:"all" help:"Fetch all pages"`
+	MaxTotal int64 `name:"max-total" help:"Stop after this many results in total (implies --all)"`
+}
+
+func (f PagingFlags) options(page string, maxPerPage int64) pageOptions {
+	return pageOptions{Page: page, Max: maxPerPage, All: f.All, MaxTotal: f.MaxTotal}
+}
+
+// pageOptions controls how fetchPages walks a paginated endpoint.
+type pageOptions struct {
+	Page     string // first page token ("" = first page)
+	Max      int64  // page size (--max)
+	All      bool   // follow nextPageToken until exhausted
+	MaxTotal int64  // stop after this many items (implies All)
+}
+
+func (o pageOptions) walkAll() bool { return o.All || o.MaxTotal > 0 }
+
+// pageFetcher fetches the page identified by pageToken ("" = first page) and
+// returns its items plus the token of the following page.
+type pageFetcher[T any] func(ctx context.Context, pageToken string, pageSize int64) ([]T, string, error)
+
+// fetchPages fetches one page, or every remaining page with --all/--max-total,
+// handing each page to onPage as it arrives. It returns the token to resume
+// from: the next unfetched page, or the failing page when err != nil.
+func fetchPages[T any](ctx context.Context, opts pageOptions, fetch pageFetcher[T], onPage func([]T) error) (string, error) {
+	token := opts.Page
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return token, err
+		}
+
+		size := opts.Max
+		if opts.MaxTotal > 0 {
+			if remaining := opts.MaxTotal - total; size <= 0 || remaining < size {
+				size = remaining
+			}
+		}
+
+		items, next, err := fetch(ctx, token, size)
+		if err != nil {
+			return token, err
+		}
+		if opts.MaxTotal > 0 && int64(len(items)) > opts.MaxTotal-total {
+			items = items[:opts.MaxTotal-total]
+		}
+		total += int64(len(items))
+		if err := onPage(items); err != nil {
+			return next, err
+		}
+
+		// Guard against APIs echoing the same token forever.
+		if !opts.walkAll() || next == "" || next == token {
+			return next, nil
+		}
+		if opts.MaxTotal > 0 && total >= opts.MaxTotal {
+			return next, nil
+		}
+		token = next
+	}
+}
+
+// listOutput describes how a list command renders its items.
+type listOutput[T any] struct {
+	Key      string         // JSON envelope key, e.g. "files"
+	Meta     map[string]any // extra JSON envelope fields, e.g. {"fileId": ...}
+	CountKey string         // optional JSON envelope key for len(items)
+	Empty    string         // stderr message when there are no items
+	Header   string         // table header row (tab-separated)
+	Row      func(T) string // table row (tab-separated, no newline)
+}
+
+var errInterrupted = &ExitError{Code: 130, Err: errors.New("interrupted")}
+
+// writeList fetches pages and writes them in the active output mode:
+//
+//   - --ndjson: one JSON object per item, streamed page by page
+//   - --json: {"<key>": [...], "nextPageToken": "..."}
+//   - default/--plain: table rows + next page hint on stderr
+//
+// Ctrl-C while paging stops fetching and still writes what was collected;
+// nextPageToken then points at the page to resume from.
+func writeList[T any](ctx context.Context, opts pageOptions, out listOutput[T], fetch pageFetcher[T]) error {
+	u := ui.FromContext(ctx)
+
+	fetchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	if outfmt.IsNDJSON(ctx) {
+		next, err := fetchPages(fetchCtx, opts, fetch, func(items []T) error {
+			for _, it := range items {
+				if err := outfmt.WriteJSON(ctx, os.Stdout, it); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		printNextPageHint(u, next)
+		return interruptedOr(fetchCtx, err)
+	}
+
+	items := make([]T, 0)
+	next, err := fetchPages(fetchCtx, opts, fetch, func(page []T) error {
+		items = append(items, page...)
+		return nil
+	})
+	if err != nil && (fetchCtx.Err() == nil || len(items) == 0) {
+		return interruptedOr(fetchCtx, err)
+	}
+	if err != nil && u != nil {
+		u.Err().Printf("Interrupted; writing %d partial results", len(items))
+	}
+
+	if outfmt.IsJSON(ctx) {
+		envelope := map[string]any{
+			out.Key:         items,
+			"nextPageToken": next,
+		}
+		for k, v := range out.Meta {
+			envelope[k] = v
+		}
+		if out.CountKey != "" {
+			envelope[out.CountKey] = len(items)
+		}
+		if writeErr := outfmt.WriteJSON(ctx, os.Stdout, envelope); writeErr != nil {
+			return writeErr
+		}
+		return interruptedOr(fetchCtx, err)
+	}
+
+	if len(items) == 0 {
+		u.Err().Println(out.Empty)
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, out.Header)
+	for _, it := range items {
+		fmt.Fprintln(w, out.Row(it))
+	}
+	flush()
+	printNextPageHint(u, next)
+	return interruptedOr(fetchCtx, err)
+}
+
+// interruptedOr maps a cancelled paging context to errInterrupted (exit 130).
+func interruptedOr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return errInterrupted
+	}
+	return err
+}
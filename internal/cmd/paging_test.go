@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func fakePages(pages map[string][]int, next map[string]string, calls *[]string) pageFetcher[int] {
+	return func(_ context.Context, token string, _ int64) ([]int, string, error) {
+		*calls = append(*calls, token)
+		items, ok := pages[token]
+		if !ok {
+			return nil, "", errors.New("unknown page " + token)
+		}
+		return items, next[token], nil
+	}
+}
+
+func TestFetchPages_SinglePage(t *testing.T) {
+	var calls []string
+	fetch := fakePages(map[string][]int{"": {1, 2}, "p2": {3}}, map[string]string{"": "p2"}, &calls)
+
+	var got []int
+	next, err := fetchPages(context.Background(), pageOptions{}, fetch, func(items []int) error {
+		got = append(got, items...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fetchPages: %v", err)
+	}
+	if next != "p2" || !reflect.DeepEqual(got, []int{1, 2}) || len(calls) != 1 {
+		t.Fatalf("unexpected next=%q got=%v calls=%v", next, got, calls)
+	}
+}
+
+func TestFetchPages_All(t *testing.T) {
+	var calls []string
+	fetch := fakePages(
+		map[string][]int{"p1": {1}, "p2": {2}, "p3": {3}},
+		map[string]string{"p1": "p2", "p2": "p3"},
+		&calls,
+	)
+
+	var got []int
+	next, err := fetchPages(context.Background(), pageOptions{Page: "p1", All: true}, fetch, func(items []int) error {
+		got = append(got, items...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fetchPages: %v", err)
+	}
+	if next != "" || !reflect.DeepEqual(got, []int{1, 2, 3}) || !reflect.DeepEqual(calls, []string{"p1", "p2", "p3"}) {
+		t.Fatalf("unexpected next=%q got=%v calls=%v", next, got, calls)
+	}
+}
+
+func TestFetchPages_RepeatedTokenStops(t *testing.T) {
+	var calls []string
+	fetch := fakePages(map[string][]int{"p1": {1}}, map[string]string{"p1": "p1"}, &calls)
+
+	if _, err := fetchPages(context.Background(), pageOptions{Page: "p1", All: true}, fetch, func([]int) error { return nil }); err != nil {
+		t.Fatalf("fetchPages: %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected a single call, got %v", calls)
+	}
+}
+
+func TestFetchPages_Error(t *testing.T) {
+	var calls []string
+	fetch := fakePages(map[string][]int{"": {1}}, map[string]string{"": "missing"}, &calls)
+
+	next, err := fetchPages(context.Background(), pageOptions{All: true}, fetch, func([]int) error { return nil })
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if next != "missing" {
+		t.Fatalf("expected failing page token, got %q", next)
+	}
+}
+
+func TestFetchPages_MaxTotal(t *testing.T) {
+	var sizes []int64
+	fetch := func(_ context.Context, token string, pageSize int64) ([]int, string, error) {
+		sizes = append(sizes, pageSize)
+		switch token {
+		case "":
+			return []int{1, 2, 3}, "p2", nil
+		case "p2":
+			// Ignores the smaller page size on purpose.
+			return []int{4, 5, 6}, "p3", nil
+		default:
+			return nil, "", errors.New("unexpected page " + token)
+		}
+	}
+
+	var got []int
+	next, err := fetchPages(context.Background(), pageOptions{Max: 3, MaxTotal: 5}, fetch, func(items []int) error {
+		got = append(got, items...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fetchPages: %v", err)
+	}
+	if next != "p3" || !reflect.DeepEqual(got, []int{1, 2, 3, 4, 5}) || !reflect.DeepEqual(sizes, []int64{3, 2}) {
+		t.Fatalf("unexpected next=%q got=%v sizes=%v", next, got, sizes)
+	}
+}
+
+func TestWriteList_InterruptedWritesPartial(t *testing.T) {
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true}))
+	defer cancel()
+
+	fetch := func(ctx context.Context, token string, _ int64) ([]int, string, error) {
+		if token == "" {
+			return []int{1, 2}, "p2", nil
+		}
+		cancel()
+		return nil, "", ctx.Err()
+	}
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			err = writeList(ctx, pageOptions{All: true}, listOutput[int]{Key: "items"}, fetch)
+		})
+	})
+	if ExitCode(err) != 130 {
+		t.Fatalf("expected exit 130, got %v", err)
+	}
+
+	var parsed struct {
+		Items         []int  `json:"items"`
+		NextPageToken string `json:"nextPageToken"`
+	}
+	if jsonErr := json.Unmarshal([]byte(out), &parsed); jsonErr != nil {
+		t.Fatalf("json parse: %v\nout=%q", jsonErr, out)
+	}
+	if !reflect.DeepEqual(parsed.Items, []int{1, 2}) || parsed.NextPageToken != "p2" {
+		t.Fatalf("unexpected payload: %#v", parsed)
+	}
+}
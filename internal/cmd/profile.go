@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+// profileFromArgs finds --profile before kong parses args, because profile
+// values become kong Vars (flag defaults). Falls back to GOG_PROFILE.
+func profileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(arg, "--profile="); ok {
+			return strings.TrimSpace(v)
+		}
+		if arg == "--profile" && i+1 < len(args) {
+			return strings.TrimSpace(args[i+1])
+		}
+	}
+	return strings.TrimSpace(os.Getenv("GOG_PROFILE"))
+}
+
+// loadProfile reads the named profile from config.json ("" = no profile).
+func loadProfile(name string) (config.Profile, error) {
+	if name == "" {
+		return config.Profile{}, nil
+	}
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return config.Profile{}, err
+	}
+	return cfg.Profile(name)
+}
+
+// profileVars are the kong Vars fed by a profile; command flags/args use
+// them as defaults (e.g. `default:"${drive_parent}"`). Env vars still win.
+func profileVars(p config.Profile) kong.Vars {
+	return kong.Vars{
+		"account":          envOr("GOG_ACCOUNT", p.Account),
+		"timezone":         p.Timezone,
+		"calendar":         orEmpty(p.CalendarID, "primary"),
+		"default_calendar": p.CalendarID,
+		"drive_parent":     p.DriveParent,
+		"tasklist":         p.TaskList,
+	}
+}
+
+// profileOutput maps a profile "output" value onto the --json/--plain/
+// --ndjson/--output flags. Only used when none of those were given.
+func profileOutput(output string) (jsonOut, plainOut, ndjsonOut bool, format string) {
+	switch strings.ToLower(strings.TrimSpace(output)) {
+	case "json":
+		return true, false, false, ""
+	case "ndjson":
+		return false, false, true, ""
+	case "plain", "tsv":
+		return false, true, false, ""
+	default:
+		return false, false, false, output
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+// retryConfig resolves API retry/timeout/circuit breaker settings:
+// flags > env (GOG_RETRIES, GOG_HTTP_TIMEOUT) > config.json > defaults.
+func retryConfig(flags *RootFlags, cfg config.File) (googleapi.RetryConfig, error) {
+	rc := googleapi.DefaultRetryConfig()
+
+	if cfg.Retries != nil {
+		rc = rc.WithRetries(*cfg.Retries)
+	}
+	if v := strings.TrimSpace(os.Getenv("GOG_RETRIES")); v != "" {
+		n, err := config.ParseRetries(v)
+		if err != nil {
+			return rc, usagef("GOG_RETRIES: %v", err)
+		}
+		rc = rc.WithRetries(n)
+	}
+	if flags.Retries != nil {
+		if *flags.Retries < 0 {
+			return rc, usage("--retries must be >= 0")
+		}
+		rc = rc.WithRetries(*flags.Retries)
+	}
+
+	if cfg.HTTPTimeout != "" {
+		d, err := config.ParsePositiveDuration("http_timeout", cfg.HTTPTimeout)
+		if err != nil {
+			return rc, usage(err.Error())
+		}
+		rc.Timeout = d
+	}
+	if v := strings.TrimSpace(os.Getenv("GOG_HTTP_TIMEOUT")); v != "" {
+		d, err := config.ParsePositiveDuration("GOG_HTTP_TIMEOUT", v)
+		if err != nil {
+			return rc, usage(err.Error())
+		}
+		rc.Timeout = d
+	}
+	if flags.HTTPTimeout != nil {
+		if *flags.HTTPTimeout <= 0 {
+			return rc, usage("--http-timeout must be > 0")
+		}
+		rc.Timeout = *flags.HTTPTimeout
+	}
+
+	if cfg.CircuitBreakerThreshold > 0 {
+		rc.BreakerThreshold = cfg.CircuitBreakerThreshold
+	}
+	if cfg.CircuitBreakerReset != "" {
+		d, err := config.ParsePositiveDuration("circuit_breaker_reset", cfg.CircuitBreakerReset)
+		if err != nil {
+			return rc, usage(err.Error())
+		}
+		rc.BreakerResetTime = d
+	}
+	if dir, err := config.CircuitBreakerStateDir(); err == nil {
+		rc.BreakerStateDir = dir
+	}
+
+	return rc, nil
+}
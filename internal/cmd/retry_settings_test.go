@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+func TestRetryConfig_Precedence(t *testing.T) {
+	t.Setenv("GOG_RETRIES", "")
+	t.Setenv("GOG_HTTP_TIMEOUT", "")
+
+	five := 5
+	cfg := config.File{Retries: &five, HTTPTimeout: "10s", CircuitBreakerThreshold: 2, CircuitBreakerReset: "1m"}
+
+	rc, err := retryConfig(&RootFlags{}, config.File{})
+	if err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+	def := googleapi.DefaultRetryConfig()
+	if rc.MaxRetries429 != def.MaxRetries429 || rc.MaxRetries5xx != def.MaxRetries5xx || rc.Timeout != def.Timeout {
+		t.Fatalf("expected defaults, got %#v", rc)
+	}
+
+	rc, err = retryConfig(&RootFlags{}, cfg)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if rc.MaxRetries5xx != 5 || rc.MaxRetriesNetwork != 5 || rc.Timeout != 10*time.Second || rc.BreakerThreshold != 2 || rc.BreakerResetTime != time.Minute {
+		t.Fatalf("expected config values, got %#v", rc)
+	}
+
+	t.Setenv("GOG_RETRIES", "1")
+	t.Setenv("GOG_HTTP_TIMEOUT", "20s")
+	rc, _ = retryConfig(&RootFlags{}, cfg)
+	if rc.MaxRetries429 != 1 || rc.Timeout != 20*time.Second {
+		t.Fatalf("expected env to win over config, got %#v", rc)
+	}
+
+	zero := 0
+	timeout := 5 * time.Second
+	rc, _ = retryConfig(&RootFlags{Retries: &zero, HTTPTimeout: &timeout}, cfg)
+	if rc.MaxRetries429 != 0 || rc.MaxRetriesNetwork != 0 || rc.Timeout != timeout {
+		t.Fatalf("expected flags to win, got %#v", rc)
+	}
+
+	t.Setenv("GOG_RETRIES", "many")
+	if _, err := retryConfig(&RootFlags{}, cfg); err == nil {
+		t.Fatalf("expected error for invalid GOG_RETRIES")
+	}
+}
+
+func TestExecute_ConfigSetRetries(t *testing.T) {
+	withTempConfigHome(t)
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"config", "set", "retries", "4"}); err != nil {
+			t.Fatalf("set retries: %v", err)
+		}
+		if err := Execute([]string{"config", "set", "http-timeout", "45s"}); err != nil {
+			t.Fatalf("set http_timeout: %v", err)
+		}
+	})
+	_ = captureStderr(t, func() {
+		if err := Execute([]string{"config", "set", "http_timeout", "soon"}); err == nil {
+			t.Fatalf("expected invalid duration error")
+		}
+	})
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if cfg.Retries == nil || *cfg.Retries != 4 || cfg.HTTPTimeout != "45s" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+}
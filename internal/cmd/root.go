@@ -6,24 +6,39 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 
 	"github.com/steipete/gogcli/internal/config"
 	"github.com/steipete/gogcli/internal/errfmt"
+	"github.com/steipete/gogcli/internal/googleapi"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/secrets"
 	"github.com/steipete/gogcli/internal/ui"
 )
 
 type RootFlags struct {
-	Color   string `help:"Color output: auto|always|never" default:"${color}"`
-	Account string `help:"Account email for API commands (gmail/calendar/drive/docs/slides/contacts/tasks/people/sheets)"`
-	JSON    bool   `help:"Output JSON to stdout (best for scripting)" default:"${json}"`
-	Plain   bool   `help:"Output stable, parseable text to stdout (TSV; no colors)" default:"${plain}"`
-	Force   bool   `help:"Skip confirmations for destructive commands"`
-	NoInput bool   `help:"Never prompt; fail instead (useful for CI)"`
-	Verbose bool   `help:"Enable verbose logging"`
+	Color       string         `help:"Color output: auto|always|never" default:"${color}"`
+	Account     string         `help:"Account email or alias for API commands (gmail/calendar/drive/docs/slides/contacts/tasks/people/sheets)" default:"${account}"`
+	Profile     string         `help:"Config profile to use (overrides GOG_PROFILE)" default:"${profile}"`
+	Accounts    string         `name:"accounts" help:"Comma-separated accounts/aliases to query at once (gmail search, calendar events, tasks list, drive search, gmail watch serve)"`
+	AllAccounts bool           `name:"all-accounts" help:"Query every stored account at once (same commands as --accounts)"`
+	JSON        bool           `help:"Output JSON to stdout (best for scripting)" default:"${json}"`
+	Plain       bool           `help:"Output stable, parseable text to stdout (TSV; no colors)" default:"${plain}"`
+	NDJSON      bool           `name:"ndjson" help:"Output newline-delimited JSON (one object per line; list results stream)" default:"${ndjson}"`
+	Output      string         `name:"output" help:"Output format: table|tsv|csv|markdown|json (superset of --json/--plain)"`
+	Fields      string         `help:"Comma-separated fields to output (JSON keys or table columns, e.g. id,name)"`
+	JQ          string         `name:"jq" help:"Filter JSON output with a jq expression (implies --json)"`
+	Force       bool           `help:"Skip confirmations for destructive commands"`
+	NoInput     bool           `help:"Never prompt; fail instead (useful for CI)"`
+	UseCache    bool           `name:"cache" negatable:"" help:"Serve rarely-changing metadata from the local API response cache (GOG_CACHE)" default:"${cache}"`
+	Retries     *int           `name:"retries" help:"Max retries per API request for 429/5xx/network errors (GOG_RETRIES; default: 3 for 429, 1 for 5xx, 2 for network)"`
+	HTTPTimeout *time.Duration `name:"http-timeout" help:"Timeout per API request, retries included (GOG_HTTP_TIMEOUT; default: 30s)"`
+	Verbose     bool           `help:"Enable verbose logging"`
+
+	profile config.Profile // resolved --profile/GOG_PROFILE (not a flag)
 }
 
 type CLI struct {
@@ -32,6 +47,8 @@ type CLI struct {
 	Version kong.VersionFlag `help:"Print version and exit"`
 
 	Auth       AuthCmd       `cmd:"" help:"Auth and credentials"`
+	Cache      CacheCmd      `cmd:"" help:"Local API response cache"`
+	Config     ConfigCmd     `cmd:"" help:"Show and edit config.json (profiles)"`
 	Drive      DriveCmd      `cmd:"" help:"Google Drive"`
 	Docs       DocsCmd       `cmd:"" help:"Google Docs (export via Drive)"`
 	Slides     SlidesCmd     `cmd:"" help:"Google Slides"`
@@ -49,12 +66,17 @@ type exitPanic struct{ code int }
 
 func Execute(args []string) (err error) {
 	envMode := outfmt.FromEnv()
+	profileName := profileFromArgs(args)
+	profile, profileErr := loadProfile(profileName)
 	vars := kong.Vars{
-		"color":   envOr("GOG_COLOR", "auto"),
+		"color":   envOr("GOG_COLOR", orEmpty(profile.Color, "auto")),
 		"json":    boolString(envMode.JSON),
 		"plain":   boolString(envMode.Plain),
+		"ndjson":  boolString(envMode.NDJSON),
+		"cache":   boolString(envBool("GOG_CACHE")),
+		"profile": profileName,
 		"version": VersionString(),
-	}
+	}.CloneWith(profileVars(profile))
 
 	cli := &CLI{}
 	parser, err := kong.New(
@@ -86,7 +108,7 @@ func Execute(args []string) (err error) {
 	kctx, err := parser.Parse(args)
 	if err != nil {
 		parsedErr := wrapParseError(err)
-		_, _ = fmt.Fprintln(os.Stderr, errfmt.Format(parsedErr))
+		printError(jsonRequested(args, envMode), parsedErr)
 		return parsedErr
 	}
 
@@ -98,13 +120,62 @@ func Execute(args []string) (err error) {
 		Level: logLevel,
 	})))
 
-	mode, err := outfmt.FromFlags(cli.JSON, cli.Plain)
+	// config commands must work with a missing/broken profile (to fix it).
+	isConfigCmd := strings.HasPrefix(kctx.Command(), "config")
+	jsonErrors := jsonRequested(args, envMode)
+	if profileErr != nil && !isConfigCmd {
+		usageErr := newUsageError(profileErr)
+		printError(jsonErrors, usageErr)
+		return usageErr
+	}
+	cli.profile = profile
+
+	cfgFile, cfgErr := config.ReadConfig()
+	if cfgErr != nil && !isConfigCmd {
+		usageErr := newUsageError(cfgErr)
+		printError(jsonErrors, usageErr)
+		return usageErr
+	}
+	retryCfg, err := retryConfig(&cli.RootFlags, cfgFile)
+	if err != nil && !isConfigCmd {
+		printError(jsonErrors, err)
+		return err
+	}
+
+	jsonOut, plainOut, ndjsonOut, output := cli.JSON, cli.Plain, cli.NDJSON, cli.Output
+	if !jsonOut && !plainOut && !ndjsonOut && output == "" && cli.JQ == "" {
+		jsonOut, plainOut, ndjsonOut, output = profileOutput(profile.Output)
+	}
+	mode, err := outfmt.FromFlags(jsonOut, plainOut, ndjsonOut, output)
+	if err != nil {
+		usageErr := newUsageError(err)
+		printError(jsonErrors, usageErr)
+		return usageErr
+	}
+
+	proj, err := outfmt.ParseProjection(cli.Fields, cli.JQ)
 	if err != nil {
-		return newUsageError(err)
+		usageErr := newUsageError(err)
+		printError(jsonErrors, usageErr)
+		return usageErr
+	}
+	if proj.Query != nil {
+		if mode.Plain {
+			usageErr := usagef("invalid output mode (cannot combine --jq and --output %s)", mode.Format)
+			if cli.Plain {
+				usageErr = usage("invalid output mode (cannot combine --jq and --plain)")
+			}
+			printError(jsonErrors, usageErr)
+			return usageErr
+		}
+		mode.JSON = true
 	}
 
 	ctx := context.Background()
 	ctx = outfmt.WithMode(ctx, mode)
+	ctx = outfmt.WithProjection(ctx, proj)
+	ctx = googleapi.WithCache(ctx, cli.UseCache)
+	ctx = googleapi.WithRetryConfig(ctx, retryCfg)
 
 	uiColor := cli.Color
 	if outfmt.IsJSON(ctx) || outfmt.IsPlain(ctx) {
@@ -129,6 +200,10 @@ func Execute(args []string) (err error) {
 		return nil
 	}
 
+	if outfmt.IsJSON(ctx) || outfmt.IsNDJSON(ctx) {
+		writeJSONError(os.Stderr, err)
+		return err
+	}
 	if u := ui.FromContext(ctx); u != nil {
 		u.Err().Error(errfmt.Format(err))
 		return err
@@ -137,6 +212,15 @@ func Execute(args []string) (err error) {
 	return err
 }
 
+// printError reports errors raised before the output mode is set up.
+func printError(jsonErrors bool, err error) {
+	if jsonErrors {
+		writeJSONError(os.Stderr, err)
+		return
+	}
+	_, _ = fmt.Fprintln(os.Stderr, errfmt.Format(err))
+}
+
 func wrapParseError(err error) error {
 	if err == nil {
 		return nil
@@ -155,6 +239,15 @@ func envOr(key, fallback string) string {
 	return fallback
 }
 
+func envBool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	default:
+		return false
+	}
+}
+
 func boolString(v bool) string {
 	if v {
 		return "true"
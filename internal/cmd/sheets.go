@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"google.golang.org/api/sheets/v4"
 
@@ -106,7 +105,7 @@ func (c *SheetsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"range":  resp.Range,
 			"values": resp.Values,
 		})
@@ -117,15 +116,15 @@ func (c *SheetsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return nil
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	w, flush := tableWriter(ctx)
 	for _, row := range resp.Values {
 		cells := make([]string, len(row))
 		for i, cell := range row {
 			cells[i] = fmt.Sprintf("%v", cell)
 		}
-		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
 	}
-	_ = tw.Flush()
+	flush()
 	return nil
 }
 
@@ -198,7 +197,7 @@ func (c *SheetsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"updatedRange":   resp.UpdatedRange,
 			"updatedRows":    resp.UpdatedRows,
 			"updatedColumns": resp.UpdatedColumns,
@@ -282,7 +281,7 @@ func (c *SheetsAppendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"updatedRange":   resp.Updates.UpdatedRange,
 			"updatedRows":    resp.Updates.UpdatedRows,
 			"updatedColumns": resp.Updates.UpdatedColumns,
@@ -326,7 +325,7 @@ func (c *SheetsClearCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"clearedRange": resp.ClearedRange,
 		})
 	}
@@ -340,7 +339,6 @@ type SheetsMetadataCmd struct {
 }
 
 func (c *SheetsMetadataCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -362,7 +360,7 @@ func (c *SheetsMetadataCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"spreadsheetId": resp.SpreadsheetId,
 			"title":         resp.Properties.Title,
 			"locale":        resp.Properties.Locale,
@@ -371,26 +369,27 @@ func (c *SheetsMetadataCmd) Run(ctx context.Context, flags *RootFlags) error {
 		})
 	}
 
-	u.Out().Printf("ID\t%s", resp.SpreadsheetId)
-	u.Out().Printf("Title\t%s", resp.Properties.Title)
-	u.Out().Printf("Locale\t%s", resp.Properties.Locale)
-	u.Out().Printf("TimeZone\t%s", resp.Properties.TimeZone)
-	u.Out().Printf("URL\t%s", resp.SpreadsheetUrl)
-	u.Out().Println("")
-	u.Out().Println("Sheets:")
+	notes := tableNotes(ctx)
+	notes.Printf("ID\t%s", resp.SpreadsheetId)
+	notes.Printf("Title\t%s", resp.Properties.Title)
+	notes.Printf("Locale\t%s", resp.Properties.Locale)
+	notes.Printf("TimeZone\t%s", resp.Properties.TimeZone)
+	notes.Printf("URL\t%s", resp.SpreadsheetUrl)
+	notes.Println("")
+	notes.Println("Sheets:")
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(tw, "ID\tTITLE\tROWS\tCOLS")
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tTITLE\tROWS\tCOLS")
 	for _, sheet := range resp.Sheets {
 		props := sheet.Properties
-		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\n",
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n",
 			props.SheetId,
 			props.Title,
 			props.GridProperties.RowCount,
 			props.GridProperties.ColumnCount,
 		)
 	}
-	_ = tw.Flush()
 	return nil
 }
 
@@ -440,7 +439,7 @@ func (c *SheetsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"spreadsheetId":  resp.SpreadsheetId,
 			"title":          resp.Properties.Title,
 			"spreadsheetUrl": resp.SpreadsheetUrl,
@@ -19,8 +19,8 @@ const (
 )
 
 type TasksListCmd struct {
-	TasklistID    string `arg:"" name:"tasklistId" help:"Task list ID"`
-	Max           int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 100)" default:"20"`
+	TasklistID    string `arg:"" name:"tasklistId" help:"Task list ID (default: profile tasklist)" default:"${tasklist}"`
+	Max           int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 100; per page with --all)" default:"20"`
 	Page          string `name:"page" help:"Page token"`
 	ShowCompleted bool   `name:"show-completed" help:"Include completed tasks (requires --show-hidden for some clients)" default:"true"`
 	ShowDeleted   bool   `name:"show-deleted" help:"Include deleted tasks"`
@@ -31,80 +31,67 @@ type TasksListCmd struct {
 	CompletedMin  string `name:"completed-min" help:"Lower bound for completion date filter (RFC3339)"`
 	CompletedMax  string `name:"completed-max" help:"Upper bound for completion date filter (RFC3339)"`
 	UpdatedMin    string `name:"updated-min" help:"Lower bound for updated time filter (RFC3339)"`
+	PagingFlags
 }
 
 func (c *TasksListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
-	if err != nil {
-		return err
-	}
 	tasklistID := strings.TrimSpace(c.TasklistID)
 	if tasklistID == "" {
 		return usage("empty tasklistId")
 	}
 
-	svc, err := newTasksService(ctx, account)
-	if err != nil {
-		return err
-	}
-
-	call := svc.Tasks.List(tasklistID).
-		MaxResults(c.Max).
-		PageToken(c.Page).
-		ShowCompleted(c.ShowCompleted).
-		ShowDeleted(c.ShowDeleted).
-		ShowHidden(c.ShowHidden).
-		ShowAssigned(c.ShowAssigned)
-	if strings.TrimSpace(c.DueMin) != "" {
-		call = call.DueMin(strings.TrimSpace(c.DueMin))
-	}
-	if strings.TrimSpace(c.DueMax) != "" {
-		call = call.DueMax(strings.TrimSpace(c.DueMax))
-	}
-	if strings.TrimSpace(c.CompletedMin) != "" {
-		call = call.CompletedMin(strings.TrimSpace(c.CompletedMin))
-	}
-	if strings.TrimSpace(c.CompletedMax) != "" {
-		call = call.CompletedMax(strings.TrimSpace(c.CompletedMax))
-	}
-	if strings.TrimSpace(c.UpdatedMin) != "" {
-		call = call.UpdatedMin(strings.TrimSpace(c.UpdatedMin))
-	}
-
-	resp, err := call.Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"tasks":         resp.Items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Items) == 0 {
-		u.Err().Println("No tasks")
-		return nil
-	}
+	newFetch := func(ctx context.Context, account string) (pageFetcher[*tasks.Task], error) {
+		svc, err := newTasksService(ctx, account)
+		if err != nil {
+			return nil, err
+		}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tDUE\tUPDATED")
-	for _, t := range resp.Items {
-		status := strings.TrimSpace(t.Status)
-		if status == "" {
-			status = taskStatusNeedsAction
+		call := svc.Tasks.List(tasklistID).
+			ShowCompleted(c.ShowCompleted).
+			ShowDeleted(c.ShowDeleted).
+			ShowHidden(c.ShowHidden).
+			ShowAssigned(c.ShowAssigned)
+		if strings.TrimSpace(c.DueMin) != "" {
+			call = call.DueMin(strings.TrimSpace(c.DueMin))
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Id, t.Title, status, strings.TrimSpace(t.Due), strings.TrimSpace(t.Updated))
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+		if strings.TrimSpace(c.DueMax) != "" {
+			call = call.DueMax(strings.TrimSpace(c.DueMax))
+		}
+		if strings.TrimSpace(c.CompletedMin) != "" {
+			call = call.CompletedMin(strings.TrimSpace(c.CompletedMin))
+		}
+		if strings.TrimSpace(c.CompletedMax) != "" {
+			call = call.CompletedMax(strings.TrimSpace(c.CompletedMax))
+		}
+		if strings.TrimSpace(c.UpdatedMin) != "" {
+			call = call.UpdatedMin(strings.TrimSpace(c.UpdatedMin))
+		}
+
+		return func(ctx context.Context, pageToken string, pageSize int64) ([]*tasks.Task, string, error) {
+			resp, err := call.MaxResults(pageSize).PageToken(pageToken).Context(ctx).Do()
+			if err != nil {
+				return nil, "", err
+			}
+			return resp.Items, resp.NextPageToken, nil
+		}, nil
+	}
+
+	return runList(ctx, flags, c.options(c.Page, c.Max), listOutput[*tasks.Task]{
+		Key:    "tasks",
+		Empty:  "No tasks",
+		Header: "ID\tTITLE\tSTATUS\tDUE\tUPDATED",
+		Row: func(t *tasks.Task) string {
+			status := strings.TrimSpace(t.Status)
+			if status == "" {
+				status = taskStatusNeedsAction
+			}
+			return fmt.Sprintf("%s\t%s\t%s\t%s\t%s", t.Id, t.Title, status, strings.TrimSpace(t.Due), strings.TrimSpace(t.Updated))
+		},
+	}, newFetch)
 }
 
 type TasksAddCmd struct {
-	TasklistID string `arg:"" name:"tasklistId" help:"Task list ID"`
+	TasklistID string `arg:"" name:"tasklistId" help:"Task list ID (default: profile tasklist)" default:"${tasklist}"`
 	Title      string `name:"title" help:"Task title (required)"`
 	Notes      string `name:"notes" help:"Task notes/description"`
 	Due        string `name:"due" help:"Due date/time (RFC3339)"`
@@ -149,7 +136,7 @@ func (c *TasksAddCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"task": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"task": created})
 	}
 	u.Out().Printf("id\t%s", created.Id)
 	u.Out().Printf("title\t%s", created.Title)
@@ -226,7 +213,7 @@ func (c *TasksUpdateCmd) Run(ctx context.Context, kctx *kong.Context, flags *Roo
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"task": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"task": updated})
 	}
 	u.Out().Printf("id\t%s", updated.Id)
 	u.Out().Printf("title\t%s", updated.Title)
@@ -272,7 +259,7 @@ func (c *TasksDoneCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"task": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"task": updated})
 	}
 	u.Out().Printf("id\t%s", updated.Id)
 	u.Out().Printf("status\t%s", strings.TrimSpace(updated.Status))
@@ -309,7 +296,7 @@ func (c *TasksUndoCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"task": updated})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"task": updated})
 	}
 	u.Out().Printf("id\t%s", updated.Id)
 	u.Out().Printf("status\t%s", strings.TrimSpace(updated.Status))
@@ -349,7 +336,7 @@ func (c *TasksDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"deleted": true,
 			"id":      taskID,
 		})
@@ -387,7 +374,7 @@ func (c *TasksClearCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"cleared":    true,
 			"tasklistId": tasklistID,
 		})
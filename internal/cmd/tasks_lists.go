@@ -18,12 +18,12 @@ type TasksListsCmd struct {
 }
 
 type TasksListsListCmd struct {
-	Max  int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 1000)" default:"100"`
+	Max  int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 1000; per page with --all)" default:"100"`
 	Page string `name:"page" help:"Page token"`
+	PagingFlags
 }
 
 func (c *TasksListsListCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -34,32 +34,22 @@ func (c *TasksListsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	call := svc.Tasklists.List().MaxResults(c.Max).PageToken(c.Page)
-	resp, err := call.Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{
-			"tasklists":     resp.Items,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Items) == 0 {
-		u.Err().Println("No task lists")
-		return nil
+	fetch := func(ctx context.Context, pageToken string, pageSize int64) ([]*tasks.TaskList, string, error) {
+		resp, err := svc.Tasklists.List().MaxResults(pageSize).PageToken(pageToken).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
 	}
 
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tTITLE")
-	for _, tl := range resp.Items {
-		fmt.Fprintf(w, "%s\t%s\n", tl.Id, tl.Title)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	return writeList(ctx, c.options(c.Page, c.Max), listOutput[*tasks.TaskList]{
+		Key:    "tasklists",
+		Empty:  "No task lists",
+		Header: "ID\tTITLE",
+		Row: func(tl *tasks.TaskList) string {
+			return fmt.Sprintf("%s\t%s", tl.Id, tl.Title)
+		},
+	}, fetch)
 }
 
 type TasksListsCreateCmd struct {
@@ -88,7 +78,7 @@ func (c *TasksListsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(os.Stdout, map[string]any{"tasklist": created})
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"tasklist": created})
 	}
 	u.Out().Printf("id\t%s", created.Id)
 	u.Out().Printf("title\t%s", created.Title)
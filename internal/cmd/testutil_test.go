@@ -4,12 +4,58 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/alecthomas/kong"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
 )
 
+// testUIContext returns a context with a colorless UI writing stderr to
+// stderr; stdout goes to io.Discard (use captureStdout for command output).
+func testUIContext(t *testing.T, stderr io.Writer) context.Context {
+	t.Helper()
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: stderr, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	return ui.WithUI(context.Background(), u)
+}
+
+// testJSONContext is testUIContext in JSON output mode.
+func testJSONContext(t *testing.T, stderr io.Writer) context.Context {
+	t.Helper()
+	return outfmt.WithMode(testUIContext(t, stderr), outfmt.Mode{JSON: true})
+}
+
+// stubGmailService serves handler as the Gmail API and points
+// newGmailService at it for the rest of the test.
+func stubGmailService(t *testing.T, handler http.Handler) *gmail.Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	orig := newGmailService
+	t.Cleanup(func() { newGmailService = orig })
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+	return svc
+}
+
 func captureStdout(t *testing.T, fn func()) string {
 	t.Helper()
 
@@ -72,6 +118,7 @@ func runKong(t *testing.T, cmd any, args []string, ctx context.Context, flags *R
 
 	parser, err := kong.New(
 		cmd,
+		kong.Vars(profileVars(config.Profile{})),
 		kong.Writers(io.Discard, io.Discard),
 		kong.Exit(func(code int) { panic(exitPanic{code: code}) }),
 	)
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormalizeAlias lowercases and trims an account alias.
+func NormalizeAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+// ValidateAlias rejects aliases that could be mistaken for an email.
+func ValidateAlias(alias string) error {
+	alias = NormalizeAlias(alias)
+	if alias == "" {
+		return fmt.Errorf("empty alias")
+	}
+	if strings.Contains(alias, "@") {
+		return fmt.Errorf("invalid alias %q (must not contain @)", alias)
+	}
+	if strings.ContainsAny(alias, " \t\r\n") {
+		return fmt.Errorf("invalid alias %q (must not contain whitespace)", alias)
+	}
+	return nil
+}
+
+// AccountAlias returns the email an alias points to.
+func (f File) AccountAlias(alias string) (string, bool) {
+	email, ok := f.AccountAliases[NormalizeAlias(alias)]
+	return email, ok
+}
+
+// AliasNames returns the configured aliases, sorted.
+func (f File) AliasNames() []string {
+	names := make([]string, 0, len(f.AccountAliases))
+	for name := range f.AccountAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
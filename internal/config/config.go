@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,15 @@ import (
 
 type File struct {
 	KeyringBackend string `json:"keyring_backend,omitempty"`
+
+	// API request tuning; see GlobalKeys. Flags and env vars win.
+	Retries                 *int   `json:"retries,omitempty"`
+	HTTPTimeout             string `json:"http_timeout,omitempty"`
+	CircuitBreakerThreshold int    `json:"circuit_breaker_threshold,omitempty"`
+	CircuitBreakerReset     string `json:"circuit_breaker_reset,omitempty"`
+
+	AccountAliases map[string]string  `json:"account_aliases,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
 }
 
 func ConfigPath() (string, error) {
@@ -60,3 +70,32 @@ func ReadConfig() (File, error) {
 	cfg.KeyringBackend = strings.ToLower(strings.TrimSpace(cfg.KeyringBackend))
 	return cfg, nil
 }
+
+// WriteConfig writes cfg to config.json (plain JSON; comments in an existing
+// JSON5 file are not preserved). The write is atomic (tmp file + rename).
+func WriteConfig(cfg File) error {
+	if _, err := EnsureDir(); err != nil {
+		return fmt.Errorf("ensure config dir: %w", err)
+	}
+
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config json: %w", err)
+	}
+	b = append(b, '\n')
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit config: %w", err)
+	}
+
+	return nil
+}
@@ -131,3 +131,61 @@ func EnsureGmailWatchDir() (string, error) {
 
 	return dir, nil
 }
+
+// GmailImportDir holds resume state for `gmail import`, one file per
+// account+source.
+func GmailImportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-import"), nil
+}
+
+func EnsureGmailImportDir() (string, error) {
+	dir, err := GmailImportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail import dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+// HTTPCacheDir holds cached API responses (see googleapi.CacheTransport).
+func HTTPCacheDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "cache", "http"), nil
+}
+
+func EnsureHTTPCacheDir() (string, error) {
+	dir, err := HTTPCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure http cache dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+// CircuitBreakerStateDir holds persisted circuit breaker state, one file per
+// account+service, shared across invocations.
+func CircuitBreakerStateDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "circuit-breaker"), nil
+}
@@ -60,6 +60,15 @@ func TestPaths_CreateDirs(t *testing.T) {
 		t.Fatalf("expected watch dir: %v", statErr)
 	}
 
+	importDir, err := EnsureGmailImportDir()
+	if err != nil {
+		t.Fatalf("EnsureGmailImportDir: %v", err)
+	}
+
+	if _, statErr := os.Stat(importDir); statErr != nil {
+		t.Fatalf("expected import dir: %v", statErr)
+	}
+
 	credsPath, err := ClientCredentialsPath()
 	if err != nil {
 		t.Fatalf("ClientCredentialsPath: %v", err)
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Profile holds per-identity defaults, selected via --profile / GOG_PROFILE.
+// Flags and env vars still win over profile values.
+type Profile struct {
+	Account     string `json:"account,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	CalendarID  string `json:"calendar_id,omitempty"`
+	DriveParent string `json:"drive_parent,omitempty"`
+	TaskList    string `json:"tasklist,omitempty"`
+}
+
+// ProfileKeys lists the settable profile keys (config.json names).
+var ProfileKeys = []string{"account", "output", "color", "timezone", "calendar_id", "drive_parent", "tasklist"}
+
+// KeyringBackendKey is the top-level key selecting the keyring backend.
+const KeyringBackendKey = "keyring_backend"
+
+func (p Profile) Get(key string) (string, error) {
+	switch normalizeKey(key) {
+	case "account":
+		return p.Account, nil
+	case "output":
+		return p.Output, nil
+	case "color":
+		return p.Color, nil
+	case "timezone":
+		return p.Timezone, nil
+	case "calendar_id":
+		return p.CalendarID, nil
+	case "drive_parent":
+		return p.DriveParent, nil
+	case "tasklist":
+		return p.TaskList, nil
+	default:
+		return "", unknownKeyError(key)
+	}
+}
+
+// Set validates and stores value under key. An empty value clears the key.
+func (p *Profile) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	switch normalizeKey(key) {
+	case "account":
+		p.Account = value
+	case "output":
+		v := strings.ToLower(value)
+		switch v {
+		case "", "table", "text", "tsv", "plain", "csv", "markdown", "md", "json", "ndjson":
+		default:
+			return fmt.Errorf("invalid output %q (expected table|tsv|plain|csv|markdown|json|ndjson)", value)
+		}
+		p.Output = v
+	case "color":
+		v := strings.ToLower(value)
+		switch v {
+		case "", "auto", "always", "never":
+		default:
+			return fmt.Errorf("invalid color %q (expected auto|always|never)", value)
+		}
+		p.Color = v
+	case "timezone":
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("invalid timezone %q: %w", value, err)
+			}
+		}
+		p.Timezone = value
+	case "calendar_id":
+		p.CalendarID = value
+	case "drive_parent":
+		p.DriveParent = value
+	case "tasklist":
+		p.TaskList = value
+	default:
+		return unknownKeyError(key)
+	}
+	return nil
+}
+
+// Profile returns the named profile.
+func (f File) Profile(name string) (Profile, error) {
+	name = strings.TrimSpace(name)
+	p, ok := f.Profiles[name]
+	if !ok {
+		names := f.ProfileNames()
+		if len(names) == 0 {
+			return Profile{}, fmt.Errorf("unknown profile %q (no profiles in config.json)", name)
+		}
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// ProfileNames returns the configured profile names, sorted.
+func (f File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key %q (expected %s, or %s without --profile)", key, strings.Join(ProfileKeys, "|"), strings.Join(GlobalKeys, "|"))
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfile_SetGet(t *testing.T) {
+	var p Profile
+	if err := p.Set("account", " me@example.com "); err != nil {
+		t.Fatalf("Set account: %v", err)
+	}
+	if err := p.Set("Output", "JSON"); err != nil {
+		t.Fatalf("Set output: %v", err)
+	}
+	if err := p.Set("drive-parent", "folder1"); err != nil {
+		t.Fatalf("Set drive-parent: %v", err)
+	}
+	if err := p.Set("timezone", "Europe/Vienna"); err != nil {
+		t.Fatalf("Set timezone: %v", err)
+	}
+
+	for key, want := range map[string]string{
+		"account":      "me@example.com",
+		"output":       "json",
+		"drive_parent": "folder1",
+		"timezone":     "Europe/Vienna",
+		"tasklist":     "",
+	} {
+		got, err := p.Get(key)
+		if err != nil || got != want {
+			t.Fatalf("Get(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	for key, value := range map[string]string{
+		"output":   "xml",
+		"color":    "sometimes",
+		"timezone": "Mars/Olympus",
+		"nope":     "x",
+	} {
+		if err := p.Set(key, value); err == nil {
+			t.Fatalf("Set(%q, %q): expected error", key, value)
+		}
+	}
+
+	if err := p.Set("account", ""); err != nil || p.Account != "" {
+		t.Fatalf("expected empty value to clear account: %#v %v", p, err)
+	}
+}
+
+func TestFile_Profile(t *testing.T) {
+	f := File{Profiles: map[string]Profile{"work": {Account: "w@example.com"}, "home": {}}}
+
+	p, err := f.Profile("work")
+	if err != nil || p.Account != "w@example.com" {
+		t.Fatalf("Profile(work) = %#v, %v", p, err)
+	}
+	if _, err := f.Profile("nope"); err == nil || !strings.Contains(err.Error(), "home, work") {
+		t.Fatalf("expected unknown profile error listing names, got %v", err)
+	}
+	if _, err := (File{}).Profile("nope"); err == nil {
+		t.Fatalf("expected error without profiles")
+	}
+}
+
+func TestWriteConfig_RoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+
+	in := File{
+		KeyringBackend: "file",
+		Profiles:       map[string]Profile{"work": {Account: "w@example.com", CalendarID: "team@group.calendar.google.com"}},
+	}
+	if err := WriteConfig(in); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	out, err := ReadConfig()
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if out.KeyringBackend != "file" || out.Profiles["work"] != in.Profiles["work"] {
+		t.Fatalf("unexpected round trip: %#v", out)
+	}
+}
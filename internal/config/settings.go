@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GlobalKeys lists the settable top-level (non-profile) keys.
+var GlobalKeys = []string{KeyringBackendKey, "retries", "http_timeout", "circuit_breaker_threshold", "circuit_breaker_reset"}
+
+// Get returns a top-level key ("" when unset).
+func (f File) Get(key string) (string, error) {
+	switch normalizeKey(key) {
+	case KeyringBackendKey:
+		return f.KeyringBackend, nil
+	case "retries":
+		if f.Retries == nil {
+			return "", nil
+		}
+		return strconv.Itoa(*f.Retries), nil
+	case "http_timeout":
+		return f.HTTPTimeout, nil
+	case "circuit_breaker_threshold":
+		if f.CircuitBreakerThreshold == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(f.CircuitBreakerThreshold), nil
+	case "circuit_breaker_reset":
+		return f.CircuitBreakerReset, nil
+	default:
+		return "", unknownGlobalKeyError(key)
+	}
+}
+
+// Set validates and stores a top-level key. An empty value clears the key.
+func (f *File) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	switch normalizeKey(key) {
+	case KeyringBackendKey:
+		v := strings.ToLower(value)
+		switch v {
+		case "", "auto", "keychain", "file":
+		default:
+			return fmt.Errorf("invalid keyring_backend %q (expected auto, keychain, or file)", value)
+		}
+		f.KeyringBackend = v
+	case "retries":
+		if value == "" {
+			f.Retries = nil
+			return nil
+		}
+		n, err := ParseRetries(value)
+		if err != nil {
+			return err
+		}
+		f.Retries = &n
+	case "http_timeout":
+		if value != "" {
+			if _, err := ParsePositiveDuration("http_timeout", value); err != nil {
+				return err
+			}
+		}
+		f.HTTPTimeout = value
+	case "circuit_breaker_threshold":
+		if value == "" {
+			f.CircuitBreakerThreshold = 0
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid circuit_breaker_threshold %q (expected a number >= 1)", value)
+		}
+		f.CircuitBreakerThreshold = n
+	case "circuit_breaker_reset":
+		if value != "" {
+			if _, err := ParsePositiveDuration("circuit_breaker_reset", value); err != nil {
+				return err
+			}
+		}
+		f.CircuitBreakerReset = value
+	default:
+		return unknownGlobalKeyError(key)
+	}
+	return nil
+}
+
+// ParseRetries parses a retry count (>= 0).
+func ParseRetries(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid retries %q (expected a number >= 0)", value)
+	}
+	return n, nil
+}
+
+// ParsePositiveDuration parses a Go duration (e.g. "45s") that must be > 0.
+func ParsePositiveDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q (expected a duration like 30s or 2m)", name, value)
+	}
+	return d, nil
+}
+
+func unknownGlobalKeyError(key string) error {
+	return fmt.Errorf("unknown config key %q (expected %s; profile keys need --profile)", key, strings.Join(GlobalKeys, "|"))
+}
@@ -0,0 +1,209 @@
+package errfmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/99designs/keyring"
+	"github.com/alecthomas/kong"
+	"golang.org/x/oauth2"
+	ggoogleapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	gogapi "github.com/steipete/gogcli/internal/googleapi"
+)
+
+// Code is a stable, machine-readable error class. Scripts should branch on
+// Code (or the matching exit code), never on the message text.
+type Code string
+
+const (
+	CodeError              Code = "error"
+	CodeUsage              Code = "usage"
+	CodeAuthRequired       Code = "auth_required"
+	CodeCredentialsMissing Code = "credentials_missing"
+	CodeNotFound           Code = "not_found"
+	CodePermissionDenied   Code = "permission_denied"
+	CodeRateLimited        Code = "rate_limited"
+	CodeQuotaExceeded      Code = "quota_exceeded"
+	CodeCircuitOpen        Code = "circuit_open"
+	CodeNetwork            Code = "network"
+	CodeTimeout            Code = "timeout"
+	CodeServerError        Code = "server_error"
+	CodeAPIError           Code = "api_error"
+	CodeInterrupted        Code = "interrupted"
+)
+
+// exitCodes maps each Code to its process exit code. Values are stable.
+var exitCodes = map[Code]int{
+	CodeError:              1,
+	CodeUsage:              2,
+	CodeAuthRequired:       3,
+	CodeCredentialsMissing: 4,
+	CodeNotFound:           5,
+	CodePermissionDenied:   6,
+	CodeRateLimited:        7,
+	CodeQuotaExceeded:      8,
+	CodeCircuitOpen:        9,
+	CodeNetwork:            10,
+	CodeTimeout:            11,
+	CodeServerError:        12,
+	CodeAPIError:           13,
+	CodeInterrupted:        130,
+}
+
+// ExitCodeFor returns the exit code for code (1 for unknown codes).
+func ExitCodeFor(code Code) int {
+	if v, ok := exitCodes[code]; ok {
+		return v
+	}
+
+	return 1
+}
+
+// CodeForExit maps an exit code back to its Code (CodeError if unknown).
+func CodeForExit(exit int) Code {
+	for code, v := range exitCodes {
+		if v == exit {
+			return code
+		}
+	}
+
+	return CodeError
+}
+
+// Info is the structured form of an error (the --json error object).
+type Info struct {
+	Code     Code   `json:"code"`
+	Message  string `json:"message"`
+	Status   int    `json:"status,omitempty"` // HTTP status, for API errors
+	Reason   string `json:"reason,omitempty"` // Google API reason, e.g. "insufficientPermissions"
+	Hint     string `json:"hint,omitempty"`   // what to do about it
+	ExitCode int    `json:"exit_code"`
+}
+
+// Classify maps err to a stable Code plus details. Message is Format(err).
+func Classify(err error) Info {
+	info := classify(err)
+	info.Message = Format(err)
+	info.ExitCode = ExitCodeFor(info.Code)
+
+	return info
+}
+
+func classify(err error) Info {
+	var parseErr *kong.ParseError
+	if errors.As(err, &parseErr) {
+		return Info{Code: CodeUsage, Hint: "Run with --help to see usage"}
+	}
+
+	var authErr *gogapi.AuthRequiredError
+	if errors.As(err, &authErr) {
+		return Info{Code: CodeAuthRequired, Hint: fmt.Sprintf("gog auth add %s --services %s", authErr.Email, authErr.Service)}
+	}
+
+	var credErr *config.CredentialsMissingError
+	if errors.As(err, &credErr) {
+		return Info{Code: CodeCredentialsMissing, Hint: "gog auth credentials <credentials.json>"}
+	}
+
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		return Info{Code: CodeAuthRequired, Hint: "gog auth add <email>"}
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		return Info{Code: CodeAuthRequired, Reason: retrieveErr.ErrorCode, Hint: "Refresh token expired or revoked; run: gog auth add <email> --force-consent"}
+	}
+
+	if gogapi.IsCircuitBreakerError(err) {
+		return Info{Code: CodeCircuitOpen, Hint: "Too many recent failures; wait and retry (see circuit_breaker_reset)"}
+	}
+
+	if gogapi.IsRateLimitError(err) {
+		return Info{Code: CodeRateLimited, Status: http.StatusTooManyRequests, Hint: "Retry later or raise --retries"}
+	}
+
+	if gogapi.IsQuotaExceededError(err) {
+		return Info{Code: CodeQuotaExceeded, Hint: "API quota exhausted; retry after the quota resets"}
+	}
+
+	if gogapi.IsNotFoundError(err) {
+		return Info{Code: CodeNotFound, Status: http.StatusNotFound}
+	}
+
+	if gogapi.IsPermissionDeniedError(err) {
+		return Info{Code: CodePermissionDenied, Status: http.StatusForbidden, Hint: "Check sharing, or re-authorize with the needed --services"}
+	}
+
+	var gerr *ggoogleapi.Error
+	if errors.As(err, &gerr) {
+		return classifyGoogleAPIError(gerr)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return Info{Code: CodeInterrupted}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Info{Code: CodeTimeout, Hint: "Raise --http-timeout or retry"}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return Info{Code: CodeTimeout, Hint: "Raise --http-timeout or retry"}
+		}
+
+		return Info{Code: CodeNetwork, Hint: "Check network connectivity and retry"}
+	}
+
+	return Info{Code: CodeError}
+}
+
+func classifyGoogleAPIError(gerr *ggoogleapi.Error) Info {
+	info := Info{Code: CodeAPIError, Status: gerr.Code}
+	if len(gerr.Errors) > 0 {
+		info.Reason = gerr.Errors[0].Reason
+	}
+
+	switch info.Reason {
+	case "rateLimitExceeded", "userRateLimitExceeded":
+		info.Code = CodeRateLimited
+		info.Hint = "Retry later or raise --retries"
+
+		return info
+	case "quotaExceeded", "dailyLimitExceeded":
+		info.Code = CodeQuotaExceeded
+		info.Hint = "API quota exhausted; retry after the quota resets"
+
+		return info
+	case "insufficientPermissions":
+		info.Code = CodePermissionDenied
+		info.Hint = "Re-authorize with the needed scopes: gog auth add <email> --services <service>"
+
+		return info
+	}
+
+	switch {
+	case gerr.Code == http.StatusUnauthorized:
+		info.Code = CodeAuthRequired
+		info.Hint = "gog auth add <email>"
+	case gerr.Code == http.StatusForbidden:
+		info.Code = CodePermissionDenied
+		info.Hint = "Check sharing, or re-authorize with the needed --services"
+	case gerr.Code == http.StatusNotFound:
+		info.Code = CodeNotFound
+	case gerr.Code == http.StatusTooManyRequests:
+		info.Code = CodeRateLimited
+		info.Hint = "Retry later or raise --retries"
+	case gerr.Code >= 500:
+		info.Code = CodeServerError
+		info.Hint = "Google API server error; retry later"
+	}
+
+	return info
+}
@@ -0,0 +1,72 @@
+package errfmt
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"golang.org/x/oauth2"
+	ggoogleapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	gogapi "github.com/steipete/gogcli/internal/googleapi"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code Code
+		exit int
+	}{
+		{"plain", errNope, CodeError, 1},
+		{"auth", fmt.Errorf("wrap: %w", &gogapi.AuthRequiredError{Service: "gmail", Email: "a@b.com"}), CodeAuthRequired, 3},
+		{"invalid_grant", &oauth2.RetrieveError{ErrorCode: "invalid_grant"}, CodeAuthRequired, 3},
+		{"credentials", &config.CredentialsMissingError{Path: "/x"}, CodeCredentialsMissing, 4},
+		{"404", &ggoogleapi.Error{Code: 404, Message: "gone"}, CodeNotFound, 5},
+		{"403", &ggoogleapi.Error{Code: 403, Errors: []ggoogleapi.ErrorItem{{Reason: "forbidden"}}}, CodePermissionDenied, 6},
+		{"403 rate", &ggoogleapi.Error{Code: 403, Errors: []ggoogleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, CodeRateLimited, 7},
+		{"429", &ggoogleapi.Error{Code: 429}, CodeRateLimited, 7},
+		{"quota", &ggoogleapi.Error{Code: 403, Errors: []ggoogleapi.ErrorItem{{Reason: "dailyLimitExceeded"}}}, CodeQuotaExceeded, 8},
+		{"circuit", fmt.Errorf("get: %w", &gogapi.CircuitBreakerError{}), CodeCircuitOpen, 9},
+		{"dns", &net.DNSError{Err: "no such host", Name: "x"}, CodeNetwork, 10},
+		{"deadline", context.DeadlineExceeded, CodeTimeout, 11},
+		{"500", &ggoogleapi.Error{Code: 503}, CodeServerError, 12},
+		{"400", &ggoogleapi.Error{Code: 400, Errors: []ggoogleapi.ErrorItem{{Reason: "badRequest"}}}, CodeAPIError, 13},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := Classify(tc.err)
+			if info.Code != tc.code || info.ExitCode != tc.exit {
+				t.Fatalf("got %s/%d, want %s/%d", info.Code, info.ExitCode, tc.code, tc.exit)
+			}
+
+			if info.Message == "" {
+				t.Fatalf("expected message")
+			}
+		})
+	}
+}
+
+func TestClassify_GoogleAPIDetails(t *testing.T) {
+	info := Classify(&ggoogleapi.Error{Code: 403, Message: "nope", Errors: []ggoogleapi.ErrorItem{{Reason: "insufficientPermissions"}}})
+	if info.Status != 403 || info.Reason != "insufficientPermissions" || info.Hint == "" {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+}
+
+func TestExitCodesDistinct(t *testing.T) {
+	seen := map[int]Code{}
+	for code, exit := range exitCodes {
+		if prev, ok := seen[exit]; ok {
+			t.Fatalf("exit %d used by %s and %s", exit, prev, code)
+		}
+		seen[exit] = code
+
+		if CodeForExit(exit) != code {
+			t.Fatalf("CodeForExit(%d) = %s, want %s", exit, CodeForExit(exit), code)
+		}
+	}
+}
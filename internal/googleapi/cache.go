@@ -0,0 +1,306 @@
+package googleapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// cacheRule opts an endpoint into the response cache.
+type cacheRule struct {
+	path *regexp.Regexp // matched against the request URL path
+	ttl  time.Duration
+}
+
+// cacheRules lists the rarely-changing metadata endpoints worth caching.
+// Everything else goes straight to the network. Only the Gmail labels list
+// (names and IDs) is cached: labels/{id} carries message counts that change
+// with every incoming mail.
+var cacheRules = []cacheRule{
+	{regexp.MustCompile(`/gmail/v1/users/[^/]+/labels$`), 10 * time.Minute},
+	{regexp.MustCompile(`/calendar/v3/users/me/calendarList$`), 10 * time.Minute},
+	{regexp.MustCompile(`/tasks/v1/users/@me/lists$`), 10 * time.Minute},
+	{regexp.MustCompile(`/v4/spreadsheets/[^/]+$`), 2 * time.Minute},
+}
+
+// CacheTTL returns how long a GET response for u may be served from cache
+// (0 = not cached).
+func CacheTTL(u *url.URL) time.Duration {
+	for _, r := range cacheRules {
+		if r.path.MatchString(u.Path) {
+			return r.ttl
+		}
+	}
+
+	return 0
+}
+
+type cacheKey struct{}
+
+// WithCache enables (or disables) the on-disk response cache for services
+// created with ctx. Disabled unless set.
+func WithCache(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, cacheKey{}, enabled)
+}
+
+func cacheEnabled(ctx context.Context) bool {
+	v, _ := ctx.Value(cacheKey{}).(bool)
+	return v
+}
+
+// CacheEntry is one cached response on disk.
+type CacheEntry struct {
+	Account   string      `json:"account"`
+	URL       string      `json:"url"`
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	ETag      string      `json:"etag,omitempty"`
+	StoredAt  time.Time   `json:"stored_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// CacheTransport serves GET responses for the endpoints in cacheRules from
+// disk while fresh, and revalidates stale entries with If-None-Match.
+// Any other request (a write) drops the account's cached entries.
+type CacheTransport struct {
+	Base    http.RoundTripper
+	Dir     string // cache root; entries live in Dir/<account>/<sha256(url)>.json
+	Account string
+	now     func() time.Time
+}
+
+// NewCacheTransport creates a CacheTransport for account under dir.
+func NewCacheTransport(base http.RoundTripper, dir, account string) *CacheTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &CacheTransport{Base: base, Dir: dir, Account: account, now: time.Now}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := t.Base.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.invalidate()
+		}
+
+		return resp, err
+	}
+
+	ttl := CacheTTL(req.URL)
+	if ttl <= 0 {
+		return t.Base.RoundTrip(req)
+	}
+
+	path := t.entryPath(req.URL.String())
+	entry, ok := readCacheEntry(path)
+
+	if ok && t.now().Before(entry.ExpiresAt) {
+		slog.Debug("cache hit", "url", req.URL.String())
+		return entry.response(req), nil
+	}
+
+	if ok && entry.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		drainAndClose(resp.Body)
+		slog.Debug("cache revalidated", "url", req.URL.String())
+		entry.StoredAt = t.now()
+		entry.ExpiresAt = entry.StoredAt.Add(ttl)
+		t.write(path, entry)
+
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := t.now()
+	t.write(path, CacheEntry{
+		Account:   t.Account,
+		URL:       req.URL.String(),
+		Status:    resp.StatusCode,
+		Header:    resp.Header.Clone(),
+		Body:      body,
+		ETag:      resp.Header.Get("ETag"),
+		StoredAt:  now,
+		ExpiresAt: now.Add(ttl),
+	})
+
+	return resp, nil
+}
+
+func (t *CacheTransport) accountDir() string {
+	return filepath.Join(t.Dir, url.PathEscape(strings.ToLower(strings.TrimSpace(t.Account))))
+}
+
+func (t *CacheTransport) entryPath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(t.accountDir(), hex.EncodeToString(sum[:])+".json")
+}
+
+// write stores an entry; cache failures never fail the request.
+func (t *CacheTransport) write(path string, entry CacheEntry) {
+	if err := writeCacheEntry(path, entry); err != nil {
+		slog.Debug("cache write failed", "path", path, "err", err)
+	}
+}
+
+func (t *CacheTransport) invalidate() {
+	if err := os.RemoveAll(t.accountDir()); err != nil {
+		slog.Debug("cache invalidate failed", "account", t.Account, "err", err)
+	}
+}
+
+func (e CacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func readCacheEntry(path string) (CacheEntry, bool) {
+	data, err := os.ReadFile(path) //nolint:gosec // path derived from cache dir + hash
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+func writeCacheEntry(path string, entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("ensure cache dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// CacheStats summarizes the on-disk cache.
+type CacheStats struct {
+	Dir      string         `json:"dir"`
+	Entries  int            `json:"entries"`
+	Expired  int            `json:"expired"`
+	Bytes    int64          `json:"bytes"`
+	Accounts map[string]int `json:"accounts"`
+}
+
+// ReadCacheStats walks dir and counts entries per account.
+func ReadCacheStats(dir string, now time.Time) (CacheStats, error) {
+	stats := CacheStats{Dir: dir, Accounts: map[string]int{}}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		entry, ok := readCacheEntry(path)
+		if !ok {
+			return nil
+		}
+
+		if info, err := d.Info(); err == nil {
+			stats.Bytes += info.Size()
+		}
+		stats.Entries++
+		stats.Accounts[entry.Account]++
+
+		if !now.Before(entry.ExpiresAt) {
+			stats.Expired++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return CacheStats{}, fmt.Errorf("read cache: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ClearCache removes every cached entry under dir and returns how many were removed.
+func ClearCache(dir string) (int, error) {
+	stats, err := ReadCacheStats(dir, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("clear cache: %w", err)
+	}
+
+	return stats.Entries, nil
+}
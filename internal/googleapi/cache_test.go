@@ -0,0 +1,146 @@
+package googleapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// etagTransport answers with a fixed ETag and 304 when it matches.
+type etagTransport struct {
+	calls       int
+	ifNoneMatch []string
+	body        string
+}
+
+func (e *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e.calls++
+	e.ifNoneMatch = append(e.ifNoneMatch, req.Header.Get("If-None-Match"))
+
+	if req.Header.Get("If-None-Match") == `"v1"` {
+		return &http.Response{StatusCode: http.StatusNotModified, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": []string{`"v1"`}, "Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(e.body)),
+	}, nil
+}
+
+func doGet(t *testing.T, rt http.RoundTripper, method, rawURL string) string {
+	t.Helper()
+
+	req, _ := http.NewRequestWithContext(context.Background(), method, rawURL, nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+
+	return string(b)
+}
+
+const labelsURL = "https://gmail.googleapis.com/gmail/v1/users/me/labels?alt=json"
+
+func TestCacheTransport_FreshHit(t *testing.T) {
+	base := &etagTransport{body: `{"labels":[]}`}
+	rt := NewCacheTransport(base, t.TempDir(), "a@b.com")
+
+	for range 2 {
+		if got := doGet(t, rt, http.MethodGet, labelsURL); got != `{"labels":[]}` {
+			t.Fatalf("unexpected body: %q", got)
+		}
+	}
+
+	if base.calls != 1 {
+		t.Fatalf("expected 1 network call, got %d", base.calls)
+	}
+}
+
+func TestCacheTransport_RevalidatesWithETag(t *testing.T) {
+	base := &etagTransport{body: `{"labels":[]}`}
+	rt := NewCacheTransport(base, t.TempDir(), "a@b.com")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rt.now = func() time.Time { return now }
+
+	_ = doGet(t, rt, http.MethodGet, labelsURL)
+	now = now.Add(time.Hour)
+
+	if got := doGet(t, rt, http.MethodGet, labelsURL); got != `{"labels":[]}` {
+		t.Fatalf("unexpected body after 304: %q", got)
+	}
+
+	if base.calls != 2 || base.ifNoneMatch[1] != `"v1"` {
+		t.Fatalf("expected conditional request, got calls=%d headers=%q", base.calls, base.ifNoneMatch)
+	}
+
+	// Revalidation renews the TTL.
+	_ = doGet(t, rt, http.MethodGet, labelsURL)
+	if base.calls != 2 {
+		t.Fatalf("expected cache hit after revalidation, got %d calls", base.calls)
+	}
+}
+
+func TestCacheTransport_UncachedEndpointAndInvalidation(t *testing.T) {
+	base := &etagTransport{body: "x"}
+	dir := t.TempDir()
+	rt := NewCacheTransport(base, dir, "a@b.com")
+
+	_ = doGet(t, rt, http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/messages")
+	_ = doGet(t, rt, http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/messages")
+
+	if base.calls != 2 {
+		t.Fatalf("expected uncached endpoint to hit network, got %d calls", base.calls)
+	}
+
+	// labels/{id} carries live message counts.
+	_ = doGet(t, rt, http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/labels/INBOX")
+	_ = doGet(t, rt, http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/labels/INBOX")
+
+	if base.calls != 4 {
+		t.Fatalf("expected label details to hit network, got %d calls", base.calls)
+	}
+
+	_ = doGet(t, rt, http.MethodGet, labelsURL)
+
+	stats, err := ReadCacheStats(dir, time.Now())
+	if err != nil {
+		t.Fatalf("ReadCacheStats: %v", err)
+	}
+
+	if stats.Entries != 1 || stats.Accounts["a@b.com"] != 1 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	_ = doGet(t, rt, http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/labels")
+
+	if stats, _ := ReadCacheStats(dir, time.Now()); stats.Entries != 0 {
+		t.Fatalf("expected write to invalidate cache, got %#v", stats)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	dir := t.TempDir()
+	rt := NewCacheTransport(&etagTransport{body: "x"}, dir, "a@b.com")
+	_ = doGet(t, rt, http.MethodGet, "https://www.googleapis.com/calendar/v3/users/me/calendarList")
+
+	removed, err := ClearCache(dir)
+	if err != nil {
+		t.Fatalf("ClearCache: %v", err)
+	}
+
+	if removed != 1 {
+		t.Fatalf("expected 1 removed, got %d", removed)
+	}
+
+	if stats, err := ReadCacheStats(dir, time.Now()); err != nil || stats.Entries != 0 {
+		t.Fatalf("unexpected stats after clear: %#v %v", stats, err)
+	}
+}
@@ -1,7 +1,10 @@
 package googleapi
 
 import (
+	"encoding/json"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -13,39 +16,76 @@ const (
 	CircuitBreakerResetTime = 30 * time.Second
 	circuitStateOpen        = "open"
 	circuitStateClosed      = "closed"
+	circuitStateHalfOpen    = "half-open"
 )
 
+// CircuitBreaker stops requests after Threshold consecutive failures. After
+// ResetTime it lets a single probe request through (half-open): success
+// closes the circuit, failure opens it again.
 type CircuitBreaker struct {
+	Threshold int
+	ResetTime time.Duration
+
 	mu          sync.Mutex
 	failures    int
 	lastFailure time.Time
 	open        bool
+	halfOpen    bool
+	probing     bool
+	probeSeq    uint64 // identifies the current probe for releaseProbe
+	statePath   string
 }
 
 func NewCircuitBreaker() *CircuitBreaker {
-	return &CircuitBreaker{}
+	return &CircuitBreaker{Threshold: CircuitBreakerThreshold, ResetTime: CircuitBreakerResetTime}
+}
+
+// NewPersistentCircuitBreaker loads and saves its state at statePath, so
+// consecutive invocations (e.g. cron jobs) share it.
+func NewPersistentCircuitBreaker(threshold int, resetTime time.Duration, statePath string) *CircuitBreaker {
+	cb := &CircuitBreaker{Threshold: threshold, ResetTime: resetTime, statePath: statePath}
+	cb.load()
+
+	return cb
 }
 
 func (cb *CircuitBreaker) RecordSuccess() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
-	wasOpen := cb.open
+	changed := cb.open || cb.halfOpen || cb.failures > 0
+	wasOpen := cb.open || cb.halfOpen
 	cb.failures = 0
 	cb.open = false
+	cb.halfOpen = false
+	cb.probing = false
 
 	if wasOpen {
 		slog.Info("circuit breaker reset")
 	}
+
+	if changed {
+		cb.save()
+	}
 }
 
 func (cb *CircuitBreaker) RecordFailure() bool {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	defer cb.save()
 
 	cb.failures++
 	cb.lastFailure = time.Now()
 
-	if cb.failures >= CircuitBreakerThreshold {
+	if cb.halfOpen {
+		cb.halfOpen = false
+		cb.probing = false
+		cb.open = true
+		slog.Warn("circuit breaker probe failed; reopened")
+
+		return true
+	}
+
+	if !cb.open && cb.failures >= cb.threshold() {
 		cb.open = true
 		slog.Warn("circuit breaker opened", "failures", cb.failures)
 
@@ -55,33 +95,151 @@ func (cb *CircuitBreaker) RecordFailure() bool {
 	return false
 }
 
+// IsOpen reports whether a request must be rejected. Once ResetTime has
+// passed the circuit turns half-open and the first caller becomes the probe.
 func (cb *CircuitBreaker) IsOpen() bool {
+	_, open := cb.acquire()
+
+	return open
+}
+
+// acquire is IsOpen for callers that release their probe: probe is non-zero
+// when the caller became the half-open probe.
+func (cb *CircuitBreaker) acquire() (probe uint64, open bool) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	if cb.halfOpen {
+		if cb.probing {
+			return 0, true
+		}
+
+		return cb.startProbe(), false
+	}
+
 	if !cb.open {
-		return false
+		return 0, false
 	}
 	// Check if reset time has passed
-	if time.Since(cb.lastFailure) > CircuitBreakerResetTime {
+	if time.Since(cb.lastFailure) > cb.resetTime() {
 		cb.open = false
-		cb.failures = 0
+		cb.halfOpen = true
+
+		slog.Info("circuit breaker half-open; probing")
+
+		return cb.startProbe(), false
+	}
+
+	return 0, true
+}
 
-		slog.Info("circuit breaker attempting reset after timeout")
+func (cb *CircuitBreaker) startProbe() uint64 {
+	cb.probing = true
+	cb.probeSeq++
 
-		return false
+	return cb.probeSeq
+}
+
+// releaseProbe ends a probe that finished without RecordSuccess or
+// RecordFailure (cancelled, body error), so the next request can probe
+// instead of the circuit rejecting everything while half-open.
+func (cb *CircuitBreaker) releaseProbe(probe uint64) {
+	if probe == 0 {
+		return
 	}
 
-	return true
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.halfOpen && cb.probing && cb.probeSeq == probe {
+		cb.probing = false
+	}
 }
 
 func (cb *CircuitBreaker) State() string {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	if cb.open {
+	switch {
+	case cb.halfOpen:
+		return circuitStateHalfOpen
+	case cb.open:
 		return circuitStateOpen
+	default:
+		return circuitStateClosed
 	}
+}
 
-	return circuitStateClosed
+func (cb *CircuitBreaker) threshold() int {
+	if cb.Threshold <= 0 {
+		return CircuitBreakerThreshold
+	}
+
+	return cb.Threshold
+}
+
+func (cb *CircuitBreaker) resetTime() time.Duration {
+	if cb.ResetTime <= 0 {
+		return CircuitBreakerResetTime
+	}
+
+	return cb.ResetTime
+}
+
+type circuitBreakerState struct {
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"last_failure"`
+	Open        bool      `json:"open"`
+}
+
+func (cb *CircuitBreaker) load() {
+	if cb.statePath == "" {
+		return
+	}
+
+	data, err := os.ReadFile(cb.statePath) //nolint:gosec // state file under config dir
+	if err != nil {
+		return
+	}
+
+	var st circuitBreakerState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return
+	}
+
+	cb.failures = st.Failures
+	cb.lastFailure = st.LastFailure
+	cb.open = st.Open
+}
+
+// save persists the state; callers hold cb.mu. Failures are only logged.
+func (cb *CircuitBreaker) save() {
+	if cb.statePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(circuitBreakerState{
+		Failures:    cb.failures,
+		LastFailure: cb.lastFailure,
+		// A half-open circuit reopens on the next process until a probe succeeds.
+		Open: cb.open || cb.halfOpen,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cb.statePath), 0o700); err != nil {
+		slog.Debug("circuit breaker state dir", "err", err)
+		return
+	}
+
+	tmp := cb.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		slog.Debug("circuit breaker state write", "err", err)
+		return
+	}
+
+	if err := os.Rename(tmp, cb.statePath); err != nil {
+		slog.Debug("circuit breaker state commit", "err", err)
+	}
 }
@@ -1,6 +1,7 @@
 package googleapi
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -26,14 +27,18 @@ func TestCircuitBreaker_OpenAndReset(t *testing.T) {
 		t.Fatalf("expected open state")
 	}
 
-	// Force timeout-based reset path.
+	// Force timeout-based reset path: one probe gets through (half-open).
 	cb.lastFailure = time.Now().Add(-(CircuitBreakerResetTime + time.Second))
 	if cb.IsOpen() {
-		t.Fatalf("expected closed after timeout")
+		t.Fatalf("expected probe to pass after timeout")
 	}
 
-	if cb.State() != "closed" {
-		t.Fatalf("expected closed after timeout")
+	if cb.State() != "half-open" {
+		t.Fatalf("expected half-open after timeout, got %q", cb.State())
+	}
+
+	if !cb.IsOpen() {
+		t.Fatalf("expected other requests rejected while probing")
 	}
 
 	// Explicit success reset path.
@@ -44,3 +49,47 @@ func TestCircuitBreaker_OpenAndReset(t *testing.T) {
 		t.Fatalf("expected closed after success")
 	}
 }
+
+func TestCircuitBreaker_HalfOpenProbeFailureReopens(t *testing.T) {
+	cb := &CircuitBreaker{Threshold: 2, ResetTime: time.Minute}
+	cb.RecordFailure()
+
+	if !cb.RecordFailure() {
+		t.Fatalf("expected circuit to open at threshold 2")
+	}
+
+	cb.lastFailure = time.Now().Add(-2 * time.Minute)
+	if cb.IsOpen() {
+		t.Fatalf("expected probe to pass")
+	}
+
+	if !cb.RecordFailure() {
+		t.Fatalf("expected failed probe to reopen")
+	}
+
+	if cb.State() != "open" || !cb.IsOpen() {
+		t.Fatalf("expected open after failed probe, got %q", cb.State())
+	}
+}
+
+func TestCircuitBreaker_PersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cb.json")
+
+	cb := NewPersistentCircuitBreaker(1, time.Minute, path)
+	cb.RecordFailure()
+
+	again := NewPersistentCircuitBreaker(1, time.Minute, path)
+	if !again.IsOpen() {
+		t.Fatalf("expected open state loaded from %s", path)
+	}
+
+	again.lastFailure = time.Now().Add(-2 * time.Minute)
+	if again.IsOpen() {
+		t.Fatalf("expected probe to pass")
+	}
+	again.RecordSuccess()
+
+	if NewPersistentCircuitBreaker(1, time.Minute, path).State() != "closed" {
+		t.Fatalf("expected closed state after successful probe")
+	}
+}
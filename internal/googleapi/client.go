@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/99designs/keyring"
 	"golang.org/x/oauth2"
@@ -19,8 +18,6 @@ import (
 	"github.com/steipete/gogcli/internal/secrets"
 )
 
-const defaultHTTPTimeout = 30 * time.Second
-
 var (
 	readClientCredentials = config.ReadClientCredentials
 	openSecretsStore      = secrets.OpenDefault
@@ -75,7 +72,7 @@ func tokenSourceForAccountScopes(ctx context.Context, serviceLabel string, email
 	}
 
 	// Ensure refresh-token exchanges don't hang forever.
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: defaultHTTPTimeout})
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: RetryConfigFromContext(ctx).Timeout})
 
 	return cfg.TokenSource(ctx, &oauth2.Token{RefreshToken: tok.RefreshToken}), nil
 }
@@ -92,14 +89,15 @@ func optionsForAccount(ctx context.Context, service googleauth.Service, email st
 			MinVersion: tls.VersionTLS12,
 		},
 	}
-	// Wrap with retry logic for 429 and 5xx errors
-	retryTransport := NewRetryTransport(&oauth2.Transport{
+	// Wrap with retry logic for 429, 5xx and network errors
+	retryCfg := RetryConfigFromContext(ctx)
+	retryTransport := NewRetryTransportConfig(&oauth2.Transport{
 		Source: ts,
 		Base:   baseTransport,
-	})
+	}, retryCfg, email+"-"+string(service))
 	c := &http.Client{
-		Transport: retryTransport,
-		Timeout:   defaultHTTPTimeout,
+		Transport: cachedTransport(ctx, retryTransport, email),
+		Timeout:   retryCfg.Timeout,
 	}
 
 	slog.Debug("client options created successfully", "service", service, "email", email)
@@ -130,17 +128,33 @@ func optionsForAccountScopes(ctx context.Context, serviceLabel string, email str
 			MinVersion: tls.VersionTLS12,
 		},
 	}
-	// Wrap with retry logic for 429 and 5xx errors
-	retryTransport := NewRetryTransport(&oauth2.Transport{
+	// Wrap with retry logic for 429, 5xx and network errors
+	retryCfg := RetryConfigFromContext(ctx)
+	retryTransport := NewRetryTransportConfig(&oauth2.Transport{
 		Source: ts,
 		Base:   baseTransport,
-	})
+	}, retryCfg, email+"-"+serviceLabel)
 	c := &http.Client{
-		Transport: retryTransport,
-		Timeout:   defaultHTTPTimeout,
+		Transport: cachedTransport(ctx, retryTransport, email),
+		Timeout:   retryCfg.Timeout,
 	}
 
 	slog.Debug("client options with custom scopes created successfully", "serviceLabel", serviceLabel, "email", email)
 
 	return []option.ClientOption{option.WithHTTPClient(c)}, nil
 }
+
+// cachedTransport layers the response cache over rt when enabled via WithCache.
+func cachedTransport(ctx context.Context, rt http.RoundTripper, email string) http.RoundTripper {
+	if !cacheEnabled(ctx) {
+		return rt
+	}
+
+	dir, err := config.HTTPCacheDir()
+	if err != nil {
+		slog.Debug("cache disabled", "err", err)
+		return rt
+	}
+
+	return NewCacheTransport(rt, dir, email)
+}
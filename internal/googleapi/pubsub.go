@@ -0,0 +1,20 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/steipete/gogcli/internal/googleauth"
+)
+
+func NewPubSub(ctx context.Context, email string) (*pubsub.Service, error) {
+	if opts, err := optionsForAccount(ctx, googleauth.ServicePubSub, email); err != nil {
+		return nil, fmt.Errorf("pubsub options: %w", err)
+	} else if svc, err := pubsub.NewService(ctx, opts...); err != nil {
+		return nil, fmt.Errorf("create pubsub service: %w", err)
+	} else {
+		return svc, nil
+	}
+}
@@ -0,0 +1,61 @@
+package googleapi
+
+import (
+	"context"
+	"time"
+)
+
+// RetryConfig tunes RetryTransport, its CircuitBreaker and the HTTP client
+// timeout for services created from a context (see WithRetryConfig).
+type RetryConfig struct {
+	MaxRetries429     int
+	MaxRetries5xx     int
+	MaxRetriesNetwork int
+	BaseDelay         time.Duration
+	Timeout           time.Duration
+
+	BreakerThreshold int
+	BreakerResetTime time.Duration
+	// BreakerStateDir persists circuit breaker state across processes
+	// ("" = in process only).
+	BreakerStateDir string
+}
+
+// DefaultRetryConfig returns the built-in defaults.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries429:     MaxRateLimitRetries,
+		MaxRetries5xx:     Max5xxRetries,
+		MaxRetriesNetwork: MaxNetworkRetries,
+		BaseDelay:         RateLimitBaseDelay,
+		Timeout:           DefaultHTTPTimeout,
+		BreakerThreshold:  CircuitBreakerThreshold,
+		BreakerResetTime:  CircuitBreakerResetTime,
+	}
+}
+
+// WithRetries sets the same retry budget for 429, 5xx and network errors.
+func (c RetryConfig) WithRetries(n int) RetryConfig {
+	c.MaxRetries429 = n
+	c.MaxRetries5xx = n
+	c.MaxRetriesNetwork = n
+
+	return c
+}
+
+type retryConfigKey struct{}
+
+// WithRetryConfig makes services created with ctx use cfg.
+func WithRetryConfig(ctx context.Context, cfg RetryConfig) context.Context {
+	return context.WithValue(ctx, retryConfigKey{}, cfg)
+}
+
+// RetryConfigFromContext returns the config set by WithRetryConfig, or the
+// defaults.
+func RetryConfigFromContext(ctx context.Context) RetryConfig {
+	if cfg, ok := ctx.Value(retryConfigKey{}).(RetryConfig); ok {
+		return cfg
+	}
+
+	return DefaultRetryConfig()
+}
@@ -11,4 +11,9 @@ const (
 	Max5xxRetries = 1
 	// ServerErrorRetryDelay is the delay before retrying on 5xx errors.
 	ServerErrorRetryDelay = 1 * time.Second
+	// MaxNetworkRetries is the maximum retries for transient network errors
+	// (connection reset, DNS failure, timeout).
+	MaxNetworkRetries = 2
+	// DefaultHTTPTimeout bounds a single API request, retries included.
+	DefaultHTTPTimeout = 30 * time.Second
 )
@@ -2,23 +2,29 @@ package googleapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
+	"path/filepath"
 	"strconv"
+	"syscall"
 	"time"
 )
 
 // RetryTransport wraps an http.RoundTripper with retry logic for
-// rate limits (429) and server errors (5xx).
+// rate limits (429), server errors (5xx) and transient network errors.
 type RetryTransport struct {
-	Base           http.RoundTripper
-	MaxRetries429  int
-	MaxRetries5xx  int
-	BaseDelay      time.Duration
-	CircuitBreaker *CircuitBreaker
+	Base              http.RoundTripper
+	MaxRetries429     int
+	MaxRetries5xx     int
+	MaxRetriesNetwork int
+	BaseDelay         time.Duration
+	CircuitBreaker    *CircuitBreaker
 }
 
 // NewRetryTransport creates a RetryTransport with sensible defaults.
@@ -28,18 +34,43 @@ func NewRetryTransport(base http.RoundTripper) *RetryTransport {
 	}
 
 	return &RetryTransport{
-		Base:           base,
-		MaxRetries429:  MaxRateLimitRetries,
-		MaxRetries5xx:  Max5xxRetries,
-		BaseDelay:      RateLimitBaseDelay,
-		CircuitBreaker: NewCircuitBreaker(),
+		Base:              base,
+		MaxRetries429:     MaxRateLimitRetries,
+		MaxRetries5xx:     Max5xxRetries,
+		MaxRetriesNetwork: MaxNetworkRetries,
+		BaseDelay:         RateLimitBaseDelay,
+		CircuitBreaker:    NewCircuitBreaker(),
 	}
 }
 
+// NewRetryTransportConfig creates a RetryTransport from cfg. The circuit
+// breaker persists its state under cfg.BreakerStateDir/<stateKey>.json when
+// both are set.
+func NewRetryTransportConfig(base http.RoundTripper, cfg RetryConfig, stateKey string) *RetryTransport {
+	t := NewRetryTransport(base)
+	t.MaxRetries429 = cfg.MaxRetries429
+	t.MaxRetries5xx = cfg.MaxRetries5xx
+	t.MaxRetriesNetwork = cfg.MaxRetriesNetwork
+	t.BaseDelay = cfg.BaseDelay
+
+	statePath := ""
+	if cfg.BreakerStateDir != "" && stateKey != "" {
+		statePath = filepath.Join(cfg.BreakerStateDir, url.PathEscape(stateKey)+".json")
+	}
+	t.CircuitBreaker = NewPersistentCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerResetTime, statePath)
+
+	return t
+}
+
 // RoundTrip implements http.RoundTripper with retry logic.
 func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if t.CircuitBreaker != nil && t.CircuitBreaker.IsOpen() {
-		return nil, &CircuitBreakerError{}
+	if t.CircuitBreaker != nil {
+		probe, open := t.CircuitBreaker.acquire()
+		if open {
+			return nil, &CircuitBreakerError{}
+		}
+		// Early returns below record no result; don't leave the probe taken.
+		defer t.CircuitBreaker.releaseProbe(probe)
 	}
 
 	if err := ensureReplayableBody(req); err != nil {
@@ -50,6 +81,7 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	var err error
 	retries429 := 0
 	retries5xx := 0
+	retriesNetwork := 0
 
 	for {
 		// Reset body for retry
@@ -67,7 +99,32 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		resp, err = t.Base.RoundTrip(req)
 		if err != nil {
-			return nil, fmt.Errorf("round trip: %w", err)
+			if !isNetworkError(req, err) {
+				return nil, fmt.Errorf("round trip: %w", err)
+			}
+
+			if t.CircuitBreaker != nil {
+				t.CircuitBreaker.RecordFailure()
+			}
+
+			if retriesNetwork >= t.MaxRetriesNetwork || !retryableNetworkError(req, err) {
+				return nil, fmt.Errorf("round trip: %w", err)
+			}
+
+			delay := t.calculateBackoff(retriesNetwork, nil)
+			slog.Debug("network error, retrying",
+				"err", err,
+				"delay", delay,
+				"attempt", retriesNetwork+1,
+				"max_retries", t.MaxRetriesNetwork)
+
+			if err := t.sleep(req.Context(), delay); err != nil {
+				return nil, err
+			}
+
+			retriesNetwork++
+
+			continue
 		}
 
 		// Success
@@ -82,6 +139,10 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		// Rate limit (429)
 		if resp.StatusCode == http.StatusTooManyRequests {
 			if retries429 >= t.MaxRetries429 {
+				if t.CircuitBreaker != nil {
+					t.CircuitBreaker.RecordSuccess() // server is up, just busy
+				}
+
 				return resp, nil // Return the 429 response after max retries
 			}
 
@@ -128,13 +189,17 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		}
 
 		// Other errors (4xx except 429): don't retry
+		if t.CircuitBreaker != nil {
+			t.CircuitBreaker.RecordSuccess() // server is up
+		}
+
 		return resp, nil
 	}
 }
 
 func (t *RetryTransport) calculateBackoff(attempt int, resp *http.Response) time.Duration {
 	// Check Retry-After header
-	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+	if retryAfter := retryAfterHeader(resp); retryAfter != "" {
 		if seconds, err := strconv.Atoi(retryAfter); err == nil {
 			if seconds < 0 {
 				return 0
@@ -236,3 +301,58 @@ func drainAndClose(body io.ReadCloser) {
 	_, _ = io.Copy(io.Discard, io.LimitReader(body, 1<<20))
 	_ = body.Close()
 }
+
+func retryAfterHeader(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+
+	return resp.Header.Get("Retry-After")
+}
+
+// isNetworkError reports whether err is a transport-level failure (as
+// opposed to a cancelled request or a local error).
+func isNetworkError(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryableNetworkError reports whether a failed request may be resent.
+// Requests that never reached the server (DNS failure, connection refused,
+// dial errors) are always safe; anything else only for idempotent requests.
+func retryableNetworkError(req *http.Request, err error) bool {
+	if !isNetworkError(req, err) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return isIdempotent(req)
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
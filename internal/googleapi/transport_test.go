@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -272,6 +274,47 @@ func TestRetryTransport_CircuitBreakerOpen(t *testing.T) {
 	}
 }
 
+func TestRetryTransport_CancelledProbeReleasesCircuit(t *testing.T) {
+	mock := &mockTransport{
+		responses: []*http.Response{
+			{StatusCode: 429, Body: io.NopCloser(strings.NewReader("rate limited"))},
+		},
+	}
+
+	rt := NewRetryTransport(mock)
+	rt.BaseDelay = time.Second
+	rt.CircuitBreaker.ResetTime = time.Millisecond
+	for i := 0; i < CircuitBreakerThreshold; i++ {
+		rt.CircuitBreaker.RecordFailure()
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The probe gets a 429 and is cancelled during its backoff.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://example.com", nil)
+	if resp, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	req, _ = http.NewRequestWithContext(context.Background(), "GET", "https://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected the next request to probe, got %v", err)
+	}
+	resp.Body.Close()
+
+	if rt.CircuitBreaker.State() != circuitStateClosed {
+		t.Fatalf("expected circuit closed after a successful probe, got %s", rt.CircuitBreaker.State())
+	}
+}
+
 func TestRetryTransport_CircuitBreakerReset(t *testing.T) {
 	mock := &mockTransport{
 		responses: []*http.Response{
@@ -442,3 +485,95 @@ func TestEnsureReplayableBody(t *testing.T) {
 		t.Fatalf("unexpected body replay: %q %q", string(first), string(second))
 	}
 }
+
+func TestRetryTransport_RetriesNetworkErrorForIdempotent(t *testing.T) {
+	mock := &mockTransport{
+		errors:    []error{syscall.ECONNRESET, nil},
+		responses: []*http.Response{nil, {StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}},
+	}
+
+	rt := NewRetryTransport(mock)
+	rt.BaseDelay = time.Millisecond
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "https://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if mock.calls != 2 {
+		t.Errorf("expected 2 calls, got %d", mock.calls)
+	}
+}
+
+func TestRetryTransport_NoNetworkRetryForPOSTReset(t *testing.T) {
+	mock := &mockTransport{errors: []error{syscall.ECONNRESET}}
+
+	rt := NewRetryTransport(mock)
+	rt.BaseDelay = time.Millisecond
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "https://example.com", strings.NewReader("x"))
+
+	resp, err := rt.RoundTrip(req)
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("expected ECONNRESET, got %v", err)
+	}
+
+	if mock.calls != 1 {
+		t.Errorf("expected no retry for non-idempotent request, got %d calls", mock.calls)
+	}
+}
+
+func TestRetryTransport_RetriesPOSTWhenNotSent(t *testing.T) {
+	mock := &mockTransport{
+		errors:    []error{&net.DNSError{Err: "temporary failure", Name: "example.com"}, nil},
+		responses: []*http.Response{nil, {StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}},
+	}
+
+	rt := NewRetryTransport(mock)
+	rt.BaseDelay = time.Millisecond
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "https://example.com", strings.NewReader("x"))
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if mock.calls != 2 {
+		t.Errorf("expected DNS failure to be retried, got %d calls", mock.calls)
+	}
+}
+
+func TestNewRetryTransportConfig(t *testing.T) {
+	cfg := DefaultRetryConfig().WithRetries(0)
+	cfg.BreakerThreshold = 1
+
+	mock := &mockTransport{
+		responses: []*http.Response{{StatusCode: 503, Body: io.NopCloser(strings.NewReader("down"))}},
+	}
+	rt := NewRetryTransportConfig(mock, cfg, "")
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "https://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if mock.calls != 1 || resp.StatusCode != 503 {
+		t.Fatalf("expected single attempt with --retries 0, got calls=%d status=%d", mock.calls, resp.StatusCode)
+	}
+
+	if rt.CircuitBreaker.State() != "open" {
+		t.Fatalf("expected breaker open at threshold 1, got %q", rt.CircuitBreaker.State())
+	}
+}
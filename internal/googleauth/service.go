@@ -17,16 +17,19 @@ const (
 	ServiceTasks    Service = "tasks"
 	ServicePeople   Service = "people"
 	ServiceSheets   Service = "sheets"
+	// ServicePubSub is opt-in (not part of "all"): only `gmail watch pull`
+	// needs it, to read a Pub/Sub subscription.
+	ServicePubSub Service = "pubsub"
 )
 
 var errUnknownService = errors.New("unknown service")
 
 func ParseService(s string) (Service, error) {
 	switch Service(strings.ToLower(strings.TrimSpace(s))) {
-	case ServiceGmail, ServiceCalendar, ServiceDrive, ServiceContacts, ServiceTasks, ServicePeople, ServiceSheets:
+	case ServiceGmail, ServiceCalendar, ServiceDrive, ServiceContacts, ServiceTasks, ServicePeople, ServiceSheets, ServicePubSub:
 		return Service(strings.ToLower(strings.TrimSpace(s))), nil
 	default:
-		return "", fmt.Errorf("%w %q (expected gmail|calendar|drive|contacts|tasks|people|sheets)", errUnknownService, s)
+		return "", fmt.Errorf("%w %q (expected gmail|calendar|drive|contacts|tasks|people|sheets|pubsub)", errUnknownService, s)
 	}
 }
 
@@ -55,6 +58,8 @@ func Scopes(service Service) ([]string, error) {
 		return []string{"profile"}, nil
 	case ServiceSheets:
 		return []string{"https://www.googleapis.com/auth/spreadsheets"}, nil
+	case ServicePubSub:
+		return []string{"https://www.googleapis.com/auth/pubsub"}, nil
 	default:
 		return nil, errUnknownService
 	}
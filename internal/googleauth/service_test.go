@@ -14,6 +14,7 @@ func TestParseService(t *testing.T) {
 		{"contacts", ServiceContacts},
 		{"tasks", ServiceTasks},
 		{"people", ServicePeople},
+		{"pubsub", ServicePubSub},
 	}
 	for _, tt := range tests {
 		got, err := ParseService(tt.in)
@@ -0,0 +1,54 @@
+package outfmt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// Query is a compiled jq expression, evaluated with gojq. The whole jq
+// language is available except what reads outside the JSON value: $ENV and
+// env are empty, and input/inputs fail.
+type Query struct {
+	src  string
+	code *gojq.Code
+}
+
+// ParseQuery compiles a jq expression.
+func ParseQuery(src string) (*Query, error) {
+	parsed, err := gojq.Parse(src)
+	if err != nil {
+		return nil, &ParseError{msg: fmt.Sprintf("invalid --jq: %v", err)}
+	}
+	code, err := gojq.Compile(parsed, gojq.WithEnvironLoader(func() []string { return nil }))
+	if err != nil {
+		return nil, &ParseError{msg: fmt.Sprintf("invalid --jq: %v", err)}
+	}
+	return &Query{src: src, code: code}, nil
+}
+
+// Eval runs the query against a decoded JSON value and returns every output.
+func (q *Query) Eval(v any) ([]any, error) {
+	if q == nil || q.code == nil {
+		return []any{v}, nil
+	}
+
+	var out []any
+	iter := q.code.Run(v)
+	for {
+		val, ok := iter.Next()
+		if !ok {
+			return out, nil
+		}
+		if err, ok := val.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				// halt stops the program without an error.
+				return out, nil
+			}
+			return nil, fmt.Errorf("jq %q: %w", q.src, err)
+		}
+		out = append(out, val)
+	}
+}
@@ -0,0 +1,118 @@
+package outfmt
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func evalJSON(t *testing.T, expr string, input string) []any {
+	t.Helper()
+
+	q, err := ParseQuery(expr)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", expr, err)
+	}
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	out, err := q.Eval(v)
+	if err != nil {
+		t.Fatalf("Eval(%q): %v", expr, err)
+	}
+	return out
+}
+
+func TestQuery_Paths(t *testing.T) {
+	in := `{"files":[{"id":"a","name":"A","owners":[{"email":"x@y"}]},{"id":"b","name":"B"}],"nextPageToken":"tok"}`
+
+	cases := []struct {
+		expr string
+		want []any
+	}{
+		{".nextPageToken", []any{"tok"}},
+		{".files[].id", []any{"a", "b"}},
+		{".files[0].name", []any{"A"}},
+		{".files[-1].id", []any{"b"}},
+		{`.["nextPageToken"]`, []any{"tok"}},
+		{".files[0].owners[0].email", []any{"x@y"}},
+		{".missing", []any{nil}},
+		{".files | length", []any{2}},
+		{".files[] | select(.id == \"b\") | .name", []any{"B"}},
+		{".files | map(.id) | join(\",\")", []any{"a,b"}},
+		{"[.files[].id]", []any{[]any{"a", "b"}}},
+		{".files[0] | keys", []any{[]any{"id", "name", "owners"}}},
+		{".files[1].owners // \"none\"", []any{"none"}},
+		{".files[0].id, .nextPageToken", []any{"a", "tok"}},
+		{".files[] | has(\"owners\")", []any{true, false}},
+		{".files | first | .id", []any{"a"}},
+		{".nextPageToken | type", []any{"string"}},
+	}
+
+	for _, tc := range cases {
+		got := evalJSON(t, tc.expr, in)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %#v want %#v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestQuery_ObjectConstruction(t *testing.T) {
+	got := evalJSON(t, `.files[] | {id, title: .name}`, `{"files":[{"id":"a","name":"A","size":"1"}]}`)
+	want := []any{map[string]any{"id": "a", "title": "A"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+}
+
+func TestQuery_BoolAndCompare(t *testing.T) {
+	in := `[{"n":1,"ok":true},{"n":5,"ok":false},{"n":10,"ok":true}]`
+	got := evalJSON(t, `.[] | select(.n > 2 and .ok) | .n`, in)
+	if !reflect.DeepEqual(got, []any{json.Number("10")}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	got = evalJSON(t, `.[] | select(.n <= 1 or (.ok | not)) | .n`, in)
+	if !reflect.DeepEqual(got, []any{json.Number("1"), json.Number("5")}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+}
+
+func TestQuery_TrySuppressesErrors(t *testing.T) {
+	got := evalJSON(t, `.[] | .a?`, `[{"a":1},"str"]`)
+	if !reflect.DeepEqual(got, []any{json.Number("1")}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+}
+
+func TestQuery_FullLanguage(t *testing.T) {
+	in := `{"files":[{"name":"b","size":"20"},{"name":"a","size":"3"}]}`
+	got := evalJSON(t, `[.files | sort_by(.name)[] | "\(.name)=\(.size | tonumber)"] | join(" ")`, in)
+	if !reflect.DeepEqual(got, []any{"a=3 b=20"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+
+	got = evalJSON(t, `$ENV | length`, `null`)
+	if !reflect.DeepEqual(got, []any{0}) {
+		t.Fatalf("expected empty $ENV, got %#v", got)
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	for _, expr := range []string{"", ".[", "nope", "select(", ".a ==", "{a b}", `"unterminated`} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Fatalf("expected parse error for %q", expr)
+		}
+	}
+
+	q, err := ParseQuery(".a")
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	if _, err := q.Eval("str"); err == nil || !strings.Contains(err.Error(), "expected an object but got: string") {
+		t.Fatalf("expected index error, got %v", err)
+	}
+}
@@ -10,26 +10,66 @@ import (
 )
 
 type Mode struct {
-	JSON  bool
-	Plain bool
+	JSON   bool
+	Plain  bool
+	NDJSON bool
+	Format Format
 }
 
 type ParseError struct{ msg string }
 
 func (e *ParseError) Error() string { return e.msg }
 
-func FromFlags(jsonOut bool, plainOut bool) (Mode, error) {
+// FromFlags resolves the output mode. NDJSON implies JSON so commands that
+// print a single object still emit JSON (one compact line).
+//
+// output is the --output value (table|tsv|csv|markdown|json); it is a
+// superset of --json/--plain and may be combined with them only when they
+// agree (e.g. --json --output json). csv/markdown count as plain output
+// (stable text, no colors).
+func FromFlags(jsonOut bool, plainOut bool, ndjsonOut bool, output string) (Mode, error) {
 	if jsonOut && plainOut {
 		return Mode{}, &ParseError{msg: "invalid output mode (cannot combine --json and --plain)"}
 	}
+	if ndjsonOut && plainOut {
+		return Mode{}, &ParseError{msg: "invalid output mode (cannot combine --ndjson and --plain)"}
+	}
 
-	return Mode{JSON: jsonOut, Plain: plainOut}, nil
+	format, err := ParseFormat(output)
+	if err != nil {
+		return Mode{}, err
+	}
+	if format != "" {
+		switch {
+		case jsonOut && format != FormatJSON:
+			return Mode{}, &ParseError{msg: fmt.Sprintf("invalid output mode (cannot combine --json and --output %s)", format)}
+		case ndjsonOut && format != FormatJSON:
+			return Mode{}, &ParseError{msg: fmt.Sprintf("invalid output mode (cannot combine --ndjson and --output %s)", format)}
+		case plainOut && format != FormatTSV:
+			return Mode{}, &ParseError{msg: fmt.Sprintf("invalid output mode (cannot combine --plain and --output %s)", format)}
+		}
+	}
+
+	mode := Mode{JSON: jsonOut || ndjsonOut, Plain: plainOut, NDJSON: ndjsonOut, Format: format}
+	switch {
+	case mode.JSON || format == FormatJSON:
+		mode.JSON, mode.Format = true, FormatJSON
+	case mode.Plain || format == FormatTSV:
+		mode.Plain, mode.Format = true, FormatTSV
+	case format == FormatCSV || format == FormatMarkdown:
+		mode.Plain = true
+	default:
+		mode.Format = FormatTable
+	}
+
+	return mode, nil
 }
 
 func FromEnv() Mode {
 	return Mode{
-		JSON:  envBool("GOG_JSON"),
-		Plain: envBool("GOG_PLAIN"),
+		JSON:   envBool("GOG_JSON"),
+		Plain:  envBool("GOG_PLAIN"),
+		NDJSON: envBool("GOG_NDJSON"),
 	}
 }
 
@@ -49,13 +89,64 @@ func FromContext(ctx context.Context) Mode {
 	return Mode{}
 }
 
-func IsJSON(ctx context.Context) bool  { return FromContext(ctx).JSON }
-func IsPlain(ctx context.Context) bool { return FromContext(ctx).Plain }
+func IsJSON(ctx context.Context) bool   { return FromContext(ctx).JSON }
+func IsPlain(ctx context.Context) bool  { return FromContext(ctx).Plain }
+func IsNDJSON(ctx context.Context) bool { return FromContext(ctx).NDJSON }
+
+// TableFormat returns how tabular output should be rendered (table when unset).
+func TableFormat(ctx context.Context) Format {
+	if f := FromContext(ctx).Format; f != "" {
+		return f
+	}
+	if IsPlain(ctx) {
+		return FormatTSV
+	}
+	return FormatTable
+}
+
+// WriteJSON writes v as indented JSON (compact in NDJSON mode), applying any
+// --fields/--jq projection stored in ctx first.
+//
+// --jq results are written one per line: strings raw, everything else as
+// compact JSON (like `jq -r`).
+func WriteJSON(ctx context.Context, w io.Writer, v any) error {
+	indent := "  "
+	if IsNDJSON(ctx) {
+		indent = ""
+	}
+
+	proj := ProjectionFromContext(ctx)
+	if proj.IsZero() {
+		return encodeJSON(w, v, indent)
+	}
+
+	values, err := proj.Apply(v)
+	if err != nil {
+		return err
+	}
+	if proj.Query == nil {
+		return encodeJSON(w, values[0], indent)
+	}
+
+	for _, val := range values {
+		if s, ok := val.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return fmt.Errorf("write json: %w", err)
+			}
+			continue
+		}
+		if err := encodeJSON(w, val, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-func WriteJSON(w io.Writer, v any) error {
+func encodeJSON(w io.Writer, v any, indent string) error {
 	enc := json.NewEncoder(w)
 	enc.SetEscapeHTML(false)
-	enc.SetIndent("", "  ")
+	enc.SetIndent("", indent)
 
 	if err := enc.Encode(v); err != nil {
 		return fmt.Errorf("encode json: %w", err)
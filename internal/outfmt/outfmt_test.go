@@ -7,11 +7,14 @@ import (
 )
 
 func TestFromFlags(t *testing.T) {
-	if _, err := FromFlags(true, true); err == nil {
+	if _, err := FromFlags(true, true, false, ""); err == nil {
 		t.Fatalf("expected error when combining --json and --plain")
 	}
+	if _, err := FromFlags(false, true, true, ""); err == nil {
+		t.Fatalf("expected error when combining --ndjson and --plain")
+	}
 
-	got, err := FromFlags(true, false)
+	got, err := FromFlags(true, false, false, "")
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -19,6 +22,50 @@ func TestFromFlags(t *testing.T) {
 	if !got.JSON || got.Plain {
 		t.Fatalf("unexpected mode: %#v", got)
 	}
+
+	got, err = FromFlags(false, false, true, "")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !got.JSON || !got.NDJSON {
+		t.Fatalf("expected ndjson to imply json: %#v", got)
+	}
+}
+
+func TestFromFlags_Output(t *testing.T) {
+	cases := []struct {
+		output string
+		want   Mode
+	}{
+		{"", Mode{Format: FormatTable}},
+		{"table", Mode{Format: FormatTable}},
+		{"tsv", Mode{Plain: true, Format: FormatTSV}},
+		{"CSV", Mode{Plain: true, Format: FormatCSV}},
+		{"md", Mode{Plain: true, Format: FormatMarkdown}},
+		{"json", Mode{JSON: true, Format: FormatJSON}},
+	}
+	for _, tc := range cases {
+		got, err := FromFlags(false, false, false, tc.output)
+		if err != nil {
+			t.Fatalf("%q: unexpected err: %v", tc.output, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%q: got %#v want %#v", tc.output, got, tc.want)
+		}
+	}
+
+	if _, err := FromFlags(false, false, false, "xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+	if _, err := FromFlags(true, false, false, "csv"); err == nil {
+		t.Fatalf("expected error when combining --json and --output csv")
+	}
+	if _, err := FromFlags(false, true, false, "markdown"); err == nil {
+		t.Fatalf("expected error when combining --plain and --output markdown")
+	}
+	if got, err := FromFlags(false, true, false, "tsv"); err != nil || !got.Plain {
+		t.Fatalf("expected --plain --output tsv to agree: %#v %v", got, err)
+	}
 }
 
 func TestContextMode(t *testing.T) {
@@ -36,7 +83,7 @@ func TestContextMode(t *testing.T) {
 
 func TestWriteJSON(t *testing.T) {
 	var buf bytes.Buffer
-	if err := WriteJSON(&buf, map[string]any{"ok": true}); err != nil {
+	if err := WriteJSON(context.Background(), &buf, map[string]any{"ok": true}); err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
@@ -45,12 +92,25 @@ func TestWriteJSON(t *testing.T) {
 	}
 }
 
+func TestWriteJSON_NDJSONCompact(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithMode(context.Background(), Mode{JSON: true, NDJSON: true})
+	if err := WriteJSON(ctx, &buf, map[string]any{"id": "a", "n": 1}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if got := buf.String(); got != "{\"id\":\"a\",\"n\":1}\n" {
+		t.Fatalf("unexpected ndjson line: %q", got)
+	}
+}
+
 func TestFromEnvAndParseError(t *testing.T) {
 	t.Setenv("GOG_JSON", "yes")
 	t.Setenv("GOG_PLAIN", "0")
+	t.Setenv("GOG_NDJSON", "on")
 	mode := FromEnv()
 
-	if !mode.JSON || mode.Plain {
+	if !mode.JSON || mode.Plain || !mode.NDJSON {
 		t.Fatalf("unexpected env mode: %#v", mode)
 	}
 
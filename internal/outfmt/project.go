@@ -0,0 +1,269 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Projection narrows command output before it is written.
+//
+// Fields applies to JSON (keys, dot paths allowed) and to table/TSV output
+// (column headers, matched case-insensitively). Query is a jq
+// expression applied to JSON output after Fields.
+type Projection struct {
+	Fields []string
+	Query  *Query
+}
+
+func (p Projection) IsZero() bool { return len(p.Fields) == 0 && p.Query == nil }
+
+// ParseProjection validates --fields and --jq values.
+func ParseProjection(fields string, query string) (Projection, error) {
+	p := Projection{Fields: ParseFields(fields)}
+	if q := strings.TrimSpace(query); q != "" {
+		compiled, err := ParseQuery(q)
+		if err != nil {
+			return Projection{}, err
+		}
+		p.Query = compiled
+	}
+	return p, nil
+}
+
+// ParseFields splits a comma-separated field list, dropping blanks and duplicates.
+func ParseFields(s string) []string {
+	var out []string
+	seen := make(map[string]struct{})
+	for _, part := range strings.Split(s, ",") {
+		f := strings.TrimSpace(part)
+		if f == "" {
+			continue
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		out = append(out, f)
+	}
+	return out
+}
+
+type projectionCtxKey struct{}
+
+func WithProjection(ctx context.Context, p Projection) context.Context {
+	return context.WithValue(ctx, projectionCtxKey{}, p)
+}
+
+func ProjectionFromContext(ctx context.Context) Projection {
+	if ctx == nil {
+		return Projection{}
+	}
+	if v := ctx.Value(projectionCtxKey{}); v != nil {
+		if p, ok := v.(Projection); ok {
+			return p
+		}
+	}
+	return Projection{}
+}
+
+// Apply runs the projection against v and returns the values to print.
+// Without a query there is always exactly one value.
+func (p Projection) Apply(v any) ([]any, error) {
+	if p.IsZero() {
+		return []any{v}, nil
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Fields) > 0 {
+		generic = selectFields(generic, p.Fields)
+	}
+	if p.Query == nil {
+		return []any{generic}, nil
+	}
+	return p.Query.Eval(generic)
+}
+
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode json: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return out, nil
+}
+
+// selectFields keeps only the requested fields on the result objects in v.
+//
+// Commands wrap results in an envelope ({"files": [...], "nextPageToken": ...}
+// or {"file": {...}}), so objects that don't carry any requested field are
+// treated as envelopes: their object/array members are projected and their
+// scalar members (paging tokens, counts) are kept as-is.
+func selectFields(v any, fields []string) any {
+	switch t := v.(type) {
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, it := range t {
+			out = append(out, selectFields(it, fields))
+		}
+		return out
+	case map[string]any:
+		if hasAnyField(t, fields) {
+			return pickFields(t, fields)
+		}
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			switch val.(type) {
+			case map[string]any, []any:
+				out[k] = selectFields(val, fields)
+			default:
+				out[k] = val
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func hasAnyField(m map[string]any, fields []string) bool {
+	for _, f := range fields {
+		head, _, _ := strings.Cut(f, ".")
+		if _, ok := m[head]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func pickFields(m map[string]any, fields []string) map[string]any {
+	out := make(map[string]any, len(fields))
+	nested := make(map[string][]string)
+	var order []string
+	for _, f := range fields {
+		head, rest, hasRest := strings.Cut(f, ".")
+		val, ok := m[head]
+		if !ok {
+			continue
+		}
+		if !hasRest || rest == "" {
+			out[head] = val
+			continue
+		}
+		if _, seen := nested[head]; !seen {
+			order = append(order, head)
+		}
+		nested[head] = append(nested[head], rest)
+	}
+	for _, head := range order {
+		if _, whole := out[head]; whole {
+			continue
+		}
+		out[head] = pickPath(m[head], nested[head])
+	}
+	return out
+}
+
+func pickPath(v any, fields []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return pickFields(t, fields)
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, it := range t {
+			out = append(out, pickPath(it, fields))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// ColumnWriter buffers tab-separated rows and, on Flush, writes only the
+// columns whose header matches one of the requested fields (in field order).
+// The first line written is treated as the header row.
+type ColumnWriter struct {
+	w      io.Writer
+	fields []string
+	buf    bytes.Buffer
+}
+
+func NewColumnWriter(w io.Writer, fields []string) *ColumnWriter {
+	return &ColumnWriter{w: w, fields: fields}
+}
+
+func (c *ColumnWriter) Write(p []byte) (int, error) {
+	return c.buf.Write(p)
+}
+
+func (c *ColumnWriter) Flush() error {
+	data := c.buf.String()
+	c.buf.Reset()
+	if data == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(data, "\n")
+	var indices []int
+	headerSeen := false
+	var out strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		body := strings.TrimSuffix(line, "\n")
+		if !headerSeen {
+			headerSeen = true
+			indices = matchColumns(strings.Split(body, "\t"), c.fields)
+		}
+		cols := strings.Split(body, "\t")
+		picked := make([]string, 0, len(indices))
+		for _, i := range indices {
+			if i < len(cols) {
+				picked = append(picked, cols[i])
+			} else {
+				picked = append(picked, "")
+			}
+		}
+		if len(picked) == 0 {
+			continue
+		}
+		out.WriteString(strings.Join(picked, "\t"))
+		out.WriteString("\n")
+	}
+	_, err := io.WriteString(c.w, out.String())
+	return err
+}
+
+func matchColumns(header []string, fields []string) []int {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeColumn(h)
+		if _, ok := idx[key]; !ok {
+			idx[key] = i
+		}
+	}
+	out := make([]int, 0, len(fields))
+	for _, f := range fields {
+		if i, ok := idx[normalizeColumn(f)]; ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func normalizeColumn(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer("_", "", "-", "", " ", "", ".", "").Replace(s)
+}
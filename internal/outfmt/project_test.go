@@ -0,0 +1,126 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	got := ParseFields(" id, name,,id ,mimeType ")
+	want := []string{"id", "name", "mimeType"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+	if ParseFields("") != nil {
+		t.Fatalf("expected nil for empty fields")
+	}
+}
+
+func TestParseProjection_InvalidQuery(t *testing.T) {
+	if _, err := ParseProjection("id", ".["); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestWriteJSON_Fields(t *testing.T) {
+	p, err := ParseProjection("id,name,owners.email", "")
+	if err != nil {
+		t.Fatalf("ParseProjection: %v", err)
+	}
+	ctx := WithProjection(context.Background(), p)
+
+	var buf bytes.Buffer
+	err = WriteJSON(ctx, &buf, map[string]any{
+		"files": []map[string]any{
+			{"id": "a", "name": "A", "size": "1", "owners": []map[string]any{{"email": "x@y", "name": "X"}}},
+			{"id": "b", "name": "B", "size": "2"},
+		},
+		"nextPageToken": "tok",
+	})
+	if err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	want := map[string]any{
+		"files": []any{
+			map[string]any{"id": "a", "name": "A", "owners": []any{map[string]any{"email": "x@y"}}},
+			map[string]any{"id": "b", "name": "B"},
+		},
+		"nextPageToken": "tok",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+}
+
+func TestWriteJSON_FieldsSingleObject(t *testing.T) {
+	ctx := WithProjection(context.Background(), Projection{Fields: []string{"id"}})
+
+	var buf bytes.Buffer
+	if err := WriteJSON(ctx, &buf, map[string]any{"file": map[string]any{"id": "a", "name": "A"}}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got := buf.String(); got != "{\n  \"file\": {\n    \"id\": \"a\"\n  }\n}\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestWriteJSON_Query(t *testing.T) {
+	p, err := ParseProjection("", "(.files[] | {id}), .count")
+	if err != nil {
+		t.Fatalf("ParseProjection: %v", err)
+	}
+	ctx := WithProjection(context.Background(), p)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(ctx, &buf, map[string]any{
+		"files": []map[string]any{{"id": "a"}, {"id": "b"}},
+		"count": 2,
+	}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got := buf.String(); got != "{\"id\":\"a\"}\n{\"id\":\"b\"}\n2\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+
+	buf.Reset()
+	p, _ = ParseProjection("", ".name")
+	if err := WriteJSON(WithProjection(context.Background(), p), &buf, map[string]any{"name": "raw"}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got := buf.String(); got != "raw\n" {
+		t.Fatalf("expected raw string output, got %q", got)
+	}
+}
+
+func TestColumnWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := NewColumnWriter(&buf, []string{"name", "ID", "missing", "mime_type"})
+	_, _ = cw.Write([]byte("ID\tNAME\tMIME_TYPE\tSIZE\n"))
+	_, _ = cw.Write([]byte("a\tA\ttext/plain\t1\n"))
+	_, _ = cw.Write([]byte("b\tB\n"))
+	if buf.Len() != 0 {
+		t.Fatalf("expected buffering until Flush")
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := "NAME\tID\tMIME_TYPE\nA\ta\ttext/plain\nB\tb\t\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestProjectionFromContext_WrongType(t *testing.T) {
+	ctx := context.WithValue(context.Background(), projectionCtxKey{}, "nope")
+	if !ProjectionFromContext(ctx).IsZero() {
+		t.Fatalf("expected zero projection")
+	}
+}
@@ -0,0 +1,128 @@
+package outfmt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is the rendering used for tabular (non-JSON) output.
+type Format string
+
+const (
+	FormatTable    Format = "table"    // aligned columns (default)
+	FormatTSV      Format = "tsv"      // tab-separated (--plain)
+	FormatCSV      Format = "csv"      // RFC 4180 quoting
+	FormatMarkdown Format = "markdown" // GitHub-flavored table
+	FormatJSON     Format = "json"     // --json
+)
+
+// ParseFormat parses an --output value. "" means "not set".
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case "table", "text":
+		return FormatTable, nil
+	case "tsv", "plain":
+		return FormatTSV, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", &ParseError{msg: fmt.Sprintf("invalid --output %q (expected table|tsv|csv|markdown|json)", s)}
+	}
+}
+
+// RowWriter buffers tab-separated rows and, on Flush, renders them as CSV or
+// a Markdown table. The first line written is treated as the header row.
+// Any other format is passed through unchanged (TSV).
+type RowWriter struct {
+	w      io.Writer
+	format Format
+	buf    bytes.Buffer
+}
+
+func NewRowWriter(w io.Writer, format Format) *RowWriter {
+	return &RowWriter{w: w, format: format}
+}
+
+func (r *RowWriter) Write(p []byte) (int, error) {
+	return r.buf.Write(p)
+}
+
+func (r *RowWriter) Flush() error {
+	data := r.buf.String()
+	r.buf.Reset()
+	if data == "" {
+		return nil
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+
+	switch r.format {
+	case FormatCSV:
+		cw := csv.NewWriter(r.w)
+		if err := cw.WriteAll(rows); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		_, err := io.WriteString(r.w, markdownTable(rows))
+		return err
+	default:
+		_, err := io.WriteString(r.w, data)
+		return err
+	}
+}
+
+func markdownTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = markdownEscape(cells[i])
+			}
+			b.WriteString(" ")
+			b.WriteString(cell)
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	sep := make([]string, width)
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeRow(sep)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.TrimSpace(s)
+}
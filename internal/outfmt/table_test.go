@@ -0,0 +1,64 @@
+package outfmt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRowWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRowWriter(&buf, FormatCSV)
+	_, _ = w.Write([]byte("ID\tSUMMARY\n"))
+	_, _ = w.Write([]byte("e1\tLunch, with \"Bob\"\n"))
+	_, _ = w.Write([]byte("e2\tplain\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := "ID,SUMMARY\ne1,\"Lunch, with \"\"Bob\"\"\"\ne2,plain\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestRowWriter_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRowWriter(&buf, FormatMarkdown)
+	_, _ = w.Write([]byte("ID\tQUERY\n"))
+	_, _ = w.Write([]byte("f1\tfrom:a|b\n"))
+	_, _ = w.Write([]byte("f2\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := "| ID | QUERY |\n| --- | --- |\n| f1 | from:a\\|b |\n| f2 |  |\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected markdown:\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestRowWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRowWriter(&buf, FormatMarkdown)
+	if err := w.Flush(); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected no output, got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{
+		"":         "",
+		"text":     FormatTable,
+		"plain":    FormatTSV,
+		"markdown": FormatMarkdown,
+		" Json ":   FormatJSON,
+	} {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Fatalf("expected error")
+	}
+}